@@ -0,0 +1,70 @@
+// Package alias contains the entry point for the alias command, which sets
+// the Bluetooth alias other tools show for a device
+package alias
+
+import (
+	"btui/internal/bluetooth"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// New creates a new cobra command for setting device aliases
+func New() *cobra.Command {
+	c := &cobra.Command{}
+	c.Use = "alias <device> [name]"
+	c.Short = "Set the Bluetooth alias of a device"
+	c.Long = "Set the alias BlueZ and other desktop tools show for a device, given by MAC address or name. " +
+		"--reset goes back to the name the device advertises."
+	c.Args = cobra.RangeArgs(1, 2)
+	c.Run = run
+	c.Flags().Bool("reset", false, "revert to the name the device advertises")
+	return c
+}
+
+// run executes the alias command
+func run(cmd *cobra.Command, args []string) {
+	reset, _ := cmd.Flags().GetBool("reset")
+	alias, err := aliasArg(args, reset)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	msg := bluetooth.FetchDevicesCmd()().(bluetooth.DevicesMsg)
+	if msg.Err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", msg.Err)
+		os.Exit(1)
+	}
+	devices := make([]bluetooth.BluetoothDevice, len(msg.Devices))
+	for i, line := range msg.Devices {
+		devices[i] = bluetooth.ParseDeviceLine(line, nil)
+	}
+
+	device, err := bluetooth.MatchDevice(devices, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	result := bluetooth.SetAliasCmd(device, alias)().(bluetooth.AliasMsg)
+	if result.Err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", result.Err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s is now called %q\n", device.MacAddress, result.Info.Alias)
+}
+
+// aliasArg returns the alias to set: the name argument, or "" with --reset
+func aliasArg(args []string, reset bool) (string, error) {
+	switch {
+	case reset && len(args) == 2:
+		return "", fmt.Errorf("give either a name or --reset, not both")
+	case reset:
+		return "", nil
+	case len(args) < 2 || args[1] == "":
+		return "", fmt.Errorf("give the new name, or --reset to use the device's own name")
+	}
+	return args[1], nil
+}
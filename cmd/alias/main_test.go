@@ -0,0 +1,28 @@
+package alias
+
+import "testing"
+
+func TestAliasArg(t *testing.T) {
+	tests := []struct {
+		args     []string
+		reset    bool
+		expected string
+		wantErr  bool
+	}{
+		{[]string{"Keychron K4", "Desk keyboard"}, false, "Desk keyboard", false},
+		{[]string{"Keychron K4"}, true, "", false},
+		{[]string{"Keychron K4"}, false, "", true},
+		{[]string{"Keychron K4", ""}, false, "", true},
+		{[]string{"Keychron K4", "Desk keyboard"}, true, "", true},
+	}
+
+	for _, tt := range tests {
+		alias, err := aliasArg(tt.args, tt.reset)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("aliasArg(%q, %v) error = %v, wantErr %v", tt.args, tt.reset, err, tt.wantErr)
+		}
+		if alias != tt.expected {
+			t.Errorf("aliasArg(%q, %v) = %q, expected %q", tt.args, tt.reset, alias, tt.expected)
+		}
+	}
+}
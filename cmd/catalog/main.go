@@ -0,0 +1,88 @@
+// Package catalog contains the commands that export and import device
+// nicknames, tags and notes so a team can share them
+package catalog
+
+import (
+	"btui/internal/state"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// New creates a new cobra command for sharing device metadata
+func New() *cobra.Command {
+	c := &cobra.Command{}
+	c.Use = "catalog"
+	c.Short = "Export or import device nicknames, tags and notes"
+	c.Long = "Share the nicknames, tags and notes given to devices in the scan view as a JSON catalogue"
+
+	export := &cobra.Command{}
+	export.Use = "export [file]"
+	export.Short = "Write the device catalogue as JSON (to stdout without a file)"
+	export.Args = cobra.MaximumNArgs(1)
+	export.Run = run(exportCatalogue)
+
+	imp := &cobra.Command{}
+	imp.Use = "import <file>"
+	imp.Short = `Read a device catalogue as JSON ("-" for stdin)`
+	imp.Args = cobra.ExactArgs(1)
+	imp.Run = run(importCatalogue)
+	imp.Flags().Bool("replace", false, "forget devices that are not in the catalogue")
+
+	c.AddCommand(export, imp)
+	return c
+}
+
+// run adapts a subcommand so its errors are reported like the other commands
+func run(f func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if err := f(cmd, args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// exportCatalogue writes the catalogue to a file or stdout
+func exportCatalogue(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 || args[0] == "-" {
+		return state.Get().Export(cmd.OutOrStdout())
+	}
+
+	f, err := os.Create(args[0])
+	if err != nil {
+		return err
+	}
+	if err := state.Get().Export(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// importCatalogue merges a catalogue into the state file
+func importCatalogue(cmd *cobra.Command, args []string) error {
+	var in io.Reader = cmd.InOrStdin()
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	replace, _ := cmd.Flags().GetBool("replace")
+	store := state.Get()
+	n, err := store.Import(in, replace)
+	if err != nil {
+		return err
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Imported %d devices into %s\n", n, store.Path())
+	return nil
+}
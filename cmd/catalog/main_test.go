@@ -0,0 +1,81 @@
+package catalog
+
+import (
+	"btui/internal/state"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useStore makes a store saving to a temporary file the active store
+func useStore(t *testing.T) *state.Store {
+	t.Helper()
+	store := state.New(filepath.Join(t.TempDir(), "state.json"))
+	state.Set(store)
+	t.Cleanup(func() { state.Set(state.New("")) })
+	return store
+}
+
+// execute runs the catalog command with arguments and stdin
+func execute(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+	c := New()
+	var out bytes.Buffer
+	c.SetOut(&out)
+	c.SetIn(strings.NewReader(stdin))
+	c.SetArgs(args)
+	_, err := c.ExecuteC()
+	return out.String(), err
+}
+
+func TestExport(t *testing.T) {
+	store := useStore(t)
+	store.SetMeta("4C:87:5D:28:86:DD", state.Meta{Nickname: "Headphones"})
+
+	out, err := execute(t, "", "export")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(out, `"4C:87:5D:28:86:DD"`) || !strings.Contains(out, `"nickname": "Headphones"`) {
+		t.Errorf("Expected the device in the catalogue, got:\n%s", out)
+	}
+
+	path := filepath.Join(t.TempDir(), "devices.json")
+	if _, err := execute(t, "", "export", path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != out {
+		t.Errorf("Expected the file to match stdout output, got %q (%v)", data, err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	store := useStore(t)
+	catalogue := `{"version": 1, "devices": {"F0:99:B6:12:34:56": {"nickname": "Office TV", "tags": ["meeting-room-3"]}}}`
+
+	out, err := execute(t, catalogue, "import", "-")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "Imported 1 devices") {
+		t.Errorf("Unexpected output %q", out)
+	}
+	if store.Meta("F0:99:B6:12:34:56").Nickname != "Office TV" {
+		t.Error("Expected the nickname to be imported")
+	}
+
+	// The import is saved to the state file
+	loaded, err := state.Load(store.Path())
+	if err != nil || loaded.Meta("F0:99:B6:12:34:56").Nickname != "Office TV" {
+		t.Errorf("Expected the import to be saved, got %v", err)
+	}
+}
+
+func TestImportInvalid(t *testing.T) {
+	useStore(t)
+	if err := importCatalogue(New(), []string{filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -2,6 +2,9 @@ package connect
 
 import (
 	"btui/internal/bluetooth"
+	"btui/internal/ui"
+	"context"
+	"time"
 )
 
 // ViewState represents the current view state
@@ -20,8 +23,22 @@ type Model struct {
 	Result       *bluetooth.ConnectResult
 	Width        int
 	Height       int
+	// Cancel stops the running connect; Cancelling is set once it has been called
+	Cancel     context.CancelFunc
+	Cancelling bool
+	// Started is when the connect began. Details describe it once it has
+	// failed, and Notice reports on copying them.
+	Started time.Time
+	Details *ui.ErrorDetails
+	Notice  string
+	// Attempt counts the tries of the running connect, out of those the retry
+	// policy allows
+	Attempt int
 }
 
+// retryMsg is sent when the wait before trying a failed connect again is over
+type retryMsg struct{}
+
 // NewModel creates a new model for the connect command
 func NewModel() Model {
 	return Model{
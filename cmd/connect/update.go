@@ -2,8 +2,12 @@ package connect
 
 import (
 	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/ui"
+	"context"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -12,8 +16,29 @@ func (m Model) Init() tea.Cmd {
 	return m.DevicePicker.Init()
 }
 
-// Update implements tea.Model
+// Update implements tea.Model. In accessible mode every new status line is
+// also printed as a plain line so it is announced by screen readers.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	previous, notice := m.Status(), m.Notice
+	updated, cmd := m.update(msg)
+	if !ui.Accessible() {
+		return updated, cmd
+	}
+	next, ok := updated.(Model)
+	if !ok {
+		return updated, cmd
+	}
+	if status := next.Status(); status != "" && status != previous {
+		cmd = tea.Batch(cmd, tea.Println(status))
+	}
+	if next.Notice != "" && next.Notice != notice {
+		cmd = tea.Batch(cmd, tea.Println(next.Notice))
+	}
+	return updated, cmd
+}
+
+// update handles a message in the current view state
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch m.State {
 	case DeviceSelection:
 		return m.updateDeviceSelection(msg)
@@ -47,7 +72,9 @@ func (m Model) updateDeviceSelection(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Check if a device was selected
 	if m.DevicePicker.Choice != nil {
 		m.State = Connecting
-		return m, bluetooth.ConnectCmd(*m.DevicePicker.Choice)
+		m.Started = time.Now()
+		m.Attempt = 1
+		return m.connect()
 	}
 
 	// Check if user quit device selection
@@ -62,30 +89,86 @@ func (m Model) updateDeviceSelection(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m Model) updateConnecting(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if msg.String() == "ctrl+c" {
-			return m, tea.Quit
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			if m.Cancelling {
+				// Pressed again: stop waiting for the cancellation to finish
+				return m, tea.Quit
+			}
+			// Stop bluetoothctl and wait for the cancelled result
+			m.Cancelling = true
+			if m.Cancel != nil {
+				m.Cancel()
+			}
+			return m, nil
 		}
+	case retryMsg:
+		if m.Cancelling {
+			// Called off while waiting, so there is nothing left to stop
+			return m.showResult(bluetooth.ConnectResult{Device: *m.DevicePicker.Choice, Cancelled: true})
+		}
+		return m.connect()
 	case bluetooth.ConnectMsg:
+		if m.Cancel != nil {
+			m.Cancel()
+		}
 		result := bluetooth.ConnectResult(msg)
-		m.Result = &result
-		m.State = ShowResult
-		// Auto-quit after 2 seconds to show result then return to main menu
-		return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg {
-			return tea.QuitMsg{}
-		})
+		policy := config.Get().Retry
+		if !result.Success && !result.Cancelled && !m.Cancelling && bluetooth.ShouldRetry(policy, max(m.Attempt, 1), result.Output) {
+			m.Attempt = max(m.Attempt, 1) + 1
+			return m, tea.Tick(bluetooth.Backoff(policy, m.Attempt), func(time.Time) tea.Msg {
+				return retryMsg{}
+			})
+		}
+		return m.showResult(result)
 	}
 
 	return m, nil
 }
 
-// updateShowResult handles updates when showing connection result
+// connect starts an attempt to connect to the chosen device
+func (m Model) connect() (tea.Model, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.Cancel = cancel
+	return m, bluetooth.ConnectCmdContext(ctx, *m.DevicePicker.Choice)
+}
+
+// showResult shows the outcome of the connect
+func (m Model) showResult(result bluetooth.ConnectResult) (tea.Model, tea.Cmd) {
+	m.Result = &result
+	m.State = ShowResult
+	if !result.Success && !result.Cancelled {
+		// A failure stays up until dismissed, so its details can be read
+		var elapsed time.Duration
+		if !m.Started.IsZero() {
+			elapsed = time.Since(m.Started)
+		}
+		details := bluetooth.FailureDetails(bluetooth.OpConnect, result.Device, elapsed, result.Output, result.Err)
+		m.Details = &details
+		return m, nil
+	}
+	// Auto-quit after 2 seconds to show result then return to main menu
+	return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg {
+		return tea.QuitMsg{}
+	})
+}
+
+// updateShowResult handles updates when showing the connection result
 func (m Model) updateShowResult(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.Details != nil && key.Matches(msg, ui.ErrorDetailsKeys.Copy) {
+			return m, ui.CopyCmd(m.Details.Text())
+		}
 		switch msg.String() {
 		case "ctrl+c", "q", "enter", "esc":
 			return m, tea.Quit
 		}
+	case ui.ClipboardMsg:
+		m.Notice = "Copied the error details to the clipboard"
+		if msg.Err != nil {
+			m.Notice = "Could not copy the error details: " + msg.Err.Error()
+		}
 	case tea.QuitMsg:
 		return m, tea.Quit
 	}
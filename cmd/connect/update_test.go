@@ -2,6 +2,9 @@ package connect
 
 import (
 	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/ui"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -94,3 +97,141 @@ func TestUpdateShowResult(t *testing.T) {
 		t.Error("Expected quit command when QuitMsg is received")
 	}
 }
+
+func TestCancelConnecting(t *testing.T) {
+	model := NewModel()
+	model.State = Connecting
+	cancelled := 0
+	model.Cancel = func() { cancelled++ }
+
+	// Esc stops bluetoothctl rather than quitting
+	updatedModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m := updatedModel.(Model)
+	if cancelled != 1 || !m.Cancelling || cmd != nil {
+		t.Fatalf("Expected the operation cancelled without quitting, got %d cancels", cancelled)
+	}
+
+	device := bluetooth.BluetoothDevice{MacAddress: "AA:BB:CC:DD:EE:FF", Name: "Test Device"}
+	updatedModel, _ = m.Update(bluetooth.ConnectMsg{Device: device, Cancelled: true})
+	m = updatedModel.(Model)
+	if m.State != ShowResult || !strings.Contains(m.View(), "Cancelled connecting to Test Device") {
+		t.Errorf("Expected the cancelled result, got %q", m.View())
+	}
+
+	// Pressing again while cancelling quits at once
+	m.State = Connecting
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC}); cmd == nil {
+		t.Error("Expected a second ctrl+c to quit")
+	}
+}
+
+func TestRetryConnecting(t *testing.T) {
+	device := bluetooth.BluetoothDevice{MacAddress: "4C:87:5D:28:86:DD", Name: "Headphones"}
+	model := NewModel()
+	model.State = Connecting
+	model.Attempt = 1
+	model.DevicePicker.Choice = &device
+
+	pageTimeout := bluetooth.ConnectMsg{Device: device, Output: "Failed to connect: org.bluez.Error.Failed br-connection-page-timeout"}
+	updatedModel, cmd := model.Update(pageTimeout)
+	m := updatedModel.(Model)
+	if m.State != Connecting || m.Attempt != 2 || cmd == nil {
+		t.Fatalf("Expected a page timeout retried, got state %v attempt %d", m.State, m.Attempt)
+	}
+	if !strings.Contains(m.View(), "attempt 2/3") {
+		t.Errorf("Expected the attempt shown, got %q", m.View())
+	}
+
+	// Cancelling during the wait gives up without another attempt
+	m.Cancelling = true
+	updatedModel, _ = m.Update(retryMsg{})
+	if m := updatedModel.(Model); m.State != ShowResult || !m.Result.Cancelled {
+		t.Errorf("Expected the retry called off, got state %v", m.State)
+	}
+
+	// Failures retrying won't fix are shown at once, as is the last attempt
+	notAvailable := bluetooth.ConnectMsg{Device: device, Output: "Device 4C:87:5D:28:86:DD not available"}
+	for attempt, msg := range map[int]bluetooth.ConnectMsg{1: notAvailable, 3: pageTimeout} {
+		model.Attempt = attempt
+		updatedModel, _ := model.Update(msg)
+		if m := updatedModel.(Model); m.State != ShowResult || m.Result.Success {
+			t.Errorf("Expected the failure on attempt %d shown, got state %v", attempt, m.State)
+		}
+	}
+}
+
+func TestFailureDetails(t *testing.T) {
+	model := NewModel()
+	model.State = Connecting
+	// On the last attempt the failure is shown rather than retried
+	model.Attempt = config.Get().Retry.Attempts
+	output := "Failed to connect: org.bluez.Error.Failed br-connection-page-timeout"
+	updatedModel, cmd := model.Update(bluetooth.ConnectMsg{
+		Device: bluetooth.BluetoothDevice{MacAddress: "4C:87:5D:28:86:DD", Name: "Headphones"},
+		Output: output,
+		Err:    bluetooth.NewCommandError(output, nil),
+	})
+
+	m := updatedModel.(Model)
+	if cmd != nil {
+		t.Error("Expected a failure to stay up until dismissed")
+	}
+	view := m.View()
+	for _, expected := range []string{"connect failed", "Headphones (4C:87:5D:28:86:DD)", "Exit status: 0", "Cause:       page timeout", "Fix:         the device did not answer", "br-connection-page-timeout"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected the details to show %q, got %q", expected, view)
+		}
+	}
+
+	// The details can be copied, and any other key closes them
+	updatedModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if cmd == nil {
+		t.Fatal("Expected c to copy the details")
+	}
+	updatedModel, _ = updatedModel.(Model).Update(ui.ClipboardMsg{})
+	if m := updatedModel.(Model); !strings.Contains(m.View(), "Copied the error details") {
+		t.Error("Expected the copy confirmed")
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc}); cmd == nil {
+		t.Error("Expected esc to close")
+	}
+}
+
+func TestAccessibleAnnouncesStatus(t *testing.T) {
+	t.Cleanup(func() {
+		ui.SetAccessible(false)
+		ui.ApplyTheme(ui.Presets[ui.ThemeDark])
+	})
+	ui.SetAccessible(true)
+
+	device := bluetooth.BluetoothDevice{MacAddress: "AA:BB:CC:DD:EE:FF", Name: "Test Device"}
+	model := NewModel()
+	model.State = Connecting
+	model.DevicePicker.Choice = &device
+	if status := model.Status(); status != "Connecting to Test Device (AA:BB:CC:DD:EE:FF)" {
+		t.Errorf("Unexpected status %q", status)
+	}
+
+	// The result is printed alongside the tick that quits
+	updatedModel, cmd := model.Update(bluetooth.ConnectMsg{Device: device, Success: true})
+	m := updatedModel.(Model)
+	if status := m.Status(); status != "Successfully connected to Test Device" {
+		t.Errorf("Unexpected status %q", status)
+	}
+	if cmd == nil {
+		t.Fatal("Expected a command")
+	}
+	if batch, ok := cmd().(tea.BatchMsg); !ok || len(batch) != 2 {
+		t.Fatalf("Expected a batch with the quit tick and the announcement, got %T", cmd())
+	}
+
+	// A failure stays up, but is still announced
+	updatedModel, cmd = model.Update(bluetooth.ConnectMsg{Device: device, Output: "Failed", Err: bluetooth.NewCommandError("org.bluez.Error.NotReady", nil)})
+	m = updatedModel.(Model)
+	if status := m.Status(); !strings.HasPrefix(status, "Could not connect to Test Device") {
+		t.Errorf("Unexpected status %q", status)
+	}
+	if cmd == nil {
+		t.Error("Expected the failure announced")
+	}
+}
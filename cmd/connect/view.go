@@ -3,6 +3,8 @@ package connect
 import (
 	"fmt"
 
+	"btui/internal/bluetooth"
+	"btui/internal/config"
 	"btui/internal/ui"
 )
 
@@ -23,7 +25,7 @@ func (m Model) View() string {
 // viewConnecting renders the connecting state
 func (m Model) viewConnecting() string {
 	if m.DevicePicker.Choice == nil {
-		return "Connecting...\n\nPress Ctrl+C to cancel."
+		return "Connecting...\n\nPress Esc or Ctrl+C to cancel."
 	}
 
 	deviceName := m.DevicePicker.Choice.Name
@@ -31,9 +33,19 @@ func (m Model) viewConnecting() string {
 		deviceName = "Unknown Device"
 	}
 
-	return fmt.Sprintf("Connecting to %s (%s)...\n\nPress Ctrl+C to cancel.",
+	if m.Cancelling {
+		return fmt.Sprintf("Cancelling connecting to %s...\n\nPress Esc or Ctrl+C again to quit now.", deviceName)
+	}
+
+	attempt := ""
+	if m.Attempt > 1 {
+		attempt = fmt.Sprintf(" attempt %d/%d", m.Attempt, config.Get().Retry.Attempts)
+	}
+
+	return fmt.Sprintf("Connecting to %s (%s)...%s\n\nPress Esc or Ctrl+C to cancel.",
 		deviceName,
-		m.DevicePicker.Choice.MacAddress)
+		m.DevicePicker.Choice.MacAddress,
+		attempt)
 }
 
 // viewResult renders the connection result
@@ -47,14 +59,27 @@ func (m Model) viewResult() string {
 		deviceName = "Unknown Device"
 	}
 
-	style := ui.SuccessStyle()
-	message := "✓ Successfully connected"
+	if m.Result.Cancelled {
+		return fmt.Sprintf("Cancelled connecting to %s\nMAC: %s\n\nPress any key to exit.",
+			deviceName,
+			m.Result.Device.MacAddress)
+	}
 
 	if !m.Result.Success {
-		style = ui.ErrorStyle()
-		message = "✗ Failed to connect"
+		if m.Details == nil {
+			details := bluetooth.FailureDetails(bluetooth.OpConnect, m.Result.Device, 0, m.Result.Output, m.Result.Err)
+			m.Details = &details
+		}
+		view := m.Details.View(m.Width)
+		if m.Notice != "" {
+			view += "\n" + m.Notice
+		}
+		return view
 	}
 
+	style := ui.SuccessStyle()
+	message := ui.SuccessMarker() + " Successfully connected"
+
 	header := style.Render(fmt.Sprintf("%s to %s", message, deviceName))
 
 	output := ""
@@ -62,12 +87,46 @@ func (m Model) viewResult() string {
 		output = fmt.Sprintf("\nOutput: %s", m.Result.Output)
 	}
 
-	if m.Result.Err != nil {
-		output += fmt.Sprintf("\nError: %s", m.Result.Err.Error())
-	}
-
 	return fmt.Sprintf("%s\nMAC: %s%s\n\nPress any key to exit.",
 		header,
 		m.Result.Device.MacAddress,
 		output)
 }
+
+// Status describes where the connect stands in one plain line, empty while a
+// device is being chosen
+func (m Model) Status() string {
+	switch {
+	case m.State == Connecting && m.DevicePicker.Choice != nil:
+		name := deviceName(*m.DevicePicker.Choice)
+		if m.Cancelling {
+			return "Cancelling connecting to " + name
+		}
+		status := fmt.Sprintf("Connecting to %s (%s)", name, m.DevicePicker.Choice.MacAddress)
+		if m.Attempt > 1 {
+			return fmt.Sprintf("%s attempt %d/%d", status, m.Attempt, config.Get().Retry.Attempts)
+		}
+		return status
+	case m.State == ShowResult && m.Result != nil:
+		name := deviceName(m.Result.Device)
+		switch {
+		case m.Result.Cancelled:
+			return "Cancelled connecting to " + name
+		case m.Result.Success:
+			return "Successfully connected to " + name
+		case m.Details != nil && m.Details.Cause != "":
+			return fmt.Sprintf("Could not connect to %s: %s", name, m.Details.Cause)
+		default:
+			return "Could not connect to " + name
+		}
+	}
+	return ""
+}
+
+// deviceName returns the name a device is shown by
+func deviceName(device bluetooth.BluetoothDevice) string {
+	if device.Name == "" {
+		return "Unknown Device"
+	}
+	return device.Name
+}
@@ -2,6 +2,9 @@ package disconnect
 
 import (
 	"btui/internal/bluetooth"
+	"btui/internal/ui"
+	"context"
+	"time"
 )
 
 // ViewState represents the current view state
@@ -20,6 +23,14 @@ type Model struct {
 	Result       *bluetooth.DisconnectResult
 	Width        int
 	Height       int
+	// Cancel stops the running disconnect; Cancelling is set once it has been called
+	Cancel     context.CancelFunc
+	Cancelling bool
+	// Started is when the disconnect began. Details describe it once it has
+	// failed, and Notice reports on copying them.
+	Started time.Time
+	Details *ui.ErrorDetails
+	Notice  string
 }
 
 // NewModel creates a new model for the disconnect command
@@ -2,8 +2,11 @@ package disconnect
 
 import (
 	"btui/internal/bluetooth"
+	"btui/internal/ui"
+	"context"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -12,8 +15,29 @@ func (m Model) Init() tea.Cmd {
 	return m.DevicePicker.Init()
 }
 
-// Update implements tea.Model
+// Update implements tea.Model. In accessible mode every new status line is
+// also printed as a plain line so it is announced by screen readers.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	previous, notice := m.Status(), m.Notice
+	updated, cmd := m.update(msg)
+	if !ui.Accessible() {
+		return updated, cmd
+	}
+	next, ok := updated.(Model)
+	if !ok {
+		return updated, cmd
+	}
+	if status := next.Status(); status != "" && status != previous {
+		cmd = tea.Batch(cmd, tea.Println(status))
+	}
+	if next.Notice != "" && next.Notice != notice {
+		cmd = tea.Batch(cmd, tea.Println(next.Notice))
+	}
+	return updated, cmd
+}
+
+// update handles a message in the current view state
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch m.State {
 	case DeviceSelection:
 		return m.updateDeviceSelection(msg)
@@ -47,7 +71,10 @@ func (m Model) updateDeviceSelection(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Check if a device was selected
 	if m.DevicePicker.Choice != nil {
 		m.State = Disconnecting
-		return m, bluetooth.DisconnectCmd(*m.DevicePicker.Choice)
+		m.Started = time.Now()
+		ctx, cancel := context.WithCancel(context.Background())
+		m.Cancel = cancel
+		return m, bluetooth.DisconnectCmdContext(ctx, *m.DevicePicker.Choice)
 	}
 
 	// Check if user quit device selection
@@ -62,13 +89,36 @@ func (m Model) updateDeviceSelection(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m Model) updateDisconnecting(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if msg.String() == "ctrl+c" {
-			return m, tea.Quit
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			if m.Cancelling {
+				// Pressed again: stop waiting for the cancellation to finish
+				return m, tea.Quit
+			}
+			// Stop bluetoothctl and wait for the cancelled result
+			m.Cancelling = true
+			if m.Cancel != nil {
+				m.Cancel()
+			}
+			return m, nil
 		}
 	case bluetooth.DisconnectMsg:
+		if m.Cancel != nil {
+			m.Cancel()
+		}
 		result := bluetooth.DisconnectResult(msg)
 		m.Result = &result
 		m.State = ShowResult
+		if !result.Success && !result.Cancelled {
+			// A failure stays up until dismissed, so its details can be read
+			var elapsed time.Duration
+			if !m.Started.IsZero() {
+				elapsed = time.Since(m.Started)
+			}
+			details := bluetooth.FailureDetails(bluetooth.OpDisconnect, result.Device, elapsed, result.Output, result.Err)
+			m.Details = &details
+			return m, nil
+		}
 		// Auto-quit after 2 seconds to show result then return to main menu
 		return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg {
 			return tea.QuitMsg{}
@@ -78,14 +128,22 @@ func (m Model) updateDisconnecting(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// updateShowResult handles updates when showing disconnection result
+// updateShowResult handles updates when showing the disconnection result
 func (m Model) updateShowResult(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.Details != nil && key.Matches(msg, ui.ErrorDetailsKeys.Copy) {
+			return m, ui.CopyCmd(m.Details.Text())
+		}
 		switch msg.String() {
 		case "ctrl+c", "q", "enter", "esc":
 			return m, tea.Quit
 		}
+	case ui.ClipboardMsg:
+		m.Notice = "Copied the error details to the clipboard"
+		if msg.Err != nil {
+			m.Notice = "Could not copy the error details: " + msg.Err.Error()
+		}
 	case tea.QuitMsg:
 		return m, tea.Quit
 	}
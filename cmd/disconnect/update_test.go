@@ -2,6 +2,8 @@ package disconnect
 
 import (
 	"btui/internal/bluetooth"
+	"btui/internal/ui"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -101,3 +103,91 @@ func TestUpdateShowResult(t *testing.T) {
 		t.Error("Expected quit command when QuitMsg is received")
 	}
 }
+
+func TestCancelDisconnecting(t *testing.T) {
+	model := NewModel()
+	model.State = Disconnecting
+	cancelled := 0
+	model.Cancel = func() { cancelled++ }
+
+	// Esc stops bluetoothctl rather than quitting
+	updatedModel, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m := updatedModel.(Model)
+	if cancelled != 1 || !m.Cancelling || cmd != nil {
+		t.Fatalf("Expected the operation cancelled without quitting, got %d cancels", cancelled)
+	}
+
+	device := bluetooth.BluetoothDevice{MacAddress: "AA:BB:CC:DD:EE:FF", Name: "Test Device"}
+	updatedModel, _ = m.Update(bluetooth.DisconnectMsg{Device: device, Cancelled: true})
+	m = updatedModel.(Model)
+	if m.State != ShowResult || !strings.Contains(m.View(), "Cancelled disconnecting from Test Device") {
+		t.Errorf("Expected the cancelled result, got %q", m.View())
+	}
+
+	// Pressing again while cancelling quits at once
+	m.State = Disconnecting
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC}); cmd == nil {
+		t.Error("Expected a second ctrl+c to quit")
+	}
+}
+
+func TestFailureDetails(t *testing.T) {
+	model := NewModel()
+	model.State = Disconnecting
+	output := "Attempting to disconnect from DC:2C:26:09:D0:0C\nFailed to disconnect: org.bluez.Error.NotConnected Not Connected"
+	updatedModel, cmd := model.Update(bluetooth.DisconnectMsg{
+		Device: bluetooth.BluetoothDevice{MacAddress: "DC:2C:26:09:D0:0C", Name: "Keychron K4"},
+		Output: output,
+		Err:    bluetooth.NewCommandError(output, nil),
+	})
+
+	m := updatedModel.(Model)
+	if cmd != nil || m.Details == nil {
+		t.Fatal("Expected a failure to stay up with its details until dismissed")
+	}
+	view := m.View()
+	for _, expected := range []string{"disconnect failed", "Keychron K4 (DC:2C:26:09:D0:0C)", "Cause:       command failed", "org.bluez.Error.NotConnected"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected the details to show %q, got %q", expected, view)
+		}
+	}
+}
+
+func TestAccessibleAnnouncesStatus(t *testing.T) {
+	t.Cleanup(func() {
+		ui.SetAccessible(false)
+		ui.ApplyTheme(ui.Presets[ui.ThemeDark])
+	})
+	ui.SetAccessible(true)
+
+	device := bluetooth.BluetoothDevice{MacAddress: "AA:BB:CC:DD:EE:FF", Name: "Test Device"}
+	model := NewModel()
+	model.State = Disconnecting
+	model.DevicePicker.Choice = &device
+	if status := model.Status(); status != "Disconnecting from Test Device (AA:BB:CC:DD:EE:FF)" {
+		t.Errorf("Unexpected status %q", status)
+	}
+
+	// The result is printed alongside the tick that quits
+	updatedModel, cmd := model.Update(bluetooth.DisconnectMsg{Device: device, Success: true})
+	m := updatedModel.(Model)
+	if status := m.Status(); status != "Successfully disconnected from Test Device" {
+		t.Errorf("Unexpected status %q", status)
+	}
+	if cmd == nil {
+		t.Fatal("Expected a command")
+	}
+	if batch, ok := cmd().(tea.BatchMsg); !ok || len(batch) != 2 {
+		t.Fatalf("Expected a batch with the quit tick and the announcement, got %T", cmd())
+	}
+
+	// A failure stays up, but is still announced
+	updatedModel, cmd = model.Update(bluetooth.DisconnectMsg{Device: device, Output: "Failed", Err: bluetooth.NewCommandError("org.bluez.Error.NotReady", nil)})
+	m = updatedModel.(Model)
+	if status := m.Status(); !strings.HasPrefix(status, "Could not disconnect from Test Device") {
+		t.Errorf("Unexpected status %q", status)
+	}
+	if cmd == nil {
+		t.Error("Expected the failure announced")
+	}
+}
@@ -3,6 +3,7 @@ package disconnect
 import (
 	"fmt"
 
+	"btui/internal/bluetooth"
 	"btui/internal/ui"
 )
 
@@ -23,7 +24,7 @@ func (m Model) View() string {
 // viewDisconnecting renders the disconnecting state
 func (m Model) viewDisconnecting() string {
 	if m.DevicePicker.Choice == nil {
-		return "Disconnecting...\n\nPress Ctrl+C to cancel."
+		return "Disconnecting...\n\nPress Esc or Ctrl+C to cancel."
 	}
 
 	deviceName := m.DevicePicker.Choice.Name
@@ -31,7 +32,11 @@ func (m Model) viewDisconnecting() string {
 		deviceName = "Unknown Device"
 	}
 
-	return fmt.Sprintf("Disconnecting from %s (%s)...\n\nPress Ctrl+C to cancel.",
+	if m.Cancelling {
+		return fmt.Sprintf("Cancelling disconnecting from %s...\n\nPress Esc or Ctrl+C again to quit now.", deviceName)
+	}
+
+	return fmt.Sprintf("Disconnecting from %s (%s)...\n\nPress Esc or Ctrl+C to cancel.",
 		deviceName,
 		m.DevicePicker.Choice.MacAddress)
 }
@@ -47,14 +52,27 @@ func (m Model) viewResult() string {
 		deviceName = "Unknown Device"
 	}
 
-	style := ui.SuccessStyle()
-	message := "✓ Successfully disconnected"
+	if m.Result.Cancelled {
+		return fmt.Sprintf("Cancelled disconnecting from %s\nMAC: %s\n\nPress any key to exit.",
+			deviceName,
+			m.Result.Device.MacAddress)
+	}
 
 	if !m.Result.Success {
-		style = ui.ErrorStyle()
-		message = "✗ Failed to disconnect"
+		if m.Details == nil {
+			details := bluetooth.FailureDetails(bluetooth.OpDisconnect, m.Result.Device, 0, m.Result.Output, m.Result.Err)
+			m.Details = &details
+		}
+		view := m.Details.View(m.Width)
+		if m.Notice != "" {
+			view += "\n" + m.Notice
+		}
+		return view
 	}
 
+	style := ui.SuccessStyle()
+	message := ui.SuccessMarker() + " Successfully disconnected"
+
 	header := style.Render(fmt.Sprintf("%s from %s", message, deviceName))
 
 	output := ""
@@ -62,12 +80,43 @@ func (m Model) viewResult() string {
 		output = fmt.Sprintf("\nOutput: %s", m.Result.Output)
 	}
 
-	if m.Result.Err != nil {
-		output += fmt.Sprintf("\nError: %s", m.Result.Err.Error())
-	}
-
 	return fmt.Sprintf("%s\nMAC: %s%s\n\nPress any key to exit.",
 		header,
 		m.Result.Device.MacAddress,
 		output)
 }
+
+// Status describes where the disconnect stands in one plain line, empty while a
+// device is being chosen
+func (m Model) Status() string {
+	switch {
+	case m.State == Disconnecting && m.DevicePicker.Choice != nil:
+		name := deviceName(*m.DevicePicker.Choice)
+		if m.Cancelling {
+			return "Cancelling disconnecting from " + name
+		}
+		status := fmt.Sprintf("Disconnecting from %s (%s)", name, m.DevicePicker.Choice.MacAddress)
+		return status
+	case m.State == ShowResult && m.Result != nil:
+		name := deviceName(m.Result.Device)
+		switch {
+		case m.Result.Cancelled:
+			return "Cancelled disconnecting from " + name
+		case m.Result.Success:
+			return "Successfully disconnected from " + name
+		case m.Details != nil && m.Details.Cause != "":
+			return fmt.Sprintf("Could not disconnect from %s: %s", name, m.Details.Cause)
+		default:
+			return "Could not disconnect from " + name
+		}
+	}
+	return ""
+}
+
+// deviceName returns the name a device is shown by
+func deviceName(device bluetooth.BluetoothDevice) string {
+	if device.Name == "" {
+		return "Unknown Device"
+	}
+	return device.Name
+}
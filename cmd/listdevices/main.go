@@ -2,6 +2,8 @@
 package listdevices
 
 import (
+	"btui/internal/query"
+	"btui/internal/ui"
 	"fmt"
 	"os"
 
@@ -16,6 +18,8 @@ func New() *cobra.Command {
 	c.Short = "List and select Bluetooth devices"
 	c.Long = "Display a list of available Bluetooth devices and allow selection"
 	c.Run = run
+	c.Flags().String("where", "",
+		`only list devices matching a query, e.g. "status:connected name:bose mac:4C:87"`)
 	return c
 }
 
@@ -23,7 +27,15 @@ func New() *cobra.Command {
 func run(cmd *cobra.Command, args []string) {
 	m := NewModel()
 
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	where, _ := cmd.Flags().GetString("where")
+	q, err := query.Parse(where)
+	if err != nil {
+		fmt.Printf("Error: invalid --where query: %v\n", err)
+		os.Exit(1)
+	}
+	m.Where = q
+
+	p := tea.NewProgram(m, ui.ProgramOptions()...)
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
@@ -2,6 +2,8 @@
 package listdevices
 
 import (
+	"btui/internal/query"
+
 	"github.com/charmbracelet/bubbles/list"
 )
 
@@ -14,6 +16,7 @@ type Model struct {
 	Err      error
 	Width    int
 	Height   int
+	Where    query.Query // only devices matching this query are listed
 }
 
 // NewModel creates a new model for the list devices command
@@ -1,6 +1,8 @@
 // Package listdevices contains types for the list devices command
 package listdevices
 
+import "btui/internal/bluetooth"
+
 // BluetoothDevice represents a Bluetooth device
 type BluetoothDevice struct {
 	MacAddress string
@@ -12,5 +14,8 @@ type BluetoothDevice struct {
 type DevicesMsg struct {
 	Devices          []string
 	ConnectedDevices []string
-	Err              error
+	// Info holds the properties of each device by MAC address, read only
+	// when a --where query may need its type or signal
+	Info map[string]bluetooth.DeviceInfo
+	Err  error
 }
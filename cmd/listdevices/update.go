@@ -2,50 +2,52 @@
 package listdevices
 
 import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/query"
+	"btui/internal/state"
 	"btui/internal/ui"
+	"context"
 	"fmt"
-	"os/exec"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func FetchDevicesCmd() tea.Cmd {
+// FetchDevicesCmd lists the paired devices, with the properties of each
+// when withInfo is set
+func FetchDevicesCmd(withInfo bool) tea.Cmd {
 	return func() tea.Msg {
 		// Fetch all devices
-		allDevicesCmd := exec.Command("bluetoothctl", "devices")
+		allDevicesCmd := bluetooth.Bluetoothctl(context.Background(), "devices")
 		allDevicesOutput, err := allDevicesCmd.Output()
 		if err != nil {
 			return DevicesMsg{Err: fmt.Errorf("issue with bluetoothctl devices: %w", err)}
 		}
 
-		// Split output into lines and filter empty ones
-		allDevicesLines := strings.Split(strings.TrimSpace(string(allDevicesOutput)), "\n")
-		var devices []string
-		for _, line := range allDevicesLines {
-			if strings.TrimSpace(line) != "" {
-				devices = append(devices, line)
-			}
-		}
+		devices := bluetooth.DeviceLines(string(allDevicesOutput))
 
 		// Fetch connected devices
-		connectedDevicesCmd := exec.Command("bluetoothctl", "devices", "Connected")
+		connectedDevicesCmd := bluetooth.Bluetoothctl(context.Background(), "devices", "Connected")
 		connectedDevicesOutput, err := connectedDevicesCmd.Output()
 		if err != nil {
 			return DevicesMsg{Err: fmt.Errorf("issue with bluetoothctl devices Connected: %w", err)}
 		}
 
-		// Split output into lines and filter empty ones
-		connectedDevicesLines := strings.Split(strings.TrimSpace(string(connectedDevicesOutput)), "\n")
-		var connectedDevices []string
-		for _, line := range connectedDevicesLines {
-			if strings.TrimSpace(line) != "" {
-				connectedDevices = append(connectedDevices, line)
+		connectedDevices := bluetooth.DeviceLines(string(connectedDevicesOutput))
+
+		msg := DevicesMsg{Devices: devices, ConnectedDevices: connectedDevices}
+		if withInfo {
+			// A device whose properties cannot be read is matched without them
+			msg.Info = make(map[string]bluetooth.DeviceInfo, len(devices))
+			for _, line := range devices {
+				if device := parseDeviceLine(line, nil); device.MacAddress != "" {
+					msg.Info[device.MacAddress], _ = bluetooth.FetchDeviceInfo(device.MacAddress)
+				}
 			}
 		}
-
-		return DevicesMsg{Devices: devices, ConnectedDevices: connectedDevices}
+		return msg
 	}
 }
 
@@ -80,7 +82,7 @@ func deviceToListItem(d BluetoothDevice) list.Item {
 
 	// Add connection status indicator to title
 	if d.Connected {
-		title = "🔗 " + title
+		title = ui.ConnectedMarker() + title
 	}
 
 	description := d.MacAddress
@@ -115,9 +117,29 @@ func devicesToListItems(deviceLines []string, connectedDeviceLines []string) []l
 	return items
 }
 
+// whereItems keeps the list items whose device matches a query. Devices from
+// `bluetoothctl devices` are paired; their type and signal come from info,
+// and their nickname, note and tags from the state file.
+func whereItems(items []list.Item, where query.Query, info map[string]bluetooth.DeviceInfo) []list.Item {
+	if where.Empty() {
+		return items
+	}
+	store := state.Get()
+	matched := make([]list.Item, 0, len(items))
+	for _, item := range items {
+		device := item.(ui.GenericItem).Value.(BluetoothDevice)
+		paired := bluetooth.BluetoothDevice{MacAddress: device.MacAddress, Name: device.Name, Connected: device.Connected, Paired: true}
+		details := info[device.MacAddress]
+		if where.Match(bluetooth.QueryDevice(paired, store.Meta(device.MacAddress), details.Icon, details.RSSI)) {
+			matched = append(matched, item)
+		}
+	}
+	return matched
+}
+
 // Init implements tea.Model
 func (m Model) Init() tea.Cmd {
-	return FetchDevicesCmd()
+	return FetchDevicesCmd(!m.Where.Empty())
 }
 
 // Update implements tea.Model
@@ -156,20 +178,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		// Convert device strings to list items
-		items := devicesToListItems(msg.Devices, msg.ConnectedDevices)
+		// Convert device strings to list items, keeping those matching --where
+		items := whereItems(devicesToListItems(msg.Devices, msg.ConnectedDevices), m.Where, msg.Info)
 
 		// Create the list with stored dimensions
 		width := m.Width
 		height := m.Height
 		if width == 0 {
-			width = 80
+			width = config.Get().Window.Width
 		}
 		if height == 0 {
-			height = 14
+			height = config.Get().Window.Height
 		}
 
-		m.List = ui.NewList(items, "Bluetooth Devices", width, height)
+		title := "Bluetooth Devices"
+		if !m.Where.Empty() {
+			title += " [" + m.Where.String() + "]"
+		}
+		m.List = ui.NewList(items, title, width, height)
 		return m, nil
 	}
 
@@ -1,6 +1,9 @@
 package listdevices
 
 import (
+	"btui/internal/bluetooth"
+	"btui/internal/query"
+	"btui/internal/state"
 	"btui/internal/ui"
 	"fmt"
 	"strings"
@@ -218,3 +221,89 @@ func TestUpdateQuitKey(t *testing.T) {
 		t.Error("Expected quit command")
 	}
 }
+
+func TestUpdateDevicesMsgWhere(t *testing.T) {
+	model := NewModel()
+	where, err := query.Parse("status:connected")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	model.Where = where
+
+	updatedModel, _ := model.Update(DevicesMsg{
+		Devices: []string{
+			"Device AA:BB:CC:DD:EE:FF Test Device",
+			"Device 11:22:33:44:55:66 Connected Device",
+		},
+		ConnectedDevices: []string{"Device 11:22:33:44:55:66 Connected Device"},
+	})
+	m := updatedModel.(Model)
+
+	if len(m.List.Items()) != 1 {
+		t.Fatalf("Expected 1 matching device, got %d", len(m.List.Items()))
+	}
+	device := m.List.Items()[0].(ui.GenericItem).Value.(BluetoothDevice)
+	if device.MacAddress != "11:22:33:44:55:66" {
+		t.Errorf("Expected the connected device, got %s", device.MacAddress)
+	}
+	if m.List.Title != "Bluetooth Devices [status:connected]" {
+		t.Errorf("Expected the query in the title, got %q", m.List.Title)
+	}
+}
+
+func TestUpdateDevicesMsgWhereType(t *testing.T) {
+	model := NewModel()
+	where, err := query.Parse("type:audio-headphones rssi>-70")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	model.Where = where
+
+	updatedModel, _ := model.Update(DevicesMsg{
+		Devices: []string{
+			"Device 4C:87:5D:28:86:DD Bose NC 700",
+			"Device DC:2C:26:09:D0:0C Keychron K4",
+			"Device AA:BB:CC:DD:EE:FF Far Headphones",
+		},
+		Info: map[string]bluetooth.DeviceInfo{
+			"4C:87:5D:28:86:DD": {Icon: "audio-headphones", RSSI: -55},
+			"DC:2C:26:09:D0:0C": {Icon: "input-keyboard", RSSI: -50},
+			"AA:BB:CC:DD:EE:FF": {Icon: "audio-headphones", RSSI: -85},
+		},
+	})
+	m := updatedModel.(Model)
+
+	if len(m.List.Items()) != 1 {
+		t.Fatalf("Expected 1 matching device, got %d", len(m.List.Items()))
+	}
+	if device := m.List.Items()[0].(ui.GenericItem).Value.(BluetoothDevice); device.Name != "Bose NC 700" {
+		t.Errorf("Expected the nearby headphones, got %s", device.Name)
+	}
+}
+
+func TestUpdateDevicesMsgWhereMeta(t *testing.T) {
+	store := state.New("")
+	store.SetMeta("4C:87:5D:28:86:DD", state.Meta{Nickname: "Work headset", Tags: []string{"desk"}})
+	state.Set(store)
+	t.Cleanup(func() { state.Set(state.New("")) })
+
+	for _, where := range []string{"tag:desk", "work"} {
+		model := NewModel()
+		q, err := query.Parse(where)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		model.Where = q
+
+		updatedModel, _ := model.Update(DevicesMsg{
+			Devices: []string{
+				"Device 4C:87:5D:28:86:DD Bose NC 700",
+				"Device DC:2C:26:09:D0:0C Keychron K4",
+			},
+		})
+		items := updatedModel.(Model).List.Items()
+		if len(items) != 1 || items[0].(ui.GenericItem).Value.(BluetoothDevice).MacAddress != "4C:87:5D:28:86:DD" {
+			t.Errorf("--where %q: expected only the tagged headset, got %v", where, items)
+		}
+	}
+}
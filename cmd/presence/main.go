@@ -0,0 +1,135 @@
+// Package presence contains the entry point for the presence command, which
+// listens during discovery and prints whether the devices of each presence
+// rule are present
+package presence
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/presence"
+	"btui/internal/state"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// New creates a new cobra command for printing presence
+func New() *cobra.Command {
+	c := &cobra.Command{}
+	c.Use = "presence"
+	c.Short = "Show which devices of the presence rules are present"
+	c.Long = "Run discovery for a while, then print for each presence rule whether its devices are present or absent"
+	c.Run = run
+	c.Flags().Bool("json", false, "print a JSON array")
+	c.Flags().Duration("duration", 10*time.Second, "how long to listen before printing")
+	return c
+}
+
+// State is one line of the presence output
+type State struct {
+	Rule    string `json:"rule"`
+	Present bool   `json:"present"`
+	// MAC, Name and RSSI describe the device as last heard, left out for a
+	// rule no device has matched
+	MAC  string `json:"mac,omitempty"`
+	Name string `json:"name,omitempty"`
+	RSSI int    `json:"rssi,omitempty"`
+	// Since is when the device arrived or left, left out if it never arrived
+	Since time.Time `json:"since,omitzero"`
+}
+
+// run executes the presence command
+func run(cmd *cobra.Command, args []string) {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	duration, _ := cmd.Flags().GetDuration("duration")
+
+	cfg := config.Get()
+	if len(cfg.Presence.Rules) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no presence rules; add [[presence.rule]] entries to the config file")
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	scanner := bluetooth.NewDiscoveryScanner()
+	if err := scanner.StartDiscovery(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer scanner.StopDiscovery()
+
+	evaluator := presence.NewEvaluator(cfg.Presence.Resolved(), time.Now)
+	ticker := time.NewTicker(cfg.Intervals.Discovery)
+	defer ticker.Stop()
+	done := time.After(duration)
+	for listening := true; listening; {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		case <-done:
+			listening = false
+		}
+		evaluator.Observe(presence.Sightings(scanner.GetDiscoveredDevices(), state.Get()))
+	}
+
+	if err := write(cmd.OutOrStdout(), states(evaluator.Status()), asJSON); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// states describes each device of each rule, by the name btui shows it by
+func states(statuses []presence.Status) []State {
+	store := state.Get()
+	result := make([]State, len(statuses))
+	for i, s := range statuses {
+		result[i] = State{Rule: s.Rule, Present: s.Present, MAC: s.Device.MAC, RSSI: s.Device.RSSI, Since: s.Since}
+		if s.Device.MAC != "" {
+			result[i].Name = store.DisplayName(s.Device.MAC, s.Device.Name)
+		}
+	}
+	return result
+}
+
+// write prints the states as a JSON array or one line each
+func write(out io.Writer, states []State, asJSON bool) error {
+	if asJSON {
+		if states == nil {
+			states = []State{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(states)
+	}
+
+	width := 0
+	for _, s := range states {
+		width = max(width, len(s.Rule))
+	}
+	for _, s := range states {
+		status := "absent"
+		if s.Present {
+			status = "present"
+		}
+		line := fmt.Sprintf("%-*s %-7s", width, s.Rule, status)
+		if s.MAC == "" {
+			line += " (not heard)"
+		} else {
+			line += fmt.Sprintf(" %s %s (%d dBm)", s.MAC, s.Name, s.RSSI)
+		}
+		if !s.Since.IsZero() {
+			line += " since " + s.Since.Format("15:04:05")
+		}
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package presence
+
+import (
+	"btui/internal/presence"
+	"btui/internal/query"
+	"btui/internal/state"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+var since = time.Date(2024, 5, 1, 8, 30, 15, 0, time.UTC)
+
+var statuses = []presence.Status{
+	{Rule: "phone", Device: query.Device{MAC: "AA:BB:CC:DD:EE:01", Name: "Pixel", RSSI: -62}, Present: true, Since: since},
+	{Rule: "watch", Device: query.Device{MAC: "11:22:33:44:55:66", Name: "Watch", RSSI: -88}},
+	{Rule: "car", Present: false},
+}
+
+func TestWriteText(t *testing.T) {
+	store := state.New("")
+	store.SetMeta("AA:BB:CC:DD:EE:01", state.Meta{Nickname: "My phone"})
+	state.Set(store)
+
+	var out bytes.Buffer
+	if err := write(&out, states(statuses), false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "phone present AA:BB:CC:DD:EE:01 My phone (-62 dBm) since 08:30:15\n" +
+		"watch absent  11:22:33:44:55:66 Watch (-88 dBm)\n" +
+		"car   absent  (not heard)\n"
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	state.Set(state.New(""))
+	var out bytes.Buffer
+	if err := write(&out, states(statuses), true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var decoded []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(decoded) != 3 || decoded[0]["present"] != true || decoded[0]["since"] != "2024-05-01T08:30:15Z" {
+		t.Errorf("Unexpected first state %v", decoded[0])
+	}
+	if _, ok := decoded[1]["since"]; ok {
+		t.Errorf("Expected no since for a device that never arrived, got %v", decoded[1])
+	}
+	if car := decoded[2]; car["rule"] != "car" || car["mac"] != nil || car["present"] != false {
+		t.Errorf("Unexpected state for an unheard rule %v", car)
+	}
+
+	out.Reset()
+	if err := write(&out, nil, true); err != nil || out.String() != "[]\n" {
+		t.Errorf("Expected an empty array, got %q (%v)", out.String(), err)
+	}
+}
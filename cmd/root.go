@@ -2,10 +2,20 @@
 package cmd
 
 import (
+	"btui/cmd/alias"
+	"btui/cmd/catalog"
 	"btui/cmd/connect"
 	"btui/cmd/disconnect"
 	"btui/cmd/listdevices"
+	"btui/cmd/presence"
 	"btui/cmd/scan"
+	"btui/cmd/scene"
+	"btui/cmd/status"
+	"btui/cmd/watch"
+	"btui/internal/config"
+	"btui/internal/menu"
+	"btui/internal/state"
+	"btui/internal/ui"
 	"context"
 	"fmt"
 	"os"
@@ -15,14 +25,47 @@ import (
 )
 
 func New() *cobra.Command {
+	var configPath string
+	var accessible bool
+
 	rootCmd := &cobra.Command{
 		Use:   "btui",
 		Short: "A TUI for interacting with bluetoothctl",
 		Long:  "btui provides a terminal user interface for managing Bluetooth devices using bluetoothctl",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+			config.Set(cfg)
+
+			// A state file that cannot be read should not lock the user out of
+			// connecting and listing devices, so carry on without it; the
+			// empty store is kept in memory and leaves the file as it is
+			store, err := state.Load("")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v; starting with no saved state, and changes will not be saved\n", err)
+				store = state.New("")
+			}
+			state.Set(store)
+
+			// Plain mode for screen readers and dumb terminals replaces the theme
+			// entirely, so the terminal is not queried for its background
+			if accessible || cfg.Accessible || ui.AccessibleFromEnv() {
+				ui.SetAccessible(true)
+				return nil
+			}
+
+			palette, err := ui.ResolveTheme(cfg.Theme.Name, cfg.Theme.Palettes)
+			if err != nil {
+				return err
+			}
+			ui.ApplyTheme(palette)
+			return nil
+		},
 		Run: func(cmd *cobra.Command, args []string) {
-			// Launch the scan interface directly
-			m := scan.NewModel()
-			p := tea.NewProgram(m, tea.WithAltScreen())
+			// Launch the configured startup view (the scan interface by default)
+			p := tea.NewProgram(startupModel(config.Get().StartupView), ui.ProgramOptions()...)
 			if _, err := p.Run(); err != nil {
 				fmt.Printf("Error running program: %v\n", err)
 				os.Exit(1)
@@ -30,15 +73,42 @@ func New() *cobra.Command {
 		},
 	}
 
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "",
+		"path to config file (default $XDG_CONFIG_HOME/btui/config.toml)")
+	rootCmd.PersistentFlags().BoolVar(&accessible, "accessible", false,
+		"plain output for screen readers: no colour, alt screen or animations (also enabled by NO_COLOR or TERM=dumb)")
+
 	// Keep individual commands for direct CLI access if needed
 	rootCmd.AddCommand(listdevices.New())
 	rootCmd.AddCommand(connect.New())
 	rootCmd.AddCommand(disconnect.New())
 	rootCmd.AddCommand(scan.New())
+	rootCmd.AddCommand(watch.New())
+	rootCmd.AddCommand(catalog.New())
+	rootCmd.AddCommand(scene.New())
+	rootCmd.AddCommand(alias.New())
+	rootCmd.AddCommand(status.New())
+	rootCmd.AddCommand(presence.New())
 
 	return rootCmd
 }
 
+// startupModel returns the model for the view btui opens with
+func startupModel(view string) tea.Model {
+	switch view {
+	case config.ViewMenu:
+		return menu.NewModel()
+	case config.ViewListDevices:
+		return listdevices.NewModel()
+	case config.ViewConnect:
+		return connect.NewModel()
+	case config.ViewDisconnect:
+		return disconnect.NewModel()
+	default:
+		return scan.NewModel()
+	}
+}
+
 func Execute(ctx context.Context, cmd *cobra.Command) error {
 	_, err := cmd.ExecuteContextC(ctx)
 	if err != nil {
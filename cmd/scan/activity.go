@@ -0,0 +1,239 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/state"
+	"btui/internal/ui"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// activitySize is how many entries the activity log keeps
+const activitySize = 500
+
+// activityEntry is one event in the activity log
+type activityEntry struct {
+	At       time.Time
+	Severity ui.Severity
+	// Device is the MAC address of the device the event is about, if any
+	Device  string
+	Message string
+}
+
+// text formats the entry as a plain line, as saved to a file
+func (e activityEntry) text() string {
+	return fmt.Sprintf("%s %-5s %s", e.At.Format(time.DateTime), strings.ToUpper(e.Severity.String()), e.Message)
+}
+
+// view formats the entry for the log pane
+func (e activityEntry) view() string {
+	severity := e.Severity.Style().Render(fmt.Sprintf("%-5s", e.Severity))
+	return e.At.Format(time.TimeOnly) + " " + severity + " " + e.Message
+}
+
+// ActivitySavedMsg is sent when the activity log has been written to a file
+type ActivitySavedMsg struct {
+	Path string
+	Err  error
+}
+
+// logKeys are the extra bindings of the activity log view
+var logKeys = struct {
+	Device key.Binding
+	Save   key.Binding
+	Close  key.Binding
+}{
+	Device: key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "selected device only")),
+	Save:   key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "save to file")),
+	Close:  key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+}
+
+// logActivity adds an event to the activity log, dropping the oldest once
+// it is full
+func (m *Model) logActivity(severity ui.Severity, device, message string) {
+	m.Activity = append(m.Activity, activityEntry{At: time.Now(), Severity: severity, Device: device, Message: message})
+	if len(m.Activity) > activitySize {
+		m.Activity = m.Activity[len(m.Activity)-activitySize:]
+	}
+	if m.ViewingLog {
+		m.refreshLog()
+	}
+}
+
+// logOperation records an operation starting, being retried or finishing
+func (m *Model) logOperation(op bluetooth.Operation) {
+	mac := op.Device.MacAddress
+	switch op.State {
+	case bluetooth.OpRunning:
+		severity := ui.SeverityInfo
+		if op.Attempt > 1 {
+			severity = ui.SeverityWarning
+		}
+		m.logActivity(severity, mac, m.startText(op))
+	case bluetooth.OpSucceeded:
+		m.logActivity(ui.SeveritySuccess, mac, resultMessage(op.Device, op.Action, true, op.Output))
+	case bluetooth.OpFailed:
+		m.logActivity(ui.SeverityError, mac, resultMessage(op.Device, op.Action, false, op.Output))
+	case bluetooth.OpCancelled:
+		m.logActivity(ui.SeverityWarning, mac, "Cancelled "+op.Action+" of "+displayName(op.Device))
+	}
+}
+
+// logDiscovered records devices coming into and going out of range since
+// the previous discovery update
+func (m *Model) logDiscovered(previous, current []bluetooth.DiscoveredDevice) {
+	seen := make(map[string]bool, len(previous))
+	for _, device := range previous {
+		seen[device.MacAddress] = true
+	}
+	for _, device := range current {
+		if !seen[device.MacAddress] {
+			m.logActivity(ui.SeverityInfo, device.MacAddress, "Discovered "+displayName(device.BluetoothDevice))
+		}
+		delete(seen, device.MacAddress)
+	}
+	for _, device := range previous {
+		if seen[device.MacAddress] {
+			m.logActivity(ui.SeverityInfo, device.MacAddress, displayName(device.BluetoothDevice)+" went out of range")
+		}
+	}
+}
+
+// logEntries returns the entries shown, limited to one device when the log
+// is filtered
+func (m Model) logEntries() []activityEntry {
+	if m.LogDevice == "" {
+		return m.Activity
+	}
+	var entries []activityEntry
+	for _, entry := range m.Activity {
+		if entry.Device == m.LogDevice {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// refreshLog fills the log pane with the entries shown, following new ones
+// unless the user has scrolled up
+func (m *Model) refreshLog() {
+	follow := m.LogView.AtBottom() || m.LogView.TotalLineCount() == 0
+	entries := m.logEntries()
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = entry.view()
+	}
+	if len(lines) == 0 {
+		lines = []string{"Nothing has happened yet"}
+	}
+	m.LogView.SetContent(strings.Join(lines, "\n"))
+	if follow {
+		m.LogView.GotoBottom()
+	}
+}
+
+// sizeLog fits the log pane to the main pane, leaving room for its title
+// and help line
+func (m *Model) sizeLog() {
+	pane := m.layout().Main
+	m.LogView.Width = pane.Width
+	m.LogView.Height = max(pane.Height-3, 1)
+}
+
+// openLog shows the activity log view
+func (m Model) openLog() (tea.Model, tea.Cmd) {
+	m.LogView = viewport.New(0, 0)
+	// f filters the log, so only the usual page keys scroll it
+	m.LogView.KeyMap.PageDown = key.NewBinding(key.WithKeys("pgdown", " "))
+	m.LogView.KeyMap.PageUp = key.NewBinding(key.WithKeys("pgup"))
+	m.LogDevice = ""
+	m.ViewingLog = true
+	m.sizeLog()
+	m.refreshLog()
+	return m, nil
+}
+
+// logTitle names the log, and the device it is limited to
+func (m Model) logTitle() string {
+	if m.LogDevice == "" {
+		return "Activity"
+	}
+	for _, device := range m.knownDevices() {
+		if device.MacAddress == m.LogDevice {
+			return "Activity of " + displayName(device)
+		}
+	}
+	return "Activity of " + m.LogDevice
+}
+
+// logView renders the activity log view
+func (m Model) logView() string {
+	help := []string{}
+	for _, b := range []key.Binding{logKeys.Device, logKeys.Save, logKeys.Close} {
+		help = append(help, b.Help().Key+" "+b.Help().Desc)
+	}
+	return ui.TitleStyle.Render(m.logTitle()) + "\n\n" + m.LogView.View() + "\n" +
+		ui.PaginationStyle.Render(ui.JoinDescription(help...))
+}
+
+// updateLog handles keys in the activity log view
+func (m Model) updateLog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, logKeys.Close, m.Keys.Log):
+		m.ViewingLog = false
+		return m, nil
+
+	case key.Matches(msg, m.Keys.Quit):
+		m.ViewingLog = false
+		return m.update(msg)
+
+	case key.Matches(msg, logKeys.Device):
+		if m.LogDevice != "" {
+			m.LogDevice = ""
+		} else if device, ok := m.selectedDevice(); ok {
+			m.LogDevice = device.MacAddress
+		}
+		m.LogView.SetContent("")
+		m.refreshLog()
+		return m, nil
+
+	case key.Matches(msg, logKeys.Save):
+		return m, saveActivity(m.logEntries())
+	}
+
+	var cmd tea.Cmd
+	m.LogView, cmd = m.LogView.Update(msg)
+	return m, cmd
+}
+
+// saveActivity returns a command writing log entries to a timestamped file
+// beside the state file
+func saveActivity(entries []activityEntry) tea.Cmd {
+	return func() tea.Msg {
+		dir := filepath.Dir(state.Get().Path())
+		if state.Get().Path() == "" {
+			path, err := state.DefaultPath()
+			if err != nil {
+				return ActivitySavedMsg{Err: err}
+			}
+			dir = filepath.Dir(path)
+		}
+		path := filepath.Join(dir, "activity-"+time.Now().Format("20060102-150405")+".log")
+
+		var b strings.Builder
+		for _, entry := range entries {
+			b.WriteString(entry.text() + "\n")
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return ActivitySavedMsg{Err: err}
+		}
+		return ActivitySavedMsg{Path: path, Err: os.WriteFile(path, []byte(b.String()), 0o644)}
+	}
+}
@@ -0,0 +1,113 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/ui"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// activityMessages returns the messages of the logged entries
+func activityMessages(entries []activityEntry) []string {
+	messages := make([]string, len(entries))
+	for i, entry := range entries {
+		messages[i] = entry.Message
+	}
+	return messages
+}
+
+func TestActivityLogsOperations(t *testing.T) {
+	model := keys(selectDevice(t, viewFixture(), "Bose NC 700 Headphones"), "c")
+	model = untilIdle(model)
+
+	var bose []activityEntry
+	for _, entry := range model.Activity {
+		if entry.Device == "4C:87:5D:28:86:DD" {
+			bose = append(bose, entry)
+		}
+	}
+	messages := activityMessages(bose)
+	expected := []string{"Connecting to Bose NC 700 Headphones...", "Successfully connected to Bose NC 700 Headphones"}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("Unexpected entries %q", messages)
+	}
+	if bose[0].Severity != ui.SeverityInfo || bose[1].Severity != ui.SeveritySuccess {
+		t.Errorf("Unexpected severities %v and %v", bose[0].Severity, bose[1].Severity)
+	}
+}
+
+func TestActivityLogsDiscovery(t *testing.T) {
+	model := viewFixture()
+	if messages := activityMessages(model.Activity); !strings.Contains(strings.Join(messages, "\n"), "Discovered Living Room TV") {
+		t.Errorf("Expected the TV discovered, got %q", messages)
+	}
+
+	model, _ = updateModel(model, bluetooth.DiscoveryUpdateMsg{})
+	last := model.Activity[len(model.Activity)-1]
+	if last.Message != "Living Room TV went out of range" || last.Device != "F0:99:B6:12:34:56" {
+		t.Errorf("Unexpected entry %+v", last)
+	}
+}
+
+func TestActivityLogView(t *testing.T) {
+	model := keys(selectDevice(t, viewFixture(), "Bose NC 700 Headphones"), "c")
+	model = untilIdle(model)
+
+	model = keys(model, "L")
+	if !model.ViewingLog || !model.modalOpen() {
+		t.Fatal("Expected the activity log open")
+	}
+	view := model.View()
+	if !strings.Contains(view, "Discovered Living Room TV") || !strings.Contains(view, "Successfully connected") {
+		t.Errorf("Expected every entry shown, got:\n%s", view)
+	}
+
+	// f limits the log to the selected device, and again lifts the limit
+	model = keys(model, "f")
+	view = model.View()
+	if !strings.Contains(view, "Activity of Bose NC 700 Headphones") || strings.Contains(view, "Living Room TV") {
+		t.Errorf("Expected only the headphones shown, got:\n%s", view)
+	}
+	if model = keys(model, "f"); model.LogDevice != "" {
+		t.Error("Expected the device filter lifted")
+	}
+
+	if model = keys(model, "L"); model.ViewingLog {
+		t.Error("Expected L to close the activity log")
+	}
+}
+
+func TestSaveActivity(t *testing.T) {
+	useState(t)
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	model := viewFixture()
+	model = keys(model, "L")
+	model, cmd := updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	if cmd == nil {
+		t.Fatal("Expected a command saving the log")
+	}
+
+	msg := cmd().(ActivitySavedMsg)
+	if msg.Err != nil {
+		t.Fatal(msg.Err)
+	}
+	if dir := filepath.Join(os.Getenv("XDG_STATE_HOME"), "btui"); filepath.Dir(msg.Path) != dir {
+		t.Errorf("Expected the log saved in %s, got %s", dir, msg.Path)
+	}
+	data, err := os.ReadFile(msg.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "INFO  Discovered Living Room TV") {
+		t.Errorf("Unexpected log file:\n%s", data)
+	}
+
+	model, _ = updateModel(model, msg)
+	if model.StatusMessage != "Saved the activity log to "+msg.Path {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+}
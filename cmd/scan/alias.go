@@ -0,0 +1,81 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// aliasPrompt reads a new Bluetooth alias for a device
+type aliasPrompt struct {
+	Device bluetooth.BluetoothDevice
+	Input  textinput.Model
+}
+
+// startAlias opens the alias prompt for a device, starting from its name
+func (m Model) startAlias(device bluetooth.BluetoothDevice) (tea.Model, tea.Cmd) {
+	input := textinput.New()
+	input.Prompt = ""
+	input.CharLimit = 248 // the longest name Bluetooth allows
+	input.SetValue(device.Name)
+	input.Focus()
+	m.Renaming = &aliasPrompt{Device: device, Input: input}
+	return m, textinput.Blink
+}
+
+// aliasPromptText is the status line shown while the alias prompt is open
+func (m Model) aliasPromptText() string {
+	return "Alias (empty resets, esc cancels): " + m.Renaming.Input.View()
+}
+
+// updateAlias handles keys while the alias prompt is open
+func (m Model) updateAlias(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := *m.Renaming
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.Renaming = nil
+		return m, nil
+	case tea.KeyEnter:
+		m.Renaming = nil
+		alias := p.Input.Value()
+		if alias == "" {
+			m.StatusMessage = "Resetting the alias of " + p.Device.Name + "..."
+		} else {
+			m.StatusMessage = fmt.Sprintf("Renaming %s to %s...", p.Device.Name, alias)
+		}
+		return m, bluetooth.SetAliasCmd(p.Device, alias)
+	}
+
+	var cmd tea.Cmd
+	p.Input, cmd = p.Input.Update(msg)
+	m.Renaming = &p
+	return m, cmd
+}
+
+// applyAlias shows a device under its new alias as soon as it is set
+func (m *Model) applyAlias(msg bluetooth.AliasMsg) {
+	if msg.Err != nil {
+		m.StatusMessage = "Could not rename " + msg.Device.Name + ": " + msg.Err.Error()
+		return
+	}
+
+	mac := msg.Device.MacAddress
+	if m.DeviceInfo == nil {
+		m.DeviceInfo = make(map[string]bluetooth.DeviceInfo)
+	}
+	m.DeviceInfo[mac] = msg.Info
+	for i := range m.PairedDevices {
+		if m.PairedDevices[i].MacAddress == mac {
+			m.PairedDevices[i].Name = msg.Info.Alias
+		}
+	}
+	for i := range m.DiscoveredDevices {
+		if m.DiscoveredDevices[i].MacAddress == mac {
+			m.DiscoveredDevices[i].Name = msg.Info.Alias
+		}
+	}
+	m.StatusMessage = fmt.Sprintf("%s is now called %s", msg.Device.Name, msg.Info.Alias)
+	m.refreshDevices()
+}
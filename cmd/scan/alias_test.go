@@ -0,0 +1,71 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAliasPrompt(t *testing.T) {
+	model := selectDevice(t, viewFixture(), "Keychron K4")
+
+	model = keys(model, "a")
+	if model.Renaming == nil || model.Renaming.Input.Value() != "Keychron K4" {
+		t.Fatal("Expected the alias prompt to start from the device name")
+	}
+	if !strings.Contains(model.View(), "Alias (empty resets, esc cancels)") {
+		t.Error("Expected the alias prompt in the status line")
+	}
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.Renaming != nil {
+		t.Error("Expected esc to close the prompt")
+	}
+
+	// Clearing the input resets the alias; the command is not run here as it
+	// would talk to BlueZ
+	model = keys(model, "a")
+	for range len("Keychron K4") {
+		model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	model, cmd := updateModel(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.Renaming != nil || cmd == nil {
+		t.Error("Expected enter to close the prompt and set the alias")
+	}
+	if model.StatusMessage != "Resetting the alias of Keychron K4..." {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+}
+
+func TestAliasApplied(t *testing.T) {
+	model := viewFixture()
+	device := model.PairedDevices[1]
+
+	model, _ = updateModel(model, bluetooth.AliasMsg{
+		Device: device,
+		Info:   bluetooth.DeviceInfo{MacAddress: device.MacAddress, Name: "Keychron K4", Alias: "Desk keyboard"},
+	})
+	if !strings.Contains(strings.Join(visibleNames(model), ","), "Desk keyboard") {
+		t.Errorf("Expected the list to show the new alias, got %v", visibleNames(model))
+	}
+	if model.StatusMessage != "Keychron K4 is now called Desk keyboard" {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+	if model.DeviceInfo[device.MacAddress].Alias != "Desk keyboard" {
+		t.Error("Expected the device info to be updated")
+	}
+}
+
+func TestAliasFailed(t *testing.T) {
+	model := viewFixture()
+	model, _ = updateModel(model, bluetooth.AliasMsg{
+		Device: model.PairedDevices[1],
+		Err:    errors.New("device DC:2C:26:09:D0:0C not found"),
+	})
+	if !strings.HasPrefix(model.StatusMessage, "Could not rename Keychron K4:") {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+}
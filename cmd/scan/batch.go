@@ -0,0 +1,479 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/state"
+	"btui/internal/ui"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Operations that can run on a device, named as recorded in LastResult
+const (
+	opConnect    = bluetooth.OpConnect
+	opDisconnect = bluetooth.OpDisconnect
+	opTrust      = bluetooth.OpTrust
+	opRemove     = bluetooth.OpRemove
+)
+
+// runOperation carries out the operations queued by the scan view. Tests
+// replace it so they never run bluetoothctl.
+var runOperation bluetooth.Runner = bluetooth.RunOperation
+
+// operationLabels holds the status shown while an operation runs and the
+// verbs used in status messages
+var operationLabels = map[string]struct {
+	status, running, done string
+}{
+	opConnect:    {statusConnecting, "Connecting", "Connected"},
+	opDisconnect: {statusDisconnecting, "Disconnecting", "Disconnected"},
+	opTrust:      {statusTrusting, "Trusting", "Trusted"},
+	opRemove:     {statusRemoving, "Removing", "Removed"},
+}
+
+// batch follows one operation started on several marked devices at once
+type batch struct {
+	Action  string
+	Devices []bluetooth.BluetoothDevice
+	// Ops holds the IDs of the batch's operations, which may be queued
+	// behind others on the same devices
+	Ops     []int
+	Skipped int
+	Results map[string]operationResult
+}
+
+// deviceOperations returns the queued and running operations on a device,
+// in the order they will run
+func (m Model) deviceOperations(mac string) []bluetooth.Operation {
+	var ops []bluetooth.Operation
+	for _, op := range m.Operations {
+		if op.Device.MacAddress == mac {
+			ops = append(ops, op)
+		}
+	}
+	slices.SortFunc(ops, func(a, b bluetooth.Operation) int { return a.ID - b.ID })
+	return ops
+}
+
+// busy reports whether an operation is queued or running on a device
+func (m Model) busy(device bluetooth.BluetoothDevice) bool {
+	return len(m.deviceOperations(device.MacAddress)) > 0
+}
+
+// willConnect reports whether a device ends up connected once the operations
+// queued on it have run
+func (m Model) willConnect(device bluetooth.BluetoothDevice) bool {
+	connected := device.Connected
+	for _, op := range m.deviceOperations(device.MacAddress) {
+		switch op.Action {
+		case opConnect:
+			connected = true
+		case opDisconnect, opRemove:
+			connected = false
+		}
+	}
+	return connected
+}
+
+// trackOperation records the latest state of an operation, forgetting it
+// once it has finished. Changes older than the state already known, such as
+// the queued message of an operation that started at once, are ignored.
+func (m *Model) trackOperation(op bluetooth.Operation) {
+	if m.Operations == nil {
+		m.Operations = make(map[int]bluetooth.Operation)
+	}
+	if known, ok := m.Operations[op.ID]; ok && op.State < known.State {
+		return
+	}
+	if op.State.Finished() {
+		delete(m.Operations, op.ID)
+		return
+	}
+	m.Operations[op.ID] = op
+}
+
+// startOperation queues an operation on a device and returns it with the
+// command listening for its progress. The first operation also starts the
+// periodic redraws that show progress.
+func (m *Model) startOperation(device bluetooth.BluetoothDevice, action string) (bluetooth.Operation, tea.Cmd) {
+	first := len(m.Operations) == 0
+	op := m.Ops.Submit(action, device)
+	m.trackOperation(op)
+	if m.Requested == nil {
+		m.Requested = make(map[int]bool)
+	}
+	m.Requested[op.ID] = true
+
+	cmd := m.Ops.Listen()
+	if first {
+		return op, tea.Batch(cmd, uiUpdateCmd())
+	}
+	return op, cmd
+}
+
+// startText is the status line shown when an operation is started on one
+// device, saying what it waits for when it cannot run yet
+func (m Model) startText(op bluetooth.Operation) string {
+	name := op.Device.Name
+	if op.State == bluetooth.OpQueued {
+		ahead := m.deviceOperations(op.Device.MacAddress)
+		if len(ahead) > 1 && ahead[0].ID != op.ID {
+			return "Queued " + op.Action + " of " + name + " after " + ahead[len(ahead)-2].Action
+		}
+		return "Queued " + op.Action + " of " + name + " until another operation finishes"
+	}
+	switch op.Action {
+	case opConnect:
+		if op.Attempt > 1 {
+			return fmt.Sprintf("Connecting to %s... attempt %d/%d", name, op.Attempt, op.Attempts)
+		}
+		return "Connecting to " + name + "..."
+	case opDisconnect:
+		return "Disconnecting from " + name + "..."
+	default:
+		return operationLabels[op.Action].running + " " + name + "..."
+	}
+}
+
+// operationStatuses returns the status of every device with an operation
+// queued or running, and of devices already finished in the running batch
+func (m Model) operationStatuses() map[string]string {
+	statuses := make(map[string]string, len(m.Operations))
+	if m.Batch != nil {
+		for mac, result := range m.Batch.Results {
+			switch {
+			case result.Success:
+				statuses[mac] = statusDone
+			case result.Cancelled:
+				statuses[mac] = statusCancelled
+			default:
+				statuses[mac] = statusFailed
+			}
+		}
+	}
+	for _, op := range m.Operations {
+		if op.State == bluetooth.OpQueued {
+			statuses[op.Device.MacAddress] = statusQueued
+		}
+	}
+	// A running operation shows over those queued behind it
+	for _, op := range m.Operations {
+		if op.State == bluetooth.OpRunning {
+			statuses[op.Device.MacAddress] = operationLabels[op.Action].status
+		}
+	}
+	return statuses
+}
+
+// updateOperation records a change in an operation and keeps listening for
+// the next one. Operations started from this view report their outcome;
+// scene steps report through the scene's progress instead.
+func (m Model) updateOperation(msg bluetooth.OperationMsg) (tea.Model, tea.Cmd) {
+	op := msg.Op
+	m.trackOperation(op)
+	m.logOperation(op)
+	if m.ViewingQueue {
+		m.QueueList.SetItems(m.queueItems())
+	}
+	if !op.State.Finished() || !m.Requested[op.ID] {
+		if m.Requested[op.ID] && op.Attempt > 1 && (m.Batch == nil || !slices.Contains(m.Batch.Ops, op.ID)) {
+			m.StatusMessage = m.startText(op)
+		}
+		m.refreshDevices()
+		return m, m.Ops.Listen()
+	}
+	delete(m.Requested, op.ID)
+	next, cmd := m.finishOperation(op)
+	return next, tea.Batch(cmd, m.Ops.Listen())
+}
+
+// finishOperation records the outcome of an operation and reports it, or the
+// batch summary once the last device of a batch is done
+func (m Model) finishOperation(op bluetooth.Operation) (tea.Model, tea.Cmd) {
+	device, action := op.Device, op.Action
+	success := op.State == bluetooth.OpSucceeded
+	cancelled := op.State == bluetooth.OpCancelled
+	switch {
+	case !cancelled:
+		m.recordResult(device, action, success, op.Output)
+		if !success {
+			result := m.LastResult[device.MacAddress]
+			result.Details = op.Details()
+			m.LastResult[device.MacAddress] = result
+		}
+	case !op.Started.IsZero():
+		m.recordCancelled(device, action)
+	}
+	if action == opRemove && success {
+		m.Marked = slices.DeleteFunc(slices.Clone(m.Marked), func(mac string) bool { return mac == device.MacAddress })
+	}
+
+	// Results go to a toast, leaving the status line to what is still going on
+	var toast tea.Cmd
+	if b := m.Batch; b != nil && slices.Contains(b.Ops, op.ID) {
+		b.Results[device.MacAddress] = m.LastResult[device.MacAddress]
+		if cancelled {
+			b.Results[device.MacAddress] = operationResult{Action: action, Cancelled: true}
+		}
+		if len(b.Results) == len(b.Devices) {
+			m.Batch = nil
+			toast = m.toast(b.severity(), b.summary())
+		}
+	} else if cancelled {
+		toast = m.toast(ui.SeverityWarning, "Cancelled "+action+" of "+displayName(device))
+	} else {
+		severity := ui.SeveritySuccess
+		if !success {
+			severity = ui.SeverityError
+		}
+		toast = m.toast(severity, resultMessage(device, action, success, op.Output))
+		if !success && !m.modalOpen() && !m.List.SettingFilter() {
+			// Show what went wrong in full, unless the user is busy elsewhere
+			details := m.LastResult[device.MacAddress].Details
+			m.ViewingError = &details
+		}
+	}
+	m.settleStatus()
+	m.refreshDevices()
+	if cancelled {
+		return m, toast
+	}
+	// Refresh device list to show updated connection status
+	return m, tea.Batch(toast, bluetooth.FetchDevicesCmd())
+}
+
+// cancelOperations calls off what is queued or running on the selected
+// device, or else the rest of the running batch
+func (m Model) cancelOperations() (tea.Model, tea.Cmd) {
+	var ops []bluetooth.Operation
+	if device, ok := m.selectedDevice(); ok {
+		ops = m.deviceOperations(device.MacAddress)
+	}
+	if len(ops) == 0 && m.Batch != nil {
+		for _, id := range m.Batch.Ops {
+			if op, ok := m.Operations[id]; ok {
+				ops = append(ops, op)
+			}
+		}
+	}
+	if len(ops) == 0 {
+		m.StatusMessage = "Nothing to cancel"
+		return m, nil
+	}
+
+	var cancelled []bluetooth.Operation
+	for _, op := range ops {
+		if m.Ops.Cancel(op.ID) {
+			cancelled = append(cancelled, op)
+		}
+	}
+	switch len(cancelled) {
+	case 0:
+		m.StatusMessage = "Cannot cancel " + ops[0].Action + " of " + ops[0].Device.Name + " once it has started"
+		return m, nil
+	case 1:
+		m.StatusMessage = "Cancelling " + cancelled[0].Action + " of " + cancelled[0].Device.Name + "..."
+	default:
+		m.StatusMessage = fmt.Sprintf("Cancelling %d operations...", len(cancelled))
+	}
+	// Each cancellation arrives as an operation message like any other change
+	return m, m.Ops.Listen()
+}
+
+// resultMessage describes the outcome of an operation on one device
+func resultMessage(device bluetooth.BluetoothDevice, action string, success bool, output string) string {
+	// Advice on a known failure says more than bluetoothctl's wording, which
+	// the detail pane still shows
+	if hint := bluetooth.Classify(output).Hint(); hint != "" {
+		output = hint
+	}
+	switch {
+	case action == opConnect && success:
+		return "Successfully connected to " + device.Name
+	case action == opDisconnect && success:
+		return "Successfully disconnected from " + device.Name
+	case action == opConnect:
+		return "Failed to connect to " + device.Name + ": " + output
+	case action == opDisconnect:
+		return "Failed to disconnect from " + device.Name + ": " + output
+	case success:
+		return operationLabels[action].done + " " + device.Name
+	default:
+		return "Failed to " + action + " " + device.Name + ": " + output
+	}
+}
+
+// startBatch runs an operation on every marked device. Devices already
+// connected or disconnected as asked, counting what is queued on them, are
+// skipped; busy devices get the operation queued behind their own.
+func (m Model) startBatch(action string) (tea.Model, tea.Cmd) {
+	if m.Batch != nil {
+		m.StatusMessage = "Wait for the running batch to finish"
+		return m, nil
+	}
+
+	b := &batch{Action: action, Results: make(map[string]operationResult)}
+	known := m.knownDevices()
+	for _, mac := range m.Marked {
+		i := slices.IndexFunc(known, func(d bluetooth.BluetoothDevice) bool { return d.MacAddress == mac })
+		if i < 0 {
+			continue
+		}
+		device := known[i]
+		if (action == opConnect && m.willConnect(device)) || (action == opDisconnect && !m.willConnect(device)) {
+			b.Skipped++
+			continue
+		}
+		b.Devices = append(b.Devices, device)
+	}
+	if len(b.Devices) == 0 {
+		m.StatusMessage = "Nothing to " + action + ": the marked devices are already " + strings.ToLower(operationLabels[action].done)
+		return m, nil
+	}
+
+	cmds := make([]tea.Cmd, len(b.Devices))
+	for i, device := range b.Devices {
+		var op bluetooth.Operation
+		op, cmds[i] = m.startOperation(device, action)
+		b.Ops = append(b.Ops, op.ID)
+	}
+	m.Batch = b
+	m.Marked = nil
+	m.StatusMessage = b.progress()
+	m.refreshDevices()
+	return m, tea.Batch(cmds...)
+}
+
+// progress describes how far a batch has got
+func (b *batch) progress() string {
+	return fmt.Sprintf("%s %s... %d/%d done",
+		operationLabels[b.Action].running, devicesText(len(b.Devices)), len(b.Results), len(b.Devices))
+}
+
+// summary describes the outcome of a finished batch, naming the devices it
+// failed on
+func (b *batch) summary() string {
+	var failures []string
+	cancelled := 0
+	for _, device := range b.Devices {
+		switch result := b.Results[device.MacAddress]; {
+		case result.Cancelled:
+			cancelled++
+		case !result.Success:
+			failures = append(failures, displayName(device)+": "+result.Output)
+		}
+	}
+
+	summary := fmt.Sprintf("%s %d of %s", operationLabels[b.Action].done,
+		len(b.Devices)-len(failures)-cancelled, devicesText(len(b.Devices)))
+	if b.Skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", b.Skipped)
+	}
+	if cancelled > 0 {
+		summary += fmt.Sprintf(", %d cancelled", cancelled)
+	}
+	if len(failures) > 0 {
+		summary += " (failed: " + strings.Join(failures, "; ") + ")"
+	}
+	return summary
+}
+
+// devicesText returns "1 device" or "n devices"
+func devicesText(n int) string {
+	if n == 1 {
+		return "1 device"
+	}
+	return fmt.Sprintf("%d devices", n)
+}
+
+// confirmRemove asks before unpairing the marked devices, or else the
+// selected one
+func (m Model) confirmRemove() (tea.Model, tea.Cmd) {
+	var devices []bluetooth.BluetoothDevice
+	if len(m.Marked) > 0 {
+		for _, device := range m.knownDevices() {
+			if m.isMarked(device.MacAddress) {
+				devices = append(devices, device)
+			}
+		}
+	} else if device, ok := m.selectedDevice(); ok {
+		devices = append(devices, device)
+	}
+	if len(devices) > 0 {
+		m.Removing = devices
+	}
+	return m, nil
+}
+
+// removePromptText is the status line shown while confirming a removal
+func (m Model) removePromptText() string {
+	what := devicesText(len(m.Removing))
+	if len(m.Removing) == 1 {
+		what = displayName(m.Removing[0])
+	}
+	return "Remove " + what + " and forget the pairing? y confirms, any other key cancels"
+}
+
+// updateRemove handles the answer to the removal prompt
+func (m Model) updateRemove(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	devices := m.Removing
+	m.Removing = nil
+	if msg.String() != "y" {
+		m.StatusMessage = "Nothing removed"
+		return m, nil
+	}
+	if len(devices) == 1 && len(m.Marked) == 0 {
+		op, cmd := m.startOperation(devices[0], opRemove)
+		m.StatusMessage = m.startText(op)
+		m.refreshDevices()
+		return m, cmd
+	}
+	return m.startBatch(opRemove)
+}
+
+// hideMarked hides the marked discovered devices by address. Paired devices
+// are always shown, so they are left marked.
+func (m Model) hideMarked() (tea.Model, tea.Cmd) {
+	store := state.Get()
+	var kept []string
+	hidden := 0
+	for _, device := range m.knownDevices() {
+		if !m.isMarked(device.MacAddress) {
+			continue
+		}
+		if device.Paired {
+			kept = append(kept, device.MacAddress)
+			continue
+		}
+		rule, err := state.NewIgnoreRule(state.IgnoreMAC, device.MacAddress)
+		if err != nil {
+			continue
+		}
+		store.AddIgnore(rule)
+		hidden++
+	}
+	m.Marked = kept
+
+	m.StatusMessage = "Hiding " + devicesText(hidden) + " by address"
+	if len(kept) > 0 {
+		m.StatusMessage += fmt.Sprintf(" (%d paired and always shown)", len(kept))
+	}
+	m.refreshDevices()
+	if hidden == 0 {
+		return m, nil
+	}
+	return m, saveStateCmd()
+}
+
+// uiUpdateCmd returns the next periodic redraw during operations. Accessible
+// mode skips these redraws so screen readers are not flooded; status changes
+// are announced as plain lines instead.
+func uiUpdateCmd() tea.Cmd {
+	if ui.Accessible() {
+		return nil
+	}
+	return bluetooth.UIUpdateCmd()
+}
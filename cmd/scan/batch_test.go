@@ -0,0 +1,182 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/state"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Devices of the view fixture
+var (
+	bose     = bluetooth.BluetoothDevice{MacAddress: "4C:87:5D:28:86:DD", Name: "Bose NC 700 Headphones", Paired: true}
+	keychron = bluetooth.BluetoothDevice{MacAddress: "DC:2C:26:09:D0:0C", Name: "Keychron K4", Paired: true, Connected: true}
+	tv       = bluetooth.BluetoothDevice{MacAddress: "F0:99:B6:12:34:56", Name: "Living Room TV"}
+)
+
+// rowStatus returns the status column of a device in the table
+func rowStatus(m Model, mac string) string {
+	for _, row := range m.deviceRows() {
+		if row.Device.MacAddress == mac {
+			return row.Status
+		}
+	}
+	return ""
+}
+
+// actionOn returns the action of the first operation queued or running on a
+// device
+func actionOn(m Model, mac string) string {
+	if ops := m.deviceOperations(mac); len(ops) > 0 {
+		return ops[0].Action
+	}
+	return ""
+}
+
+// finish reports the first operation on a device as done
+func finish(t *testing.T, m Model, device bluetooth.BluetoothDevice, success bool, output string) Model {
+	t.Helper()
+	ops := m.deviceOperations(device.MacAddress)
+	if len(ops) == 0 {
+		t.Fatalf("Expected an operation on %s", device.Name)
+	}
+	op := ops[0]
+	op.State, op.Output = bluetooth.OpSucceeded, output
+	if !success {
+		op.State = bluetooth.OpFailed
+	}
+	m, _ = updateModel(m, bluetooth.OperationMsg{Op: op})
+	return m
+}
+
+func TestBatchConnect(t *testing.T) {
+	useState(t)
+	model := mark(t, viewFixture(), "Living Room TV")
+	model = mark(t, model, "Keychron K4")
+	model = mark(t, model, "Bose NC 700 Headphones")
+
+	model, cmd := updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if cmd == nil || len(model.Marked) != 0 {
+		t.Fatal("Expected the batch to start and take the marks")
+	}
+	if actionOn(model, tv.MacAddress) != opConnect || actionOn(model, bose.MacAddress) != opConnect || model.busy(keychron) {
+		t.Errorf("Expected both disconnected devices to connect at once, got %v", model.Operations)
+	}
+	if model.StatusMessage != "Connecting 2 devices... 0/2 done" {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+
+	model = finish(t, model, bose, true, "")
+	if model.StatusMessage != "Connecting 2 devices... 1/2 done" {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+	if rowStatus(model, bose.MacAddress) != statusDone || rowStatus(model, tv.MacAddress) != statusConnecting {
+		t.Errorf("Expected per-device statuses, got %q and %q", rowStatus(model, bose.MacAddress), rowStatus(model, tv.MacAddress))
+	}
+
+	model = finish(t, model, tv, false, "Failed to connect: org.bluez.Error.Failed")
+	expected := "Connected 1 of 2 devices, 1 skipped (failed: Living Room TV: Failed to connect: org.bluez.Error.Failed)"
+	if lastToast(model) != expected || model.Batch != nil {
+		t.Errorf("Expected summary %q, got %q", expected, lastToast(model))
+	}
+	if rowStatus(model, tv.MacAddress) != statusDiscovered {
+		t.Errorf("Expected statuses to return to normal, got %q", rowStatus(model, tv.MacAddress))
+	}
+}
+
+func TestOperationsRunPerDevice(t *testing.T) {
+	model := selectDevice(t, viewFixture(), "Bose NC 700 Headphones")
+	model = keys(model, "c")
+	model = selectDevice(t, model, "Keychron K4")
+	model = keys(model, "d")
+
+	if actionOn(model, bose.MacAddress) != opConnect || actionOn(model, keychron.MacAddress) != opDisconnect {
+		t.Fatalf("Expected a connect and a disconnect at once, got %v", model.Operations)
+	}
+
+	// A second connect on a device already connecting is ignored
+	model = selectDevice(t, model, "Bose NC 700 Headphones")
+	if _, cmd := updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")}); cmd != nil {
+		t.Error("Expected no second connect on a connecting device")
+	}
+
+	model = finish(t, model, keychron, true, "")
+	if lastToast(model) != "Successfully disconnected from Keychron K4" || !model.busy(bose) {
+		t.Errorf("Expected only the keyboard to finish, got %q and %v", lastToast(model), model.Operations)
+	}
+	// The status line goes back to the connect still running
+	if model.StatusMessage != "Connecting to Bose NC 700 Headphones..." {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+}
+
+func TestRemoveAsksFirst(t *testing.T) {
+	model := selectDevice(t, viewFixture(), "Bose NC 700 Headphones")
+
+	model = keys(model, "X")
+	if !strings.Contains(model.View(), "Remove Bose NC 700 Headphones and forget the pairing?") {
+		t.Fatal("Expected a confirmation prompt")
+	}
+	model = keys(model, "n")
+	if model.Removing != nil || model.busy(bose) || model.StatusMessage != "Nothing removed" {
+		t.Error("Expected any other key to cancel")
+	}
+
+	model = keys(model, "Xy")
+	if actionOn(model, bose.MacAddress) != opRemove {
+		t.Fatal("Expected the removal to start")
+	}
+	model = finish(t, model, bose, true, "Device has been removed")
+	if lastToast(model) != "Removed Bose NC 700 Headphones" {
+		t.Errorf("Unexpected result %q", lastToast(model))
+	}
+}
+
+func TestBatchTrust(t *testing.T) {
+	useState(t)
+	model := mark(t, viewFixture(), "Bose NC 700 Headphones")
+	model = mark(t, model, "Keychron K4")
+
+	model = keys(model, "T")
+	if actionOn(model, bose.MacAddress) != opTrust || actionOn(model, keychron.MacAddress) != opTrust {
+		t.Fatalf("Expected both devices to be trusted, got %v", model.Operations)
+	}
+	model = finish(t, model, bose, true, "")
+	model = finish(t, model, keychron, true, "")
+	if lastToast(model) != "Trusted 2 of 2 devices" {
+		t.Errorf("Unexpected summary %q", lastToast(model))
+	}
+}
+
+func TestBatchHide(t *testing.T) {
+	store := useState(t)
+	model := mark(t, viewFixture(), "Living Room TV")
+	model = mark(t, model, "Bose NC 700 Headphones")
+
+	model = keys(model, "i")
+	if rules := store.IgnoreRules(); len(rules) != 1 || rules[0] != (state.IgnoreRule{Kind: state.IgnoreMAC, Value: tv.MacAddress}) {
+		t.Errorf("Expected the TV hidden by address, got %+v", rules)
+	}
+	if model.StatusMessage != "Hiding 1 device by address (1 paired and always shown)" {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+	if len(model.Marked) != 1 || model.Marked[0] != bose.MacAddress {
+		t.Errorf("Expected the paired device to stay marked, got %v", model.Marked)
+	}
+}
+
+func TestFailureHint(t *testing.T) {
+	model := blockingOps(t, viewFixture(), 2)
+	model = keys(selectDevice(t, model, "Bose NC 700 Headphones"), "c")
+	model = finish(t, model, bose, false, "Attempting to connect to 4C:87:5D:28:86:DD\nFailed to connect: org.bluez.Error.NotReady Resource Not Ready")
+
+	expected := "Failed to connect to Bose NC 700 Headphones: adapter is off — power it on with bluetoothctl power on"
+	if lastToast(model) != expected {
+		t.Errorf("Expected the hint instead of the raw output, got %q", lastToast(model))
+	}
+	if result := model.LastResult[bose.MacAddress]; !strings.Contains(resultLine(result, result.At), "org.bluez.Error.NotReady") {
+		t.Error("Expected the detail pane to keep the output")
+	}
+}
@@ -0,0 +1,140 @@
+package scan
+
+import (
+	"btui/internal/battery"
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/hooks"
+	"btui/internal/notify"
+	"btui/internal/ui"
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// lowBattery reports whether a charge is low enough to be warned about
+func lowBattery(percent int) bool {
+	return battery.Low(percent, config.Get().Battery.Thresholds)
+}
+
+// batteryParts returns the description part showing a device's charge, if
+// it reports one
+func batteryParts(d bluetooth.BluetoothDevice) []string {
+	if !d.HasBattery {
+		return nil
+	}
+	style := ui.RSSIStyle
+	if lowBattery(d.Battery) {
+		style = ui.SeverityWarning.Style()
+	}
+	return []string{style.Render(fmt.Sprintf("Battery: %d%%", d.Battery))}
+}
+
+// chargeRank orders devices emptiest battery first, with those that report
+// no charge last
+func chargeRank(d bluetooth.BluetoothDevice) int {
+	if !d.HasBattery {
+		return 101
+	}
+	return d.Battery
+}
+
+// applyBatteries copies the known charges onto the listed devices. Devices
+// that are paired but no longer connected lose theirs, as BlueZ only reads
+// the battery of connected devices.
+func (m *Model) applyBatteries() {
+	for i, device := range m.PairedDevices {
+		if !device.Connected {
+			delete(m.Batteries, device.MacAddress)
+		}
+		percent, known := m.Batteries[device.MacAddress]
+		m.PairedDevices[i].Battery, m.PairedDevices[i].HasBattery = percent, known
+	}
+	for i, device := range m.DiscoveredDevices {
+		percent, known := m.Batteries[device.MacAddress]
+		m.DiscoveredDevices[i].Battery, m.DiscoveredDevices[i].HasBattery = percent, known
+	}
+}
+
+// updateBatteries records battery levels, in percent by MAC address, runs
+// the battery hooks of each that changed and warns about each that ran low
+func (m *Model) updateBatteries(levels map[string]int) tea.Cmd {
+	if len(levels) == 0 {
+		return nil
+	}
+	if m.Batteries == nil {
+		m.Batteries = make(map[string]int)
+	}
+
+	macs := make([]string, 0, len(levels))
+	changed := make(map[string]bool, len(levels))
+	for mac, percent := range levels {
+		previous, known := m.Batteries[mac]
+		changed[mac] = !known || previous != percent
+		m.Batteries[mac] = percent
+		macs = append(macs, mac)
+	}
+	sort.Strings(macs)
+	m.applyBatteries()
+
+	var cmds []tea.Cmd
+	for _, mac := range macs {
+		if changed[mac] {
+			event := hooks.Event{Kind: config.HookBattery, Device: m.hookDevice(mac), Battery: levels[mac]}
+			cmds = append(cmds, m.runHooks(event))
+		}
+		if m.Battery == nil {
+			continue
+		}
+		name := m.deviceName(mac)
+		if w, ok := m.Battery.Update(mac, name, levels[mac]); ok {
+			cmds = append(cmds, m.warnBattery(w))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// deviceName returns the name shown for a listed device, or its MAC address
+func (m Model) deviceName(mac string) string {
+	for _, device := range m.PairedDevices {
+		if device.MacAddress == mac {
+			if name := displayName(device); name != "" {
+				return name
+			}
+		}
+	}
+	for _, device := range m.DiscoveredDevices {
+		if device.MacAddress == mac {
+			if name := displayName(device.BluetoothDevice); name != "" {
+				return name
+			}
+		}
+	}
+	return mac
+}
+
+// warnBattery logs and toasts a low battery, and passes it on to the desktop
+// and the low_battery hooks
+func (m *Model) warnBattery(w battery.Warning) tea.Cmd {
+	message := fmt.Sprintf("%s battery low: %d%% left", w.Name, w.Percent)
+	m.logActivity(ui.SeverityWarning, w.MAC, message)
+	event := hooks.Event{Kind: config.HookLowBattery, Device: m.hookDevice(w.MAC), Battery: w.Percent, Threshold: w.Threshold}
+	return tea.Batch(
+		m.toast(ui.SeverityWarning, message),
+		m.notifyDesktop(notify.Event{Kind: notify.EventLowBattery, MAC: w.MAC, Name: w.Name, Battery: w.Percent}),
+		m.runHooks(event),
+	)
+}
+
+// readBatteries asks each connected device for its properties, which carry
+// its charge
+func (m Model) readBatteries() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, device := range m.PairedDevices {
+		if device.Connected {
+			cmds = append(cmds, bluetooth.DeviceInfoCmd(device.MacAddress))
+		}
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,91 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/ui"
+	"strings"
+	"testing"
+)
+
+func TestBatteryShownForConnectedDevice(t *testing.T) {
+	model := viewFixture()
+	model, _ = updateModel(model, bluetooth.DeviceInfoMsg{Info: bluetooth.DeviceInfo{
+		MacAddress: keychron.MacAddress, Name: "Keychron K4", Connected: true, Battery: 64, HasBattery: true,
+	}})
+
+	if got := model.Batteries[keychron.MacAddress]; got != 64 {
+		t.Fatalf("Expected the keyboard at 64%%, got %d", got)
+	}
+	if !strings.Contains(model.View(), "Battery: 64%") {
+		t.Error("Expected the charge in the device list")
+	}
+	if detail := model.detailView(); !strings.Contains(detail, "64%") {
+		t.Errorf("Expected the charge in the detail pane, got %q", detail)
+	}
+	if len(model.Toasts.Items) != 0 {
+		t.Errorf("Expected no warning for a charged device, got %v", model.Toasts.Items)
+	}
+
+	// Once the keyboard disconnects its charge is no longer known
+	model = relist(model)
+	if _, ok := model.Batteries[keychron.MacAddress]; ok {
+		t.Error("Expected the charge forgotten after disconnecting")
+	}
+}
+
+func TestLowBatteryWarning(t *testing.T) {
+	model := viewFixture()
+
+	// Battery changes reported during discovery raise a warning once
+	update := bluetooth.DiscoveryUpdateMsg{Devices: model.DiscoveredDevices, Batteries: map[string]int{keychron.MacAddress: 18}}
+	model, _ = updateModel(model, update)
+	if lastToast(model) != "Keychron K4 battery low: 18% left" || model.Toasts.Items[0].Severity != ui.SeverityWarning {
+		t.Fatalf("Unexpected toasts %v", model.Toasts.Items)
+	}
+	if last := model.Activity[len(model.Activity)-1]; last.Device != keychron.MacAddress || last.Severity != ui.SeverityWarning {
+		t.Errorf("Expected the warning logged against the keyboard, got %+v", last)
+	}
+
+	update.Batteries = map[string]int{keychron.MacAddress: 17}
+	model, _ = updateModel(model, update)
+	if len(model.Toasts.Items) != 1 {
+		t.Errorf("Expected no second warning above the next threshold, got %v", model.Toasts.Items)
+	}
+	for _, row := range model.deviceRows() {
+		if row.Device.MacAddress == keychron.MacAddress && row.Device.Battery != 17 {
+			t.Errorf("Expected the table row to follow the charge, got %d", row.Device.Battery)
+		}
+	}
+}
+
+func TestEmptyBattery(t *testing.T) {
+	model := viewFixture()
+	model, _ = updateModel(model, bluetooth.DeviceInfoMsg{Info: bluetooth.DeviceInfo{
+		MacAddress: keychron.MacAddress, Name: "Keychron K4", Connected: true, Battery: 0, HasBattery: true,
+	}})
+
+	if got, ok := model.Batteries[keychron.MacAddress]; !ok || got != 0 {
+		t.Fatalf("Expected the keyboard at 0%%, got %d (%v)", got, ok)
+	}
+	if !strings.Contains(model.View(), "Battery: 0%") {
+		t.Error("Expected the empty battery in the device list")
+	}
+	if lastToast(model) != "Keychron K4 battery low: 0% left" {
+		t.Errorf("Expected a low battery warning, got %v", model.Toasts.Items)
+	}
+
+	// An empty battery sorts before a charged one, and both before none
+	empty := bluetooth.BluetoothDevice{Battery: 0, HasBattery: true}
+	charged := bluetooth.BluetoothDevice{Battery: 50, HasBattery: true}
+	if !(chargeRank(empty) < chargeRank(charged) && chargeRank(charged) < chargeRank(bluetooth.BluetoothDevice{})) {
+		t.Error("Expected empty, then charged, then unknown")
+	}
+}
+
+func TestBatteryTickReadsConnectedDevices(t *testing.T) {
+	model := viewFixture()
+	_, cmd := updateModel(model, bluetooth.BatteryTickMsg{})
+	if cmd == nil {
+		t.Fatal("Expected the connected keyboard to be read and the next tick scheduled")
+	}
+}
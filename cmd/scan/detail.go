@@ -0,0 +1,184 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/state"
+	"btui/internal/ui"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// rssiHistorySize is the number of signal samples kept per device
+const rssiHistorySize = 30
+
+// rssiSample is one signal strength reading from discovery
+type rssiSample struct {
+	RSSI int
+	At   time.Time
+}
+
+// operationResult records the outcome of the last connect or disconnect
+type operationResult struct {
+	Action    string
+	Success   bool
+	Cancelled bool
+	Output    string
+	At        time.Time
+	// Details explains a failure in the error details modal
+	Details ui.ErrorDetails
+}
+
+// layout returns the pane layout for the current window size
+func (m Model) layout() ui.Layout {
+	cfg := config.Get()
+	width := m.Width
+	height := m.Height
+	if width == 0 {
+		width = cfg.Window.Width
+	}
+	if height == 0 {
+		height = cfg.Window.Height
+	}
+	// Rows below the main pane are kept for the toasts and the status line
+	return ui.NewLayout(width, height, cfg.Window.SplitWidth, m.Toasts.Height()+1)
+}
+
+// recordRSSI appends new signal readings to each device's history. Readings
+// are keyed by their discovery timestamp so repeated ticks don't duplicate them.
+func (m *Model) recordRSSI(devices []bluetooth.DiscoveredDevice) {
+	if m.RSSIHistory == nil {
+		m.RSSIHistory = make(map[string][]rssiSample)
+	}
+	for _, device := range devices {
+		if device.RSSI == 0 {
+			continue
+		}
+		history := m.RSSIHistory[device.MacAddress]
+		if n := len(history); n > 0 && history[n-1].At.Equal(device.Timestamp) {
+			continue
+		}
+		history = append(history, rssiSample{RSSI: device.RSSI, At: device.Timestamp})
+		if len(history) > rssiHistorySize {
+			history = history[len(history)-rssiHistorySize:]
+		}
+		m.RSSIHistory[device.MacAddress] = history
+	}
+}
+
+// recordResult stores the outcome of an operation on a device
+func (m *Model) recordResult(device bluetooth.BluetoothDevice, action string, success bool, output string) {
+	if m.LastResult == nil {
+		m.LastResult = make(map[string]operationResult)
+	}
+	m.LastResult[device.MacAddress] = operationResult{
+		Action:  action,
+		Success: success,
+		Output:  strings.TrimSpace(output),
+		At:      time.Now(),
+	}
+}
+
+// recordCancelled records that an operation on a device was called off
+// after it had started
+func (m *Model) recordCancelled(device bluetooth.BluetoothDevice, action string) {
+	m.recordResult(device, action, false, "")
+	result := m.LastResult[device.MacAddress]
+	result.Cancelled = true
+	m.LastResult[device.MacAddress] = result
+}
+
+// detailView renders the properties of the selected device for the detail pane
+func (m Model) detailView() string {
+	device, ok := m.selectedDevice()
+	if !ok {
+		return ui.DetailLabelStyle.Render("No device selected")
+	}
+
+	name := displayName(device)
+	if name == "" {
+		name = "Unknown Device"
+	}
+	info := m.DeviceInfo[device.MacAddress]
+	meta := state.Get().Meta(device.MacAddress)
+	history := m.RSSIHistory[device.MacAddress]
+	now := time.Now()
+
+	var b strings.Builder
+	b.WriteString(ui.TitleStyle.Render(name) + "\n\n")
+
+	field := func(label, value string) {
+		b.WriteString(ui.DetailLabelStyle.Render(fmt.Sprintf("%-10s", label)) + " " + value + "\n")
+	}
+	field("Status", renderStatus(deviceStatus(device, m.operationStatuses())))
+	if meta.Nickname != "" {
+		field("Name", orDash(device.Name))
+	}
+	field("MAC", device.MacAddress)
+	if info.Alias != "" && info.Alias != device.Name {
+		field("Alias", info.Alias)
+	}
+	field("Type", orDash(info.Icon))
+	if percent, ok := m.Batteries[device.MacAddress]; ok {
+		field("Battery", ui.BatteryLevel(percent, lowBattery(percent)))
+	}
+	field("Paired", yesNo(device.Paired))
+	if info.MacAddress != "" {
+		field("Trusted", yesNo(info.Trusted))
+	}
+
+	if len(meta.Tags) > 0 {
+		field("Tags", strings.Join(meta.Tags, ", "))
+	}
+	if meta.Note != "" {
+		field("Note", meta.Note)
+	}
+
+	if len(history) > 0 {
+		latest := history[len(history)-1]
+		field("Signal", fmt.Sprintf("%d dBm %s", latest.RSSI, ui.SignalBars(latest.RSSI)))
+		samples := make([]int, len(history))
+		for i, sample := range history {
+			samples[i] = sample.RSSI
+		}
+		field("History", ui.Sparkline(samples))
+		field("Last seen", lastSeen(latest.At, now))
+	} else {
+		field("Signal", "-")
+	}
+
+	if result, ok := m.LastResult[device.MacAddress]; ok {
+		b.WriteString("\n" + ui.DetailLabelStyle.Render("Last operation") + "\n")
+		b.WriteString(resultLine(result, now))
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// resultLine summarises an operation result with how long ago it finished
+func resultLine(result operationResult, now time.Time) string {
+	ago := lastSeen(result.At, now)
+	if result.Success {
+		return ui.SuccessStyle().Render(ui.SuccessMarker()) + " " + result.Action + " " + ago
+	}
+	if result.Cancelled {
+		return result.Action + " cancelled " + ago
+	}
+	line := ui.ErrorStyle().Render(ui.FailureMarker()) + " " + result.Action + " failed " + ago
+	if result.Output != "" {
+		line += "\n" + result.Output
+	}
+	if hint := bluetooth.Classify(result.Output).Hint(); hint != "" {
+		line += "\n" + hint
+	}
+	return line
+}
+
+// yesNo formats a boolean property the way bluetoothctl does
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
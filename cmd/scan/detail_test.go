@@ -0,0 +1,97 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRecordRSSI(t *testing.T) {
+	model := NewModel()
+	start := time.Now()
+	device := func(rssi int, at time.Time) []bluetooth.DiscoveredDevice {
+		return []bluetooth.DiscoveredDevice{{
+			BluetoothDevice: bluetooth.BluetoothDevice{MacAddress: "AA:BB:CC:DD:EE:FF"},
+			RSSI:            rssi,
+			Timestamp:       at,
+		}}
+	}
+
+	model.recordRSSI(device(-60, start))
+	model.recordRSSI(device(-60, start)) // same reading on the next tick
+	model.recordRSSI(device(0, start.Add(time.Second)))
+	model.recordRSSI(device(-65, start.Add(2*time.Second)))
+
+	history := model.RSSIHistory["AA:BB:CC:DD:EE:FF"]
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 samples, got %d", len(history))
+	}
+	if history[0].RSSI != -60 || history[1].RSSI != -65 {
+		t.Errorf("Expected samples -60, -65, got %v", history)
+	}
+
+	for i := range rssiHistorySize + 5 {
+		model.recordRSSI(device(-70, start.Add(time.Duration(i+3)*time.Second)))
+	}
+	if got := len(model.RSSIHistory["AA:BB:CC:DD:EE:FF"]); got != rssiHistorySize {
+		t.Errorf("Expected history capped at %d, got %d", rssiHistorySize, got)
+	}
+}
+
+func TestRecordResultOnConnect(t *testing.T) {
+	model := keys(selectDevice(t, viewFixture(), "Bose NC 700 Headphones"), "c")
+	device := model.PairedDevices[0]
+
+	model = finish(t, model, device, false, "org.bluez.Error.Failed\n")
+
+	result, ok := model.LastResult[device.MacAddress]
+	if !ok {
+		t.Fatal("Expected a result for the device")
+	}
+	if result.Action != "connect" || result.Success || result.Output != "org.bluez.Error.Failed" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+func TestDetailPaneOnWideTerminal(t *testing.T) {
+	model := viewFixture()
+
+	if strings.Contains(model.View(), "Last operation") {
+		t.Error("Expected no detail pane on a narrow terminal")
+	}
+
+	model, _ = updateModel(model, tea.WindowSizeMsg{Width: 120, Height: 24})
+	device, _ := model.selectedDevice()
+	model = finish(t, keys(model, "d"), device, true, "")
+
+	view := model.View()
+	for _, expected := range []string{"MAC", device.MacAddress, "Last operation", "disconnect"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected wide view to contain %q", expected)
+		}
+	}
+	if model.List.Width() >= 116 {
+		t.Errorf("Expected the list to shrink for the detail pane, got width %d", model.List.Width())
+	}
+}
+
+func TestDetailViewSignal(t *testing.T) {
+	model := viewFixture()
+	model.recordRSSI([]bluetooth.DiscoveredDevice{{
+		BluetoothDevice: bluetooth.BluetoothDevice{MacAddress: "DC:2C:26:09:D0:0C"},
+		RSSI:            -58,
+		Timestamp:       time.Now(),
+	}})
+
+	// The connected keyboard sorts first and is selected
+	detail := model.detailView()
+	if !strings.Contains(detail, "-58 dBm") {
+		t.Errorf("Expected latest signal in detail view, got %q", detail)
+	}
+	if !strings.Contains(detail, "History") {
+		t.Errorf("Expected signal history in detail view, got %q", detail)
+	}
+}
@@ -0,0 +1,51 @@
+package scan
+
+import (
+	"btui/internal/ui"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// modalOpen reports whether a prompt, editor or secondary view has the
+// keyboard, so the device list should not react
+func (m Model) modalOpen() bool {
+	return m.Reviewing || m.Hiding != nil || m.Editing != nil || m.Renaming != nil ||
+		m.ChoosingScene || m.SavingScene != nil || m.Removing != nil || m.ViewingQueue ||
+		m.ViewingError != nil || m.ViewingLog
+}
+
+// openErrorDetails shows the error details modal
+func (m Model) openErrorDetails(details ui.ErrorDetails) (tea.Model, tea.Cmd) {
+	m.ViewingError = &details
+	return m, nil
+}
+
+// openSelectedFailure shows the details of the selected device's last
+// operation, if it failed
+func (m Model) openSelectedFailure() (tea.Model, tea.Cmd) {
+	device, ok := m.selectedDevice()
+	if !ok {
+		return m, nil
+	}
+	result, ok := m.LastResult[device.MacAddress]
+	if !ok || result.Success || result.Cancelled {
+		m.StatusMessage = "No failed operation on " + displayName(device)
+		return m, nil
+	}
+	return m.openErrorDetails(result.Details)
+}
+
+// updateErrorDetails handles keys in the error details modal
+func (m Model) updateErrorDetails(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, ui.ErrorDetailsKeys.Copy):
+		return m, ui.CopyCmd(m.ViewingError.Text())
+	case key.Matches(msg, ui.ErrorDetailsKeys.Close, m.Keys.Details):
+		m.ViewingError = nil
+	case key.Matches(msg, m.Keys.Quit):
+		m.ViewingError = nil
+		return m.update(msg)
+	}
+	return m, nil
+}
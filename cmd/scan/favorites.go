@@ -0,0 +1,71 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/state"
+	"btui/internal/ui"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// itemMAC returns the MAC address of the device behind a list item
+func itemMAC(item list.Item) string {
+	if deviceItem, ok := item.(ui.DeviceItem); ok {
+		if device, ok := deviceItem.Device().(bluetooth.BluetoothDevice); ok {
+			return device.MacAddress
+		}
+	}
+	return ""
+}
+
+// favoritesFirst moves favorite devices to the front, keeping the existing
+// order among favorites and among the rest
+func favoritesFirst(items []list.Item) {
+	store := state.Get()
+	favorites := make([]list.Item, 0, len(items))
+	others := make([]list.Item, 0, len(items))
+	for _, item := range items {
+		if store.IsFavorite(itemMAC(item)) {
+			favorites = append(favorites, item)
+		} else {
+			others = append(others, item)
+		}
+	}
+	copy(items, favorites)
+	copy(items[len(favorites):], others)
+}
+
+// onlyFavorites returns the items whose device is a favorite
+func onlyFavorites(items []list.Item) []list.Item {
+	store := state.Get()
+	favorites := make([]list.Item, 0, len(items))
+	for _, item := range items {
+		if store.IsFavorite(itemMAC(item)) {
+			favorites = append(favorites, item)
+		}
+	}
+	return favorites
+}
+
+// toggleFavorite stars or unstars a device and saves the change
+func (m Model) toggleFavorite(device bluetooth.BluetoothDevice) (tea.Model, tea.Cmd) {
+	name := device.Name
+	if name == "" {
+		name = device.MacAddress
+	}
+	if state.Get().ToggleFavorite(device.MacAddress) {
+		m.StatusMessage = "Added " + name + " to favorites"
+	} else {
+		m.StatusMessage = "Removed " + name + " from favorites"
+	}
+	m.refreshDevices()
+	return m, saveStateCmd()
+}
+
+// saveStateCmd writes the state file in the background
+func saveStateCmd() tea.Cmd {
+	return func() tea.Msg {
+		return StateSavedMsg{Err: state.Get().Save()}
+	}
+}
@@ -0,0 +1,123 @@
+package scan
+
+import (
+	"btui/internal/config"
+	"btui/internal/state"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// useState gives a test its own in-memory state store
+func useState(t *testing.T, favorites ...string) *state.Store {
+	t.Helper()
+	store := state.New("")
+	for _, mac := range favorites {
+		store.SetFavorite(mac, true)
+	}
+	state.Set(store)
+	t.Cleanup(func() { state.Set(state.New("")) })
+	return store
+}
+
+// favoritesPlacement switches where favorites are listed for one test
+func favoritesPlacement(t *testing.T, placement string) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.List.Favorites = placement
+	config.Set(cfg)
+	t.Cleanup(func() { config.Set(config.Default()) })
+}
+
+func TestFavoritesPinnedToTop(t *testing.T) {
+	useState(t, "F0:99:B6:12:34:56")
+	model := viewFixture()
+
+	names := visibleNames(model)
+	expected := "Living Room TV,Keychron K4,Bose NC 700 Headphones"
+	if got := strings.Join(names, ","); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	if !strings.Contains(model.View(), "★ Living Room TV") {
+		t.Error("Expected the favorite to be starred")
+	}
+}
+
+func TestFavoritesWithinTier(t *testing.T) {
+	favoritesPlacement(t, config.FavoritesTier)
+	useState(t, "F0:99:B6:12:34:56", "4C:87:5D:28:86:DD")
+	model := viewFixture()
+
+	// Favorites lead their own tier but the tiers keep their order
+	expected := "Keychron K4,Bose NC 700 Headphones,Living Room TV"
+	if got := strings.Join(visibleNames(model), ","); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestToggleFavoriteKey(t *testing.T) {
+	store := useState(t)
+	model := viewFixture()
+
+	// The connected keyboard is selected first
+	model, cmd := updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if !store.IsFavorite("DC:2C:26:09:D0:0C") {
+		t.Fatal("Expected the selected device to become a favorite")
+	}
+	if model.StatusMessage != "Added Keychron K4 to favorites" {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+	if cmd == nil {
+		t.Fatal("Expected a command to save the state")
+	}
+	if msg, ok := cmd().(StateSavedMsg); !ok || msg.Err != nil {
+		t.Errorf("Expected a successful save, got %#v", msg)
+	}
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if store.IsFavorite("DC:2C:26:09:D0:0C") {
+		t.Error("Expected a second press to remove the favorite")
+	}
+	if model.StatusMessage != "Removed Keychron K4 from favorites" {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+}
+
+func TestFavoritesOnly(t *testing.T) {
+	useState(t, "4C:87:5D:28:86:DD")
+	model := viewFixture()
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")})
+	if got := strings.Join(visibleNames(model), ","); got != "Bose NC 700 Headphones" {
+		t.Errorf("Expected only the favorite, got %q", got)
+	}
+	if !strings.Contains(model.List.Title, "Favorites") {
+		t.Errorf("Expected the title to mention favorites, got %q", model.List.Title)
+	}
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")})
+	if len(visibleNames(model)) != 3 {
+		t.Errorf("Expected every device again, got %v", visibleNames(model))
+	}
+}
+
+func TestFavoritesPinnedInTable(t *testing.T) {
+	useState(t, "F0:99:B6:12:34:56")
+	model := viewFixture()
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+
+	rows := model.Table.Rows()
+	if len(rows) != 3 || !strings.Contains(strings.Join(rows[0], " "), "★ Living Room TV") {
+		t.Errorf("Expected the starred TV in the first row, got %v", rows)
+	}
+}
+
+func TestStateSaveError(t *testing.T) {
+	model := viewFixture()
+	model, _ = updateModel(model, StateSavedMsg{Err: errors.New("permission denied")})
+	if !strings.Contains(model.StatusMessage, "Could not save state") {
+		t.Errorf("Expected a save error in the status, got %q", model.StatusMessage)
+	}
+}
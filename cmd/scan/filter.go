@@ -0,0 +1,60 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/query"
+	"btui/internal/state"
+	"btui/internal/ui"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// queryDevice returns the view of a device that filter queries match against
+func (m Model) queryDevice(d bluetooth.BluetoothDevice, rssi int) query.Device {
+	return bluetooth.QueryDevice(d, state.Get().Meta(d.MacAddress), m.DeviceInfo[d.MacAddress].Icon, rssi)
+}
+
+// queryDevices returns the query view of each list item, in list order
+func (m Model) queryDevices(items []list.Item) []query.Device {
+	rssi := make(map[string]int, len(m.DiscoveredDevices))
+	for _, device := range m.DiscoveredDevices {
+		rssi[device.MacAddress] = device.RSSI
+	}
+
+	devices := make([]query.Device, len(items))
+	for i, item := range items {
+		if deviceItem, ok := item.(ui.DeviceItem); ok {
+			if device, ok := deviceItem.Device().(bluetooth.BluetoothDevice); ok {
+				devices[i] = m.queryDevice(device, rssi[device.MacAddress])
+			}
+		}
+	}
+	return devices
+}
+
+// queryFilter returns a list filter that treats the filter text as a query.
+// The list passes one target per item in item order, so targets are matched
+// by index against the devices built from the same items.
+func queryFilter(devices []query.Device) list.FilterFunc {
+	return func(term string, targets []string) []list.Rank {
+		q, err := query.Parse(term)
+		if err != nil {
+			return nil
+		}
+		var ranks []list.Rank
+		for i := range targets {
+			if i < len(devices) && q.Match(devices[i]) {
+				ranks = append(ranks, list.Rank{Index: i})
+			}
+		}
+		return ranks
+	}
+}
+
+// activeQuery returns the query typed into the list filter, if any
+func (m Model) activeQuery() (query.Query, error) {
+	if m.List.FilterState() == list.Unfiltered {
+		return query.Query{}, nil
+	}
+	return query.Parse(m.List.FilterValue())
+}
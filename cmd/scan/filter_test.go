@@ -0,0 +1,103 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/query"
+	"btui/internal/ui"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// applyQuery sets the list filter text and lets the model react to it
+func applyQuery(m Model, text string) Model {
+	m.List.SetFilterText(text)
+	m, _ = updateModel(m, struct{}{})
+	return m
+}
+
+// visibleNames returns the names of the devices the list currently shows
+func visibleNames(m Model) []string {
+	var names []string
+	for _, item := range m.List.VisibleItems() {
+		device := item.(ui.DeviceItem).Device().(bluetooth.BluetoothDevice)
+		names = append(names, device.Name)
+	}
+	return names
+}
+
+func TestQueryFilter(t *testing.T) {
+	devices := []query.Device{
+		{Name: "Bose", Paired: true, RSSI: -50},
+		{Name: "TV", RSSI: -80},
+	}
+	filter := queryFilter(devices)
+
+	ranks := filter("rssi>-60", []string{"Bose", "TV"})
+	if len(ranks) != 1 || ranks[0].Index != 0 {
+		t.Errorf("Expected only the first device to match, got %v", ranks)
+	}
+	if ranks := filter("colour:red", []string{"Bose", "TV"}); ranks != nil {
+		t.Errorf("Expected an invalid query to match nothing, got %v", ranks)
+	}
+}
+
+func TestQueryFiltersList(t *testing.T) {
+	model := viewFixture()
+
+	model = applyQuery(model, "status:paired")
+	if got := strings.Join(visibleNames(model), ","); got != "Keychron K4,Bose NC 700 Headphones" {
+		t.Errorf("Expected paired devices, got %q", got)
+	}
+	if !strings.Contains(model.List.Title, "[status:paired]") {
+		t.Errorf("Expected the query in the title, got %q", model.List.Title)
+	}
+
+	model = applyQuery(model, "rssi>-70 mac:F0:99")
+	if got := strings.Join(visibleNames(model), ","); got != "Living Room TV" {
+		t.Errorf("Expected the TV, got %q", got)
+	}
+}
+
+func TestQuerySurvivesDeviceUpdates(t *testing.T) {
+	model := viewFixture()
+	model = applyQuery(model, "status:discovered")
+
+	model, _ = updateModel(model, bluetooth.DiscoveryUpdateMsg{
+		Devices: []bluetooth.DiscoveredDevice{
+			{BluetoothDevice: bluetooth.BluetoothDevice{MacAddress: "F0:99:B6:12:34:56", Name: "Living Room TV"}, RSSI: -60},
+			{BluetoothDevice: bluetooth.BluetoothDevice{MacAddress: "11:22:33:44:55:66", Name: "Phone"}, RSSI: -40},
+		},
+	})
+
+	if got := strings.Join(visibleNames(model), ","); got != "Living Room TV,Phone" {
+		t.Errorf("Expected newly discovered devices to be filtered too, got %q", got)
+	}
+}
+
+func TestInvalidQueryShown(t *testing.T) {
+	model := viewFixture()
+	model = applyQuery(model, "colour:red")
+
+	if model.QueryErr == nil {
+		t.Fatal("Expected a query error")
+	}
+	if !strings.Contains(model.View(), `Invalid query: column 1: unknown field "colour"`) {
+		t.Error("Expected the query error in the status line")
+	}
+}
+
+func TestQueryFiltersTable(t *testing.T) {
+	model := viewFixture()
+	model = applyQuery(model, "status:connected")
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+
+	if model.Layout != config.LayoutTable {
+		t.Fatal("Expected table layout")
+	}
+	if len(model.TableRows) != 1 || model.TableRows[0].Device.Name != "Keychron K4" {
+		t.Errorf("Expected only the connected keyboard in the table, got %d rows", len(model.TableRows))
+	}
+}
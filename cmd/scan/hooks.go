@@ -0,0 +1,124 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/hooks"
+	"btui/internal/query"
+	"btui/internal/ui"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// HookDoneMsg is sent when a hook command has finished
+type HookDoneMsg struct {
+	Result hooks.Result
+}
+
+// runHooks returns commands running each hook that matches an event
+func (m Model) runHooks(e hooks.Event) tea.Cmd {
+	runner := m.Hooks
+	var cmds []tea.Cmd
+	for _, h := range runner.Matching(e) {
+		cmds = append(cmds, func() tea.Msg {
+			return HookDoneMsg{Result: runner.Run(h, e)}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// hookDevice returns the query view of a listed device, by MAC address
+func (m Model) hookDevice(mac string) query.Device {
+	rssi := 0
+	for _, device := range m.DiscoveredDevices {
+		if device.MacAddress == mac {
+			rssi = device.RSSI
+		}
+	}
+	for _, device := range m.PairedDevices {
+		if device.MacAddress == mac {
+			return m.queryDevice(device, rssi)
+		}
+	}
+	for _, device := range m.DiscoveredDevices {
+		if device.MacAddress == mac {
+			return m.queryDevice(device.BluetoothDevice, rssi)
+		}
+	}
+	return query.Device{MAC: mac}
+}
+
+// connectionHooks runs the connect and disconnect hooks of paired devices
+// whose connection changed since the previous device list. Unlike toasts,
+// these also fire for changes btui made itself.
+func (m Model) connectionHooks(previous, current []bluetooth.BluetoothDevice) tea.Cmd {
+	connected := make(map[string]bool, len(previous))
+	for _, device := range previous {
+		connected[device.MacAddress] = device.Connected
+	}
+	var cmds []tea.Cmd
+	for _, device := range current {
+		was, known := connected[device.MacAddress]
+		if !known || was == device.Connected {
+			continue
+		}
+		kind := config.HookDisconnect
+		if device.Connected {
+			kind = config.HookConnect
+		}
+		cmds = append(cmds, m.runHooks(hooks.Event{Kind: kind, Device: m.hookDevice(device.MacAddress)}))
+	}
+	return tea.Batch(cmds...)
+}
+
+// discoveryHooks runs the discover hooks of devices that came into range
+// since the previous discovery update
+func (m Model) discoveryHooks(previous, current []bluetooth.DiscoveredDevice) tea.Cmd {
+	seen := make(map[string]bool, len(previous))
+	for _, device := range previous {
+		seen[device.MacAddress] = true
+	}
+	var cmds []tea.Cmd
+	for _, device := range current {
+		if !seen[device.MacAddress] {
+			event := hooks.Event{Kind: config.HookDiscover, Device: m.queryDevice(device.BluetoothDevice, device.RSSI)}
+			cmds = append(cmds, m.runHooks(event))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// hookDone logs what a hook printed, and toasts hooks that failed
+func (m Model) hookDone(msg HookDoneMsg) (tea.Model, tea.Cmd) {
+	r := msg.Result
+	output := hookOutput(r.Output)
+
+	if r.Err == nil {
+		message := fmt.Sprintf("Ran %s hook for %s", r.Event.Kind, r.Event.Name())
+		if output != "" {
+			message += ": " + output
+		}
+		m.logActivity(ui.SeverityInfo, r.Event.Device.MAC, message)
+		return m, nil
+	}
+
+	message := fmt.Sprintf("Hook for %s failed: %v", r.Event.Name(), r.Err)
+	if output != "" {
+		message += " (" + output + ")"
+	}
+	m.logActivity(ui.SeverityError, r.Event.Device.MAC, message)
+	return m, m.toast(ui.SeverityError, message)
+}
+
+// hookOutput folds what a hook printed onto one line for the activity log
+func hookOutput(output string) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "; ")
+}
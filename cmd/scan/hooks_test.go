@@ -0,0 +1,88 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/hooks"
+	"btui/internal/ui"
+	"errors"
+	"testing"
+)
+
+// withHooks gives the model a runner for the given hooks
+func withHooks(m Model, run ...config.Hook) Model {
+	cfg := config.Default()
+	cfg.Hooks.Run = run
+	m.Hooks = hooks.NewRunner(cfg)
+	return m
+}
+
+func TestConnectionHooks(t *testing.T) {
+	model := withHooks(viewFixture(),
+		config.Hook{Event: config.HookConnect, Device: "name:bose", Command: `echo "$BTUI_EVENT $BTUI_NAME"`},
+		config.Hook{Event: config.HookDisconnect, Device: "name:bose", Command: "echo paused"},
+	)
+
+	previous := model.PairedDevices
+	current := []bluetooth.BluetoothDevice{bose, keychron}
+	current[0].Connected = true
+	cmd := model.connectionHooks(previous, current)
+	if cmd == nil {
+		t.Fatal("Expected the connect hook to run")
+	}
+	msg, ok := cmd().(HookDoneMsg)
+	if !ok || msg.Result.Err != nil {
+		t.Fatalf("Unexpected result %+v", msg)
+	}
+
+	model, _ = updateModel(model, msg)
+	last := model.Activity[len(model.Activity)-1]
+	if last.Device != bose.MacAddress || last.Message != "Ran connect hook for Bose NC 700 Headphones: connect Bose NC 700 Headphones" {
+		t.Errorf("Unexpected log entry %+v", last)
+	}
+	if len(model.Toasts.Items) != 0 {
+		t.Errorf("Expected no toast for a hook that ran, got %v", model.Toasts.Items)
+	}
+
+	// The keyboard has no hooks, and unchanged devices run none
+	current = []bluetooth.BluetoothDevice{bose, keychron}
+	current[1].Connected = false
+	if cmd := model.connectionHooks(previous, current); cmd != nil {
+		t.Error("Expected no hooks for the keyboard disconnecting")
+	}
+}
+
+func TestDiscoveryHooks(t *testing.T) {
+	model := withHooks(viewFixture(), config.Hook{Event: config.HookDiscover, Device: "rssi>-70", Command: "true"})
+
+	phone := bluetooth.DiscoveredDevice{BluetoothDevice: bluetooth.BluetoothDevice{MacAddress: "11:22:33:44:55:66", Name: "Phone"}, RSSI: -80}
+	current := append(append([]bluetooth.DiscoveredDevice(nil), model.DiscoveredDevices...), phone)
+	if cmd := model.discoveryHooks(model.DiscoveredDevices, current); cmd != nil {
+		t.Error("Expected no hook for a distant device or one already in range")
+	}
+
+	current[len(current)-1].RSSI = -60
+	cmd := model.discoveryHooks(model.DiscoveredDevices, current)
+	if cmd == nil {
+		t.Fatal("Expected the discover hook to run for a close device")
+	}
+	if msg := cmd().(HookDoneMsg); msg.Result.Event.Device.MAC != phone.MacAddress || msg.Result.Event.Kind != config.HookDiscover {
+		t.Errorf("Unexpected event %+v", msg.Result.Event)
+	}
+}
+
+func TestHookFailureLogged(t *testing.T) {
+	model := viewFixture()
+	event := hooks.Event{Kind: config.HookLowBattery, Device: model.hookDevice(keychron.MacAddress), Battery: 9}
+	result := hooks.Result{Event: event, Output: "no such sink\nexiting\n", Err: errors.New("low_battery hook: exit status 1")}
+	model, _ = updateModel(model, HookDoneMsg{Result: result})
+
+	last := model.Activity[len(model.Activity)-1]
+	expected := "Hook for Keychron K4 failed: low_battery hook: exit status 1 (no such sink; exiting)"
+	if last.Severity != ui.SeverityError || last.Device != keychron.MacAddress || last.Message != expected {
+		t.Errorf("Unexpected log entry %+v", last)
+	}
+	if lastToast(model) != expected {
+		t.Errorf("Expected the failure toasted, got %q", lastToast(model))
+	}
+}
@@ -0,0 +1,243 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/state"
+	"btui/internal/ui"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Keys answering the hide prompt
+const (
+	hideByAddress      = "a"
+	hideByName         = "n"
+	hideByManufacturer = "m"
+)
+
+// hidePrompt asks how a device should be hidden: by address, by a name
+// pattern typed into Input, or by manufacturer
+type hidePrompt struct {
+	Device  bluetooth.BluetoothDevice
+	Input   textinput.Model
+	Editing bool
+}
+
+// reviewKeys are the extra bindings of the hidden devices view
+var reviewKeys = struct {
+	Unhide key.Binding
+	Close  key.Binding
+}{
+	Unhide: key.NewBinding(key.WithKeys("enter", "u", "delete"), key.WithHelp("enter/u", "un-hide")),
+	Close:  key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+}
+
+// ignoreTarget returns what ignore rules are matched against for a device
+func (m Model) ignoreTarget(d bluetooth.BluetoothDevice) state.Target {
+	return state.Target{
+		MAC:          d.MacAddress,
+		Name:         d.Name,
+		Manufacturer: m.DeviceInfo[d.MacAddress].Manufacturer,
+	}
+}
+
+// hiddenDevices returns the discovered devices hidden by an ignore rule, keyed
+// by MAC address. Paired devices are always shown.
+func (m Model) hiddenDevices() map[string]state.IgnoreRule {
+	paired := make(map[string]bool, len(m.PairedDevices))
+	for _, device := range m.PairedDevices {
+		paired[device.MacAddress] = true
+	}
+
+	store := state.Get()
+	hidden := make(map[string]state.IgnoreRule)
+	for _, device := range m.DiscoveredDevices {
+		if paired[device.MacAddress] {
+			continue
+		}
+		if rule, ok := store.Ignored(m.ignoreTarget(device.BluetoothDevice)); ok {
+			hidden[device.MacAddress] = rule
+		}
+	}
+	return hidden
+}
+
+// withoutHidden returns the items whose device is not hidden
+func withoutHidden(items []list.Item, hidden map[string]state.IgnoreRule) []list.Item {
+	if len(hidden) == 0 {
+		return items
+	}
+	shown := make([]list.Item, 0, len(items))
+	for _, item := range items {
+		if _, ok := hidden[itemMAC(item)]; !ok {
+			shown = append(shown, item)
+		}
+	}
+	return shown
+}
+
+// startHide opens the hide prompt for a device
+func (m Model) startHide(device bluetooth.BluetoothDevice) (tea.Model, tea.Cmd) {
+	if device.Paired {
+		m.StatusMessage = "Paired devices are always shown"
+		return m, nil
+	}
+	m.Hiding = &hidePrompt{Device: device}
+	return m, nil
+}
+
+// hidePromptText is the status line shown while the hide prompt is open
+func (m Model) hidePromptText() string {
+	p := m.Hiding
+	if p.Editing {
+		return "Hide devices named " + p.Input.View()
+	}
+	choices := fmt.Sprintf("%s address%s%s name%s",
+		hideByAddress, ui.Separator(), hideByName, ui.Separator())
+	if manufacturer := m.DeviceInfo[p.Device.MacAddress].Manufacturer; manufacturer != "" {
+		choices += fmt.Sprintf("%s %s%s", hideByManufacturer, bluetooth.ManufacturerName(manufacturer), ui.Separator())
+	}
+	return fmt.Sprintf("Hide %s by: %sesc cancel", p.Device.Name, choices)
+}
+
+// updateHide handles keys while the hide prompt is open
+func (m Model) updateHide(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := *m.Hiding
+	if msg.Type == tea.KeyEsc {
+		m.Hiding = nil
+		return m, nil
+	}
+
+	if p.Editing {
+		if msg.Type == tea.KeyEnter {
+			return m.addIgnoreRule(state.IgnoreName, p.Input.Value())
+		}
+		var cmd tea.Cmd
+		p.Input, cmd = p.Input.Update(msg)
+		m.Hiding = &p
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case hideByAddress:
+		return m.addIgnoreRule(state.IgnoreMAC, p.Device.MacAddress)
+	case hideByName:
+		// Start from the exact name so it can be widened with * and ?
+		p.Input = textinput.New()
+		p.Input.Prompt = ""
+		p.Input.SetValue(p.Device.Name)
+		p.Input.Focus()
+		p.Editing = true
+		m.Hiding = &p
+		return m, textinput.Blink
+	case hideByManufacturer:
+		if manufacturer := m.DeviceInfo[p.Device.MacAddress].Manufacturer; manufacturer != "" {
+			return m.addIgnoreRule(state.IgnoreManufacturer, manufacturer)
+		}
+	}
+	return m, nil
+}
+
+// addIgnoreRule saves a new ignore rule and hides the devices it matches
+func (m Model) addIgnoreRule(kind, value string) (tea.Model, tea.Cmd) {
+	rule, err := state.NewIgnoreRule(kind, value)
+	if err != nil {
+		m.StatusMessage = "Could not hide device: " + err.Error()
+		return m, nil
+	}
+	m.Hiding = nil
+	state.Get().AddIgnore(rule)
+	m.StatusMessage = "Hiding devices by " + m.describeRule(rule)
+	m.refreshDevices()
+	return m, saveStateCmd()
+}
+
+// describeRule names a rule in terms the user picked it by
+func (m Model) describeRule(rule state.IgnoreRule) string {
+	switch rule.Kind {
+	case state.IgnoreMAC:
+		for _, device := range m.DiscoveredDevices {
+			if rule.Match(m.ignoreTarget(device.BluetoothDevice)) {
+				return "address " + rule.Value + " (" + device.Name + ")"
+			}
+		}
+		return "address " + rule.Value
+	case state.IgnoreManufacturer:
+		return "manufacturer " + bluetooth.ManufacturerName(rule.Value)
+	default:
+		return fmt.Sprintf("name %q", rule.Value)
+	}
+}
+
+// ruleItems lists the ignore rules with how many current devices each hides
+func (m Model) ruleItems() []list.Item {
+	hidden := m.hiddenDevices()
+	rules := state.Get().IgnoreRules()
+	items := make([]list.Item, len(rules))
+	for i, rule := range rules {
+		count := 0
+		for _, r := range hidden {
+			if r == rule {
+				count++
+			}
+		}
+		description := fmt.Sprintf("hiding %d nearby devices", count)
+		if count == 1 {
+			description = "hiding 1 nearby device"
+		}
+		items[i] = ui.NewDeviceItem(m.describeRule(rule), description, rule)
+	}
+	return items
+}
+
+// openReview shows the hidden devices view
+func (m Model) openReview() (tea.Model, tea.Cmd) {
+	pane := m.layout().Main
+	m.RuleList = ui.NewList(m.ruleItems(), "Hidden Devices", pane.Width, pane.Height)
+	m.RuleList.SetFilteringEnabled(false)
+	m.RuleList.SetStatusBarItemName("rule", "rules")
+	m.RuleList.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{reviewKeys.Unhide, reviewKeys.Close}
+	}
+	m.Reviewing = true
+	if len(m.RuleList.Items()) == 0 {
+		m.StatusMessage = "No hidden devices"
+	}
+	return m, nil
+}
+
+// updateReview handles keys in the hidden devices view
+func (m Model) updateReview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, reviewKeys.Close, m.Keys.Hidden):
+		m.Reviewing = false
+		return m, nil
+
+	case key.Matches(msg, m.Keys.Quit):
+		m.Reviewing = false
+		return m.update(msg)
+
+	case key.Matches(msg, reviewKeys.Unhide):
+		item, ok := m.RuleList.SelectedItem().(ui.DeviceItem)
+		if !ok {
+			return m, nil
+		}
+		rule := item.Device().(state.IgnoreRule)
+		state.Get().RemoveIgnore(rule)
+		m.StatusMessage = "Showing devices by " + m.describeRule(rule) + " again"
+		m.refreshDevices()
+
+		index := m.RuleList.Index()
+		m.RuleList.SetItems(m.ruleItems())
+		m.RuleList.Select(min(index, len(m.RuleList.Items())-1))
+		return m, saveStateCmd()
+	}
+
+	var cmd tea.Cmd
+	m.RuleList, cmd = m.RuleList.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,170 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/state"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keys sends each rune of s to the model as a key press
+func keys(m Model, s string) Model {
+	for _, r := range s {
+		m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return m
+}
+
+// selectDevice moves the list cursor to the device with the given name
+func selectDevice(t *testing.T, m Model, name string) Model {
+	t.Helper()
+	for i, n := range visibleNames(m) {
+		if n == name {
+			m.List.Select(i)
+			return m
+		}
+	}
+	t.Fatalf("Device %q is not listed", name)
+	return m
+}
+
+func TestHideByAddress(t *testing.T) {
+	store := useState(t)
+	model := selectDevice(t, viewFixture(), "Living Room TV")
+
+	model = keys(model, "i")
+	if model.Hiding == nil || !strings.Contains(model.View(), "Hide Living Room TV by:") {
+		t.Fatal("Expected the hide prompt")
+	}
+
+	model, cmd := updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if model.Hiding != nil || cmd == nil {
+		t.Error("Expected the prompt to close and the state to be saved")
+	}
+	if rules := store.IgnoreRules(); len(rules) != 1 || rules[0].Value != "F0:99:B6:12:34:56" {
+		t.Errorf("Expected an address rule, got %+v", rules)
+	}
+	if strings.Contains(strings.Join(visibleNames(model), ","), "Living Room TV") {
+		t.Error("Expected the TV to be hidden")
+	}
+	if !strings.Contains(model.List.Title, "(1 hidden)") {
+		t.Errorf("Expected a hidden count in the title, got %q", model.List.Title)
+	}
+}
+
+func TestHideByNamePattern(t *testing.T) {
+	store := useState(t)
+	model := selectDevice(t, viewFixture(), "Living Room TV")
+
+	model = keys(model, "in")
+	if model.Hiding == nil || !model.Hiding.Editing {
+		t.Fatal("Expected the name pattern input")
+	}
+	// Widen the name to a pattern: "Living Room TV" -> "Living*"
+	for range len(" Room TV") {
+		model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	model = keys(model, "*")
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEnter})
+
+	if rules := store.IgnoreRules(); len(rules) != 1 || rules[0] != (state.IgnoreRule{Kind: state.IgnoreName, Value: "Living*"}) {
+		t.Errorf("Expected a name pattern rule, got %+v", rules)
+	}
+	if len(visibleNames(model)) != 2 {
+		t.Errorf("Expected the TV to be hidden, got %v", visibleNames(model))
+	}
+}
+
+func TestHideByManufacturer(t *testing.T) {
+	store := useState(t)
+	model := selectDevice(t, viewFixture(), "Living Room TV")
+	model, _ = updateModel(model, bluetooth.DeviceInfoMsg{
+		Info: bluetooth.DeviceInfo{MacAddress: "F0:99:B6:12:34:56", Manufacturer: "0x004C"},
+	})
+
+	model = keys(model, "i")
+	if !strings.Contains(model.hidePromptText(), "m Apple (0x004C)") {
+		t.Errorf("Expected the manufacturer choice, got %q", model.hidePromptText())
+	}
+	model = keys(model, "m")
+	if rules := store.IgnoreRules(); len(rules) != 1 || rules[0].Kind != state.IgnoreManufacturer {
+		t.Errorf("Expected a manufacturer rule, got %+v", rules)
+	}
+}
+
+func TestHidePromptCancel(t *testing.T) {
+	store := useState(t)
+	model := selectDevice(t, viewFixture(), "Living Room TV")
+
+	model = keys(model, "i")
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.Hiding != nil || len(store.IgnoreRules()) != 0 {
+		t.Error("Expected esc to cancel without adding a rule")
+	}
+
+	// Without a known manufacturer "m" does nothing
+	model = keys(model, "im")
+	if model.Hiding == nil || len(store.IgnoreRules()) != 0 {
+		t.Error("Expected the prompt to stay open")
+	}
+}
+
+func TestPairedDevicesCannotBeHidden(t *testing.T) {
+	store := useState(t)
+	model := selectDevice(t, viewFixture(), "Bose NC 700 Headphones")
+
+	model = keys(model, "i")
+	if model.Hiding != nil || model.StatusMessage != "Paired devices are always shown" {
+		t.Errorf("Expected paired devices to be refused, got %q", model.StatusMessage)
+	}
+
+	// Rules never hide a paired device either
+	store.AddIgnore(state.IgnoreRule{Kind: state.IgnoreName, Value: "*"})
+	model.refreshDevices()
+	if got := strings.Join(visibleNames(model), ","); got != "Keychron K4,Bose NC 700 Headphones" {
+		t.Errorf("Expected only the paired devices, got %q", got)
+	}
+}
+
+func TestHiddenInTable(t *testing.T) {
+	useState(t).AddIgnore(state.IgnoreRule{Kind: state.IgnoreMAC, Value: "F0:99:B6:12:34:56"})
+	model := viewFixture()
+	model = keys(model, "t")
+
+	if len(model.Table.Rows()) != 2 {
+		t.Errorf("Expected the hidden device left out of the table, got %v", model.Table.Rows())
+	}
+	if !strings.Contains(model.tableTitle(), "(1 hidden)") {
+		t.Errorf("Expected a hidden count in the table title, got %q", model.tableTitle())
+	}
+}
+
+func TestReviewAndUnhide(t *testing.T) {
+	store := useState(t)
+	store.AddIgnore(state.IgnoreRule{Kind: state.IgnoreMAC, Value: "F0:99:B6:12:34:56"})
+	model := viewFixture()
+
+	model = keys(model, "I")
+	if !model.Reviewing {
+		t.Fatal("Expected the hidden devices view")
+	}
+	view := model.View()
+	if !strings.Contains(view, "address F0:99:B6:12:34:56 (Living Room TV)") || !strings.Contains(view, "hiding 1 nearby device") {
+		t.Errorf("Expected the rule and its device in the review, got:\n%s", view)
+	}
+
+	model, cmd := updateModel(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(store.IgnoreRules()) != 0 || cmd == nil {
+		t.Error("Expected enter to remove the rule and save")
+	}
+	if len(visibleNames(model)) != 3 {
+		t.Errorf("Expected the TV to be shown again, got %v", visibleNames(model))
+	}
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.Reviewing {
+		t.Error("Expected esc to close the review")
+	}
+}
@@ -1,6 +1,7 @@
 package scan
 
 import (
+	"btui/internal/ui"
 	"fmt"
 	"os"
 
@@ -22,7 +23,7 @@ func New() *cobra.Command {
 func run(cmd *cobra.Command, args []string) {
 	m := NewModel()
 
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := tea.NewProgram(m, ui.ProgramOptions()...)
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
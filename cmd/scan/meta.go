@@ -0,0 +1,143 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/state"
+	"btui/internal/ui"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Fields of the metadata editor, in tab order
+const (
+	metaNickname = iota
+	metaTags
+	metaNote
+	metaFieldCount
+)
+
+// metaLabels title the editor fields
+var metaLabels = [metaFieldCount]string{"Nickname", "Tags", "Note"}
+
+// editorKeys are the bindings of the metadata editor
+var editorKeys = struct {
+	Next   key.Binding
+	Prev   key.Binding
+	Save   key.Binding
+	Cancel key.Binding
+}{
+	Next:   key.NewBinding(key.WithKeys("tab", "down"), key.WithHelp("tab", "next field")),
+	Prev:   key.NewBinding(key.WithKeys("shift+tab", "up"), key.WithHelp("shift+tab", "previous field")),
+	Save:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "save")),
+	Cancel: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+}
+
+// metaEditor edits the nickname, tags and note of one device
+type metaEditor struct {
+	Device bluetooth.BluetoothDevice
+	Inputs [metaFieldCount]textinput.Model
+	Focus  int
+}
+
+// displayName returns a device's nickname, or its Bluetooth name
+func displayName(d bluetooth.BluetoothDevice) string {
+	return state.Get().DisplayName(d.MacAddress, d.Name)
+}
+
+// metaParts returns the description parts showing a device's metadata: its
+// Bluetooth name when a nickname replaces it in the title, and its tags
+func metaParts(d bluetooth.BluetoothDevice) []string {
+	meta := state.Get().Meta(d.MacAddress)
+	var parts []string
+	if meta.Nickname != "" && d.Name != "" {
+		parts = append(parts, ui.MacAddressStyle.Render(d.Name))
+	}
+	if len(meta.Tags) > 0 {
+		parts = append(parts, ui.MacAddressStyle.Render("#"+strings.Join(meta.Tags, " #")))
+	}
+	return parts
+}
+
+// startEdit opens the metadata editor for a device
+func (m Model) startEdit(device bluetooth.BluetoothDevice) (tea.Model, tea.Cmd) {
+	meta := state.Get().Meta(device.MacAddress)
+	values := [metaFieldCount]string{meta.Nickname, strings.Join(meta.Tags, ", "), meta.Note}
+
+	editor := &metaEditor{Device: device}
+	for i := range editor.Inputs {
+		input := textinput.New()
+		input.Prompt = ""
+		input.CharLimit = 200
+		input.SetValue(values[i])
+		editor.Inputs[i] = input
+	}
+	editor.Inputs[metaNickname].Placeholder = device.Name
+	editor.Inputs[metaTags].Placeholder = "desk, meeting-room-3"
+	editor.Inputs[metaNickname].Focus()
+	m.Editing = editor
+	return m, textinput.Blink
+}
+
+// updateEdit handles keys while the metadata editor is open
+func (m Model) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	e := *m.Editing
+	switch {
+	case key.Matches(msg, editorKeys.Cancel):
+		m.Editing = nil
+		return m, nil
+
+	case key.Matches(msg, editorKeys.Save):
+		meta := state.Meta{
+			Nickname: e.Inputs[metaNickname].Value(),
+			Tags:     state.ParseTags(e.Inputs[metaTags].Value()),
+			Note:     e.Inputs[metaNote].Value(),
+		}
+		state.Get().SetMeta(e.Device.MacAddress, meta)
+		m.Editing = nil
+		m.StatusMessage = "Saved details of " + orDash(displayName(e.Device))
+		m.refreshDevices()
+		return m, saveStateCmd()
+
+	case key.Matches(msg, editorKeys.Next, editorKeys.Prev):
+		e.Inputs[e.Focus].Blur()
+		step := 1
+		if key.Matches(msg, editorKeys.Prev) {
+			step = metaFieldCount - 1
+		}
+		e.Focus = (e.Focus + step) % metaFieldCount
+		m.Editing = &e
+		return m, e.Inputs[e.Focus].Focus()
+	}
+
+	var cmd tea.Cmd
+	e.Inputs[e.Focus], cmd = e.Inputs[e.Focus].Update(msg)
+	m.Editing = &e
+	return m, cmd
+}
+
+// editorView renders the metadata editor in place of the device list
+func (m Model) editorView() string {
+	e := m.Editing
+	width := m.layout().Main.Width
+
+	var b strings.Builder
+	b.WriteString(ui.TitleStyle.Render("Details of "+orDash(e.Device.Name)) + "\n\n")
+	b.WriteString(ui.DetailLabelStyle.Render(e.Device.MacAddress) + "\n\n")
+	for i, input := range e.Inputs {
+		input.Width = max(width-4, 10)
+		cursor := "  "
+		if i == e.Focus {
+			cursor = "> "
+		}
+		b.WriteString(cursor + ui.DetailLabelStyle.Render(metaLabels[i]) + "\n  " + input.View() + "\n\n")
+	}
+	b.WriteString(ui.DetailLabelStyle.Render("Separate tags with commas or spaces") + "\n\n")
+
+	h := m.List.Help
+	h.Width = width
+	b.WriteString(h.ShortHelpView([]key.Binding{editorKeys.Next, editorKeys.Save, editorKeys.Cancel}))
+	return b.String()
+}
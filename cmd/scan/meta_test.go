@@ -0,0 +1,102 @@
+package scan
+
+import (
+	"btui/internal/state"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// typeText sends text to the model as typed runes
+func typeText(m Model, text string) Model {
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+	return m
+}
+
+func TestEditMetadata(t *testing.T) {
+	store := useState(t)
+	model := selectDevice(t, viewFixture(), "Living Room TV")
+
+	model = keys(model, "e")
+	if model.Editing == nil || !strings.Contains(model.View(), "Nickname") {
+		t.Fatal("Expected the metadata editor")
+	}
+
+	model = typeText(model, "Office TV")
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyTab})
+	model = typeText(model, "meeting-room-3, AV")
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyTab})
+	model = typeText(model, "Remote is in the drawer")
+	model, cmd := updateModel(model, tea.KeyMsg{Type: tea.KeyEnter})
+
+	if model.Editing != nil || cmd == nil {
+		t.Error("Expected the editor to close and the state to be saved")
+	}
+	meta := store.Meta("F0:99:B6:12:34:56")
+	if meta.Nickname != "Office TV" || strings.Join(meta.Tags, ",") != "av,meeting-room-3" || meta.Note != "Remote is in the drawer" {
+		t.Errorf("Unexpected metadata %+v", meta)
+	}
+}
+
+func TestEditCancel(t *testing.T) {
+	store := useState(t)
+	model := selectDevice(t, viewFixture(), "Living Room TV")
+
+	model = keys(model, "e")
+	model = typeText(model, "Office TV")
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.Editing != nil || !store.Meta("F0:99:B6:12:34:56").Empty() {
+		t.Error("Expected esc to close the editor without saving")
+	}
+}
+
+func TestNicknameDisplayed(t *testing.T) {
+	store := useState(t)
+	store.SetMeta("F0:99:B6:12:34:56", state.Meta{Nickname: "Office TV", Tags: []string{"av"}, Note: "Remote in drawer"})
+	model := viewFixture()
+
+	view := model.View()
+	if !strings.Contains(view, "Office TV") || !strings.Contains(view, "Living Room TV") {
+		t.Errorf("Expected the nickname with the Bluetooth name kept visible, got:\n%s", view)
+	}
+	if !strings.Contains(view, "#av") {
+		t.Error("Expected the tags in the description")
+	}
+
+	// The table shows both names in the name column
+	model = keys(model, "t")
+	found := false
+	for _, row := range model.Table.Rows() {
+		found = found || row[0] == "Office TV (Living Room TV)"
+	}
+	if !found {
+		t.Errorf("Expected a nicknamed row, got %v", model.Table.Rows())
+	}
+}
+
+func TestNicknameInDetail(t *testing.T) {
+	store := useState(t)
+	store.SetMeta("F0:99:B6:12:34:56", state.Meta{Nickname: "Office TV", Tags: []string{"av"}, Note: "Remote in drawer"})
+	model := selectDevice(t, viewFixture(), "Living Room TV")
+
+	detail := model.detailView()
+	for _, want := range []string{"Office TV", "Living Room TV", "av", "Remote in drawer"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("Expected %q in the detail pane, got:\n%s", want, detail)
+		}
+	}
+}
+
+func TestFilterByMetadata(t *testing.T) {
+	store := useState(t)
+	store.SetMeta("4C:87:5D:28:86:DD", state.Meta{Nickname: "Alex's headphones", Tags: []string{"desk"}})
+	model := viewFixture()
+
+	if got := strings.Join(visibleNames(applyQuery(model, "tag:desk")), ","); got != "Bose NC 700 Headphones" {
+		t.Errorf("Expected the tagged device, got %q", got)
+	}
+	if got := strings.Join(visibleNames(applyQuery(model, "alex")), ","); got != "Bose NC 700 Headphones" {
+		t.Errorf("Expected the nicknamed device, got %q", got)
+	}
+}
@@ -1,24 +1,51 @@
 package scan
 
 import (
+	"btui/internal/battery"
 	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/hooks"
+	"btui/internal/notify"
+	"btui/internal/presence"
+	"btui/internal/scene"
+	"btui/internal/ui"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/viewport"
 )
 
 // scanKeyMap defines the key bindings for the scan interface
 type scanKeyMap struct {
-	Up          key.Binding
-	Down        key.Binding
-	ViUp        key.Binding
-	ViDown      key.Binding
-	Enter       key.Binding
-	Scan        key.Binding
-	Connect     key.Binding
-	Disconnect  key.Binding
-	Refresh     key.Binding
-	Quit        key.Binding
+	Up            key.Binding
+	Down          key.Binding
+	ViUp          key.Binding
+	ViDown        key.Binding
+	Enter         key.Binding
+	Scan          key.Binding
+	Connect       key.Binding
+	Disconnect    key.Binding
+	Refresh       key.Binding
+	Quit          key.Binding
+	ToggleView    key.Binding
+	SortColumn    key.Binding
+	SortOrder     key.Binding
+	Favorite      key.Binding
+	FavoritesOnly key.Binding
+	Hide          key.Binding
+	Hidden        key.Binding
+	Edit          key.Binding
+	Alias         key.Binding
+	Mark          key.Binding
+	Trust         key.Binding
+	Remove        key.Binding
+	Scenes        key.Binding
+	Queue         key.Binding
+	Cancel        key.Binding
+	Details       key.Binding
+	Log           key.Binding
 }
 
 // ShortHelp returns keybindings to be shown in the mini help view
@@ -29,54 +56,84 @@ func (k scanKeyMap) ShortHelp() []key.Binding {
 // FullHelp returns keybindings for the expanded help view
 func (k scanKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Up, k.ViUp, k.Down, k.ViDown}, // navigation
-		{k.Enter, k.Connect, k.Disconnect}, // actions
-		{k.Scan, k.Refresh, k.Quit}, // controls
+		{k.Up, k.ViUp, k.Down, k.ViDown},          // navigation
+		{k.Enter, k.Connect, k.Disconnect},        // actions
+		{k.Scan, k.Refresh, k.Quit},               // controls
+		{k.ToggleView, k.SortColumn, k.SortOrder}, // table view
+		{k.Favorite, k.FavoritesOnly},             // favorites
+		{k.Hide, k.Hidden},                        // ignore list
+		{k.Edit, k.Alias},                         // naming
+		{k.Mark, k.Trust, k.Remove, k.Scenes},     // batches and scenes
+		{k.Queue, k.Cancel, k.Details, k.Log},     // operations
 	}
 }
 
-// scanKeys defines the key map for scan interface
-var scanKeys = scanKeyMap{
-	Up: key.NewBinding(
-		key.WithKeys("up", "↑"),
-		key.WithHelp("↑", "move up"),
-	),
-	Down: key.NewBinding(
-		key.WithKeys("down", "↓"),
-		key.WithHelp("↓", "move down"),
-	),
-	ViUp: key.NewBinding(
-		key.WithKeys("k"),
-		key.WithHelp("k", "move up"),
-	),
-	ViDown: key.NewBinding(
-		key.WithKeys("j"),
-		key.WithHelp("j", "move down"),
-	),
-	Enter: key.NewBinding(
-		key.WithKeys("enter"),
-		key.WithHelp("enter", "smart connect/disconnect"),
-	),
-	Scan: key.NewBinding(
-		key.WithKeys("s"),
-		key.WithHelp("s", "toggle scan"),
-	),
-	Connect: key.NewBinding(
-		key.WithKeys("c"),
-		key.WithHelp("c", "connect"),
-	),
-	Disconnect: key.NewBinding(
-		key.WithKeys("d"),
-		key.WithHelp("d", "disconnect"),
-	),
-	Refresh: key.NewBinding(
-		key.WithKeys("r"),
-		key.WithHelp("r", "refresh"),
-	),
-	Quit: key.NewBinding(
-		key.WithKeys("q", "ctrl+c"),
-		key.WithHelp("q", "quit"),
-	),
+// newScanKeyMap builds the scan key map from action -> keys bindings
+func newScanKeyMap(keys map[string][]string) scanKeyMap {
+	binding := func(action, help string) key.Binding {
+		bound := keys[action]
+		return key.NewBinding(
+			key.WithKeys(keyNames(bound)...),
+			key.WithHelp(helpKeyName(bound), help),
+		)
+	}
+	return scanKeyMap{
+		Up:            binding(config.ActionUp, "move up"),
+		Down:          binding(config.ActionDown, "move down"),
+		ViUp:          binding(config.ActionViUp, "move up"),
+		ViDown:        binding(config.ActionViDown, "move down"),
+		Enter:         binding(config.ActionEnter, "smart connect/disconnect"),
+		Scan:          binding(config.ActionScan, "toggle scan"),
+		Connect:       binding(config.ActionConnect, "connect"),
+		Disconnect:    binding(config.ActionDisconnect, "disconnect"),
+		Refresh:       binding(config.ActionRefresh, "refresh"),
+		Quit:          binding(config.ActionQuit, "quit"),
+		ToggleView:    binding(config.ActionToggleView, "list/table view"),
+		SortColumn:    binding(config.ActionSortColumn, "sort column"),
+		SortOrder:     binding(config.ActionSortOrder, "reverse sort"),
+		Favorite:      binding(config.ActionFavorite, "star/unstar"),
+		FavoritesOnly: binding(config.ActionFavorites, "favorites only"),
+		Hide:          binding(config.ActionHide, "hide device"),
+		Hidden:        binding(config.ActionHidden, "hidden devices"),
+		Edit:          binding(config.ActionEdit, "nickname/tags/note"),
+		Alias:         binding(config.ActionAlias, "set alias"),
+		Mark:          binding(config.ActionMark, "mark device"),
+		Trust:         binding(config.ActionTrust, "trust"),
+		Remove:        binding(config.ActionRemove, "remove"),
+		Scenes:        binding(config.ActionScenes, "scenes"),
+		Queue:         binding(config.ActionQueue, "operation queue"),
+		Cancel:        binding(config.ActionCancel, "cancel operation"),
+		Details:       binding(config.ActionDetails, "error details"),
+		Log:           binding(config.ActionLog, "activity log"),
+	}
+}
+
+// keyNames returns keys as Bubble Tea names them. The config spells the space
+// bar "space" since a blank key cannot be told apart from a typo.
+func keyNames(keys []string) []string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		if k == "space" {
+			k = " "
+		}
+		names[i] = k
+	}
+	return names
+}
+
+// helpKeyName returns the label shown in the help view for a set of keys
+func helpKeyName(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	switch keys[0] {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	default:
+		return keys[0]
+	}
 }
 
 // Model represents the state of the scan view
@@ -88,19 +145,97 @@ type Model struct {
 	Err               error
 	Width             int
 	Height            int
-	ConnectingTo      *bluetooth.BluetoothDevice
-	DisconnectingFrom *bluetooth.BluetoothDevice
+	Ops               *bluetooth.Manager
+	Operations        map[int]bluetooth.Operation
+	Requested         map[int]bool
+	Batch             *batch
+	Removing          []bluetooth.BluetoothDevice
 	StatusMessage     string
 	DiscoveryScanner  *bluetooth.DiscoveryScanner
 	PairedDevices     []bluetooth.BluetoothDevice
 	DiscoveredDevices []bluetooth.DiscoveredDevice
+	Keys              scanKeyMap
+	Layout            string
+	Table             table.Model
+	TableRows         []deviceRow
+	TableCells        []table.Row
+	TableCursor       int
+	TableOffset       int
+	TableColumns      []string
+	TableSort         string
+	TableDescending   bool
+	DeviceInfo        map[string]bluetooth.DeviceInfo
+	RSSIHistory       map[string][]rssiSample
+	LastResult        map[string]operationResult
+	Clicks            ui.ClickTracker
+	QueryErr          error
+	FavoritesOnly     bool
+	Hiding            *hidePrompt
+	Reviewing         bool
+	RuleList          list.Model
+	Editing           *metaEditor
+	Renaming          *aliasPrompt
+	Marked            []string
+	ChoosingScene     bool
+	SceneList         list.Model
+	SavingScene       *scenePrompt
+	SceneRun          *scene.Run
+	ExecuteStep       scene.Executor
+	ViewingQueue      bool
+	QueueList         list.Model
+	ViewingError      *ui.ErrorDetails
+	ViewingLog        bool
+	LogView           viewport.Model
+	// LogDevice limits the activity log to one device's MAC address
+	LogDevice string
+	Activity  []activityEntry
+	Toasts    ui.Toasts
+	// DevicesAt is when the paired devices were last listed, to tell
+	// connection changes made elsewhere from those btui made
+	DevicesAt time.Time
+	// Notify raises desktop notifications for connection changes
+	Notify *notify.Dispatcher
+	// Batteries holds the charge of connected devices in percent, by MAC
+	// address
+	Batteries map[string]int
+	// Battery decides when a low battery is warned about
+	Battery *battery.Monitor
+	// Hooks runs the commands configured for device events
+	Hooks *hooks.Runner
+	// Presence follows the devices of the presence rules during discovery;
+	// nil without rules
+	Presence *presence.Evaluator
 }
 
 // NewModel creates a new model for the scan command
 func NewModel() Model {
+	cfg := config.Get()
+	ops := bluetooth.NewManager(cfg.Operations.Concurrency, runOperation)
+	var tracker *presence.Evaluator
+	if len(cfg.Presence.Rules) > 0 {
+		tracker = presence.NewEvaluator(cfg.Presence.Resolved(), time.Now)
+	}
 	return Model{
 		ScanState:        ScanStopped,
 		Loading:          true,
 		DiscoveryScanner: bluetooth.NewDiscoveryScanner(),
+		Keys:             newScanKeyMap(cfg.ScanKeys()),
+		Layout:           cfg.List.View,
+		TableColumns:     cfg.Table.Columns,
+		TableSort:        cfg.Table.Sort,
+		TableDescending:  cfg.Table.Descending,
+		DeviceInfo:       make(map[string]bluetooth.DeviceInfo),
+		RSSIHistory:      make(map[string][]rssiSample),
+		LastResult:       make(map[string]operationResult),
+		Ops:              ops,
+		Operations:       make(map[int]bluetooth.Operation),
+		Requested:        make(map[int]bool),
+		ExecuteStep:      ops.Do,
+		Toasts:           ui.NewToasts(toastLimit, toastLifetime),
+		Notify:           notify.NewDispatcher(notify.Session(), cfg.Notifications),
+		Batteries:        make(map[string]int),
+		Battery:          battery.NewMonitor(cfg.Battery.Thresholds),
+		Hooks:            hooks.NewRunner(cfg),
+		Presence:         tracker,
 	}
 }
@@ -1,9 +1,19 @@
 package scan
 
 import (
+	"btui/internal/bluetooth"
+	"context"
+	"os"
 	"testing"
 )
 
+func TestMain(m *testing.M) {
+	// Queued operations never run bluetoothctl; tests report their outcome
+	// with operation messages
+	runOperation = func(context.Context, string, bluetooth.BluetoothDevice) (bool, string, error) { return true, "", nil }
+	os.Exit(m.Run())
+}
+
 func TestNewModel(t *testing.T) {
 	model := NewModel()
 
@@ -0,0 +1,81 @@
+package scan
+
+import (
+	"btui/internal/config"
+	"btui/internal/ui"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Rows above the first table row: the title and its gap, then the header and
+// its underline
+const tableHeaderRows = 4
+
+// handleMouse selects rows on click, runs the smart connect/disconnect on a
+// double click and scrolls with the wheel
+func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.Loading || m.List.Items() == nil || m.List.SettingFilter() {
+		return m, nil
+	}
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		if m.Layout == config.LayoutTable {
+			m.setTableCursor(m.TableCursor - 1)
+		} else {
+			m.List.CursorUp()
+		}
+		return m, nil
+
+	case msg.Button == tea.MouseButtonWheelDown:
+		if m.Layout == config.LayoutTable {
+			m.setTableCursor(m.TableCursor + 1)
+		} else {
+			m.List.CursorDown()
+		}
+		return m, nil
+
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		// Translate screen coordinates to the main pane inside AppStyle's padding
+		x := msg.X - ui.AppStyle.GetPaddingLeft()
+		y := msg.Y - ui.AppStyle.GetPaddingTop()
+		if x < 0 || x >= m.layout().Main.Width {
+			return m, nil
+		}
+
+		index, ok := m.rowAt(y)
+		if !ok {
+			return m, nil
+		}
+		if m.Layout == config.LayoutTable {
+			m.setTableCursor(index)
+		} else {
+			m.List.Select(index)
+		}
+
+		if m.Clicks.Click(index, time.Now()) {
+			if next, cmd, ok := m.smartConnect(); ok {
+				return next, cmd
+			}
+		}
+	}
+	return m, nil
+}
+
+// rowAt returns the index of the device drawn at row y of the main pane
+func (m Model) rowAt(y int) (int, bool) {
+	if m.Layout != config.LayoutTable {
+		return ui.ListItemAt(m.List, y)
+	}
+
+	row := y - tableHeaderRows
+	if row < 0 || row >= m.Table.Height() {
+		return 0, false
+	}
+	index := m.TableOffset + row
+	if index >= len(m.TableRows) {
+		return 0, false
+	}
+	return index, true
+}
@@ -0,0 +1,144 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rowOf returns the screen row of the first line of the view containing text
+func rowOf(t *testing.T, m Model, text string) int {
+	t.Helper()
+	for y, line := range strings.Split(m.View(), "\n") {
+		if strings.Contains(line, text) {
+			return y
+		}
+	}
+	t.Fatalf("%q not found in view", text)
+	return 0
+}
+
+func click(m Model, y int) (Model, tea.Cmd) {
+	return updateModel(m, tea.MouseMsg{X: 10, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+}
+
+func TestMouseClickSelects(t *testing.T) {
+	model := viewFixture()
+
+	for _, name := range []string{"Living Room TV", "Bose NC 700 Headphones", "Keychron K4"} {
+		// Both the title and description rows of an item select it
+		for offset := range 2 {
+			model, _ = click(model, rowOf(t, model, name)+offset)
+			device, _ := model.selectedDevice()
+			if device.Name != name {
+				t.Errorf("Expected click on %s to select it, got %q", name, device.Name)
+			}
+		}
+	}
+}
+
+func TestMouseClickOutsideList(t *testing.T) {
+	model := viewFixture()
+	before, _ := model.selectedDevice()
+
+	// The title row and the padding beside the list are not items
+	model, _ = click(model, rowOf(t, model, "Bluetooth Devices"))
+	model, _ = updateModel(model, tea.MouseMsg{X: 0, Y: rowOf(t, model, "Living Room TV"), Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+
+	if after, _ := model.selectedDevice(); after.MacAddress != before.MacAddress {
+		t.Errorf("Expected selection to stay on %s, got %s", before.Name, after.Name)
+	}
+}
+
+func TestMouseDoubleClickConnects(t *testing.T) {
+	model := viewFixture()
+	y := rowOf(t, model, "Living Room TV")
+
+	model, cmd := click(model, y)
+	if len(model.Operations) != 0 || cmd != nil {
+		t.Fatal("Expected a single click only to select")
+	}
+
+	model, cmd = click(model, y)
+	if actionOn(model, "F0:99:B6:12:34:56") != opConnect {
+		t.Fatal("Expected a double click to start connecting")
+	}
+	if cmd == nil {
+		t.Error("Expected a connect command")
+	}
+}
+
+func TestMouseDoubleClickDisconnects(t *testing.T) {
+	model := viewFixture()
+	y := rowOf(t, model, "Keychron K4")
+
+	model, _ = click(model, y)
+	model, _ = click(model, y)
+	if actionOn(model, "DC:2C:26:09:D0:0C") != opDisconnect {
+		t.Error("Expected a double click on a connected device to disconnect it")
+	}
+}
+
+func TestMouseWheel(t *testing.T) {
+	model := viewFixture()
+
+	model, _ = updateModel(model, tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+	if model.List.Index() != 1 {
+		t.Errorf("Expected wheel down to move to item 1, got %d", model.List.Index())
+	}
+	model, _ = updateModel(model, tea.MouseMsg{Button: tea.MouseButtonWheelUp, Action: tea.MouseActionPress})
+	if model.List.Index() != 0 {
+		t.Errorf("Expected wheel up to move back to item 0, got %d", model.List.Index())
+	}
+}
+
+func TestMouseTableClick(t *testing.T) {
+	model := viewFixture()
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+
+	model, _ = click(model, rowOf(t, model, "Living Room"))
+	device, _ := model.selectedDevice()
+	if device.Name != "Living Room TV" {
+		t.Errorf("Expected click to select Living Room TV, got %q", device.Name)
+	}
+
+	model, _ = updateModel(model, tea.MouseMsg{Button: tea.MouseButtonWheelUp, Action: tea.MouseActionPress})
+	if model.TableCursor != 1 {
+		t.Errorf("Expected wheel up to move the table cursor to 1, got %d", model.TableCursor)
+	}
+}
+
+func TestMouseTableClickAfterScrolling(t *testing.T) {
+	model := viewFixture()
+	devices := make([]bluetooth.DiscoveredDevice, 20)
+	for i := range devices {
+		devices[i] = bluetooth.DiscoveredDevice{
+			BluetoothDevice: bluetooth.BluetoothDevice{
+				MacAddress: fmt.Sprintf("AA:BB:CC:DD:EE:%02X", i),
+				Name:       fmt.Sprintf("Sensor %02d", i),
+			},
+			RSSI: -40 - i,
+		}
+	}
+	model, _ = updateModel(model, bluetooth.DiscoveryUpdateMsg{Devices: devices})
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+
+	for range 15 {
+		model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if model.TableOffset == 0 {
+		t.Fatal("Expected moving down past the last visible row to scroll the table")
+	}
+
+	// A refresh keeps the scroll position, and the first visible row maps to
+	// the offset rather than the top of the table
+	model, _ = updateModel(model, bluetooth.DiscoveryUpdateMsg{Devices: devices})
+	want := model.TableRows[model.TableOffset].Device.Name
+	model, _ = click(model, rowOf(t, model, want))
+	if device, _ := model.selectedDevice(); device.Name != want {
+		t.Errorf("Expected click to select %s, got %q", want, device.Name)
+	}
+}
@@ -0,0 +1,45 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/hooks"
+	"btui/internal/presence"
+	"btui/internal/state"
+	"btui/internal/ui"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// updatePresence evaluates the presence rules on a discovery update, logging
+// each device that arrived or left and running its present or absent hooks
+func (m *Model) updatePresence(devices []bluetooth.DiscoveredDevice) tea.Cmd {
+	if m.Presence == nil {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, change := range m.Presence.Observe(presence.Sightings(devices, state.Get())) {
+		event := hooks.Event{Kind: config.HookAbsent, Device: change.Device, Rule: change.Rule}
+		message := fmt.Sprintf("%s left (%s)", event.Name(), change.Rule)
+		if change.Present {
+			event.Kind = config.HookPresent
+			message = fmt.Sprintf("%s arrived (%s)", event.Name(), change.Rule)
+		}
+		m.logActivity(ui.SeverityInfo, change.Device.MAC, message)
+		cmds = append(cmds, m.runHooks(event))
+	}
+	return tea.Batch(cmds...)
+}
+
+// presenceTickCmd returns a command that sends the next PresenceTickMsg, or
+// nil without presence rules
+func (m Model) presenceTickCmd() tea.Cmd {
+	if m.Presence == nil {
+		return nil
+	}
+	return tea.Tick(config.Get().Intervals.Discovery, func(t time.Time) tea.Msg {
+		return PresenceTickMsg(t)
+	})
+}
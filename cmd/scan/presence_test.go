@@ -0,0 +1,73 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/presence"
+	"testing"
+	"time"
+)
+
+func TestPresenceLoggedAndHooked(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := at
+	rules := []config.PresenceRule{{Name: "home", Device: "name:pixel", Enter: -70, Exit: -80, Timeout: time.Minute}}
+
+	model := withHooks(viewFixture(), config.Hook{Event: config.HookPresent, Command: `echo "$BTUI_RULE"`})
+	model.Presence = presence.NewEvaluator(rules, func() time.Time { return now })
+
+	phone := bluetooth.DiscoveredDevice{
+		BluetoothDevice: bluetooth.BluetoothDevice{MacAddress: "11:22:33:44:55:66", Name: "Pixel"},
+		RSSI:            -65,
+		Timestamp:       at,
+	}
+	cmd := model.updatePresence([]bluetooth.DiscoveredDevice{phone})
+	if last := model.Activity[len(model.Activity)-1]; last.Device != phone.MacAddress || last.Message != "Pixel arrived (home)" {
+		t.Errorf("Unexpected log entry %+v", last)
+	}
+	if cmd == nil {
+		t.Fatal("Expected the present hook to run")
+	}
+	if msg := cmd().(HookDoneMsg); msg.Result.Err != nil || msg.Result.Output != "home\n" {
+		t.Errorf("Unexpected hook result %+v", msg.Result)
+	}
+
+	// Through discovery updates, the phone leaves once it has not been heard
+	// for the rule's timeout; no absent hook is configured
+	now = at.Add(time.Minute)
+	model, _ = updateModel(model, bluetooth.DiscoveryUpdateMsg{Devices: []bluetooth.DiscoveredDevice{phone}})
+	if last := model.Activity[len(model.Activity)-1]; last.Message != "Pixel left (home)" {
+		t.Errorf("Unexpected log entry %+v", last)
+	}
+}
+
+func TestPresenceAbsentAfterScanStops(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := at
+	rules := []config.PresenceRule{{Name: "home", Device: "name:pixel", Enter: -70, Exit: -80, Timeout: time.Minute}}
+
+	model := withHooks(viewFixture(), config.Hook{Event: config.HookAbsent, Command: `echo "$BTUI_NAME"`})
+	model.Presence = presence.NewEvaluator(rules, func() time.Time { return now })
+
+	phone := bluetooth.DiscoveredDevice{
+		BluetoothDevice: bluetooth.BluetoothDevice{MacAddress: "11:22:33:44:55:66", Name: "Pixel"},
+		RSSI:            -65,
+		Timestamp:       at,
+	}
+	model, _ = updateModel(model, bluetooth.DiscoveryUpdateMsg{Devices: []bluetooth.DiscoveredDevice{phone}})
+	model.ScanState = ScanStopped
+
+	// No more discovery updates arrive, but the presence tick still notices
+	// the phone has gone
+	now = at.Add(time.Minute)
+	model, cmd := updateModel(model, PresenceTickMsg(now))
+	if last := model.Activity[len(model.Activity)-1]; last.Message != "Pixel left (home)" {
+		t.Errorf("Unexpected log entry %+v", last)
+	}
+	if cmd == nil {
+		t.Fatal("Expected the absent hook to run and the next tick")
+	}
+	if model.presenceTickCmd() == nil {
+		t.Error("Expected the presence tick to keep running")
+	}
+}
@@ -0,0 +1,110 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/ui"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// queueKeys are the extra bindings of the operation queue view
+var queueKeys = struct {
+	Cancel  key.Binding
+	Details key.Binding
+	Close   key.Binding
+}{
+	Cancel:  key.NewBinding(key.WithKeys("x", "delete"), key.WithHelp("x", "cancel")),
+	Details: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "error details")),
+	Close:   key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+}
+
+// queueItems lists the queued and running operations in the order they run,
+// then recently finished ones
+func (m Model) queueItems() []list.Item {
+	ops := m.Ops.Operations()
+	items := make([]list.Item, len(ops))
+	now := time.Now()
+	for i, op := range ops {
+		title := op.Action + " " + displayName(op.Device)
+		parts := []string{op.State.String()}
+		switch {
+		case op.State.Finished():
+			parts = append(parts, lastSeen(op.Finished, now))
+		case op.State == bluetooth.OpRunning:
+			parts = append(parts, "started "+lastSeen(op.Started, now))
+			if op.Attempt > 1 {
+				parts = append(parts, fmt.Sprintf("attempt %d/%d", op.Attempt, op.Attempts))
+			}
+		default:
+			parts = append(parts, "queued "+lastSeen(op.Queued, now))
+		}
+		if output := strings.TrimSpace(op.Output); op.State == bluetooth.OpFailed && output != "" {
+			parts = append(parts, output)
+		}
+		items[i] = ui.NewDeviceItem(title, ui.JoinDescription(parts...), op)
+	}
+	return items
+}
+
+// openQueue shows the operation queue view
+func (m Model) openQueue() (tea.Model, tea.Cmd) {
+	pane := m.layout().Main
+	m.QueueList = ui.NewList(m.queueItems(), "Operations", pane.Width, pane.Height)
+	m.QueueList.SetFilteringEnabled(false)
+	m.QueueList.SetStatusBarItemName("operation", "operations")
+	m.QueueList.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{queueKeys.Cancel, queueKeys.Details, queueKeys.Close}
+	}
+	m.ViewingQueue = true
+	if len(m.QueueList.Items()) == 0 {
+		m.StatusMessage = "No operations yet"
+	}
+	return m, nil
+}
+
+// updateQueue handles keys in the operation queue view
+func (m Model) updateQueue(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, queueKeys.Close, m.Keys.Queue):
+		m.ViewingQueue = false
+		return m, nil
+
+	case key.Matches(msg, m.Keys.Quit):
+		m.ViewingQueue = false
+		return m.update(msg)
+
+	case key.Matches(msg, queueKeys.Cancel):
+		item, ok := m.QueueList.SelectedItem().(ui.DeviceItem)
+		if !ok {
+			return m, nil
+		}
+		op := item.Device().(bluetooth.Operation)
+		if !m.Ops.Cancel(op.ID) {
+			m.StatusMessage = "Only queued operations, and connects and disconnects still running, can be cancelled"
+			return m, nil
+		}
+		// The cancellation arrives as an operation message like any other change
+		return m, m.Ops.Listen()
+
+	case key.Matches(msg, queueKeys.Details):
+		item, ok := m.QueueList.SelectedItem().(ui.DeviceItem)
+		if !ok {
+			return m, nil
+		}
+		op := item.Device().(bluetooth.Operation)
+		if op.State != bluetooth.OpFailed {
+			m.StatusMessage = "Only failed operations have error details"
+			return m, nil
+		}
+		return m.openErrorDetails(op.Details())
+	}
+
+	var cmd tea.Cmd
+	m.QueueList, cmd = m.QueueList.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,261 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/ui"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// blockingOps gives the model an operation manager whose operations keep
+// running until the test ends
+func blockingOps(t *testing.T, m Model, limit int) Model {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	m.Ops = bluetooth.NewManager(limit, func(ctx context.Context, _ string, _ bluetooth.BluetoothDevice) (bool, string, error) {
+		select {
+		case <-release:
+			return true, "", nil
+		case <-ctx.Done():
+			return false, "", nil
+		}
+	})
+	return m
+}
+
+// untilIdle feeds the manager's operation messages to the model until no
+// operation is queued or running
+func untilIdle(m Model) Model {
+	for len(m.Operations) > 0 {
+		m, _ = updateModel(m, m.Ops.Next()().(bluetooth.OperationMsg))
+	}
+	return m
+}
+
+// untilState feeds the manager's operation messages to the model until one
+// reaches the given state
+func untilState(m Model, state bluetooth.OpState) Model {
+	for {
+		msg := m.Ops.Next()().(bluetooth.OperationMsg)
+		m, _ = updateModel(m, msg)
+		if msg.Op.State == state {
+			return m
+		}
+	}
+}
+
+func TestEnterQueuesBehindOperation(t *testing.T) {
+	model := selectDevice(t, blockingOps(t, viewFixture(), 2), "Keychron K4")
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.StatusMessage != "Disconnecting from Keychron K4..." {
+		t.Fatalf("Unexpected status %q", model.StatusMessage)
+	}
+
+	// Enter again reconnects once the disconnect is done
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.StatusMessage != "Queued connect of Keychron K4 after disconnect" {
+		t.Errorf("Expected feedback about the queued connect, got %q", model.StatusMessage)
+	}
+	if ops := model.deviceOperations(keychron.MacAddress); len(ops) != 2 || ops[1].Action != opConnect || ops[1].State != bluetooth.OpQueued {
+		t.Fatalf("Expected a connect queued behind the disconnect, got %+v", ops)
+	}
+	if rowStatus(model, keychron.MacAddress) != statusDisconnecting {
+		t.Errorf("Expected the running operation shown, got %q", rowStatus(model, keychron.MacAddress))
+	}
+
+	model = finish(t, model, keychron, true, "")
+	if actionOn(model, keychron.MacAddress) != opConnect || rowStatus(model, keychron.MacAddress) != statusQueued {
+		t.Errorf("Expected the connect next, got %q", rowStatus(model, keychron.MacAddress))
+	}
+}
+
+func TestQueueLimitsConcurrency(t *testing.T) {
+	useState(t)
+	model := blockingOps(t, viewFixture(), 1)
+	model = mark(t, model, "Living Room TV")
+	model = mark(t, model, "Bose NC 700 Headphones")
+
+	model = keys(model, "c")
+	running, queued := 0, 0
+	for _, mac := range []string{tv.MacAddress, bose.MacAddress} {
+		switch rowStatus(model, mac) {
+		case statusConnecting:
+			running++
+		case statusQueued:
+			queued++
+		}
+	}
+	if running != 1 || queued != 1 {
+		t.Errorf("Expected one connect running and one queued, got %d and %d", running, queued)
+	}
+}
+
+func TestQueueView(t *testing.T) {
+	model := blockingOps(t, viewFixture(), 1)
+	model = keys(selectDevice(t, model, "Bose NC 700 Headphones"), "T")
+	model = keys(selectDevice(t, model, "Living Room TV"), "c")
+	if model.StatusMessage != "Queued connect of Living Room TV until another operation finishes" {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+
+	model = keys(model, "Q")
+	if !model.ViewingQueue || len(model.QueueList.Items()) != 2 {
+		t.Fatalf("Expected both operations listed, got %d", len(model.QueueList.Items()))
+	}
+	view := model.View()
+	for _, expected := range []string{"trust Bose NC 700 Headphones", "Running", "connect Living Room TV", "Queued"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected the queue to show %q", expected)
+		}
+	}
+
+	// A running trust is left to finish
+	model = keys(model, "x")
+	if model.StatusMessage != "Only queued operations, and connects and disconnects still running, can be cancelled" {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+
+	model = keys(model, "jx")
+	model = untilState(model, bluetooth.OpCancelled)
+	if lastToast(model) != "Cancelled connect of Living Room TV" || model.busy(tv) {
+		t.Errorf("Expected the queued connect cancelled, got %q", lastToast(model))
+	}
+	if !strings.Contains(model.View(), "Cancelled") {
+		t.Error("Expected the queue to show the cancelled operation")
+	}
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.ViewingQueue {
+		t.Error("Expected esc to close the queue")
+	}
+}
+
+func TestCancelRunningConnect(t *testing.T) {
+	model := blockingOps(t, viewFixture(), 2)
+	model = keys(selectDevice(t, model, "Bose NC 700 Headphones"), "c")
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.StatusMessage != "Cancelling connect of Bose NC 700 Headphones..." {
+		t.Fatalf("Unexpected status %q", model.StatusMessage)
+	}
+	model = untilIdle(model)
+	if lastToast(model) != "Cancelled connect of Bose NC 700 Headphones" {
+		t.Errorf("Unexpected result %q", lastToast(model))
+	}
+	if result := model.LastResult[bose.MacAddress]; !result.Cancelled || result.Success {
+		t.Errorf("Expected a cancelled result rather than a failure, got %+v", result)
+	}
+	if rowStatus(model, bose.MacAddress) != statusPaired {
+		t.Errorf("Expected the device back to its status, got %q", rowStatus(model, bose.MacAddress))
+	}
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.StatusMessage != "Nothing to cancel" {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+}
+
+func TestCancelBatch(t *testing.T) {
+	useState(t)
+	model := blockingOps(t, viewFixture(), 2)
+	model = mark(t, model, "Living Room TV")
+	model = mark(t, model, "Bose NC 700 Headphones")
+	model = keys(model, "c")
+
+	// With an idle device selected, esc calls off the rest of the batch
+	model = selectDevice(t, model, "Keychron K4")
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.StatusMessage != "Cancelling 2 operations..." {
+		t.Fatalf("Unexpected status %q", model.StatusMessage)
+	}
+	model = untilIdle(model)
+	if lastToast(model) != "Connected 0 of 2 devices, 2 cancelled" || model.Toasts.Items[len(model.Toasts.Items)-1].Severity != ui.SeverityWarning {
+		t.Errorf("Expected cancellations apart from failures, got %q", lastToast(model))
+	}
+}
+
+func TestEscClearsFilterFirst(t *testing.T) {
+	model := blockingOps(t, viewFixture(), 2)
+	model = keys(selectDevice(t, model, "Bose NC 700 Headphones"), "c")
+	model = applyQuery(model, "bose")
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.List.FilterState() != list.Unfiltered || !model.busy(bose) {
+		t.Error("Expected esc to clear the filter and leave the connect running")
+	}
+}
+
+func TestRetryShowsAttempt(t *testing.T) {
+	model := blockingOps(t, viewFixture(), 2)
+	model = keys(selectDevice(t, model, "Bose NC 700 Headphones"), "c")
+
+	// The manager publishes each further attempt of a connect
+	op := model.deviceOperations(bose.MacAddress)[0]
+	op.Attempt, op.Attempts = 2, 3
+	model, _ = updateModel(model, bluetooth.OperationMsg{Op: op})
+	if model.StatusMessage != "Connecting to Bose NC 700 Headphones... attempt 2/3" {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+
+	model = keys(model, "Q")
+	if !strings.Contains(model.View(), "attempt 2/3") {
+		t.Error("Expected the queue to show the attempt")
+	}
+}
+
+func TestErrorDetailsModal(t *testing.T) {
+	model := blockingOps(t, viewFixture(), 2)
+	model = keys(selectDevice(t, model, "Bose NC 700 Headphones"), "c")
+	model = finish(t, model, bose, false, "Attempting to connect to 4C:87:5D:28:86:DD\nFailed to connect: org.bluez.Error.Failed br-connection-page-timeout")
+
+	// A failed connect from the view opens its details at once
+	if model.ViewingError == nil {
+		t.Fatal("Expected the error details shown")
+	}
+	view := model.View()
+	for _, expected := range []string{"connect failed", "Cause:       page timeout", "org.bluez.Error.Failed"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected the modal to show %q", expected)
+		}
+	}
+
+	model, cmd := updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if cmd == nil || model.ViewingError == nil {
+		t.Fatal("Expected c to copy the details rather than connect")
+	}
+	model, _ = updateModel(model, ui.ClipboardMsg{})
+	if model.StatusMessage != "Copied the error details to the clipboard" {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.ViewingError != nil {
+		t.Fatal("Expected esc to close the details")
+	}
+
+	// E brings them back for the selected device
+	model = keys(model, "E")
+	if model.ViewingError == nil || model.ViewingError.Operation != opConnect {
+		t.Error("Expected E to reopen the details")
+	}
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEsc})
+	model = keys(selectDevice(t, model, "Keychron K4"), "E")
+	if model.ViewingError != nil || model.StatusMessage != "No failed operation on Keychron K4" {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+}
+
+func TestQueueErrorDetails(t *testing.T) {
+	model := blockingOps(t, viewFixture(), 2)
+	model = keys(selectDevice(t, model, "Bose NC 700 Headphones"), "c")
+	model = keys(model, "Q")
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.ViewingError != nil || model.StatusMessage != "Only failed operations have error details" {
+		t.Errorf("Unexpected status %q", model.StatusMessage)
+	}
+}
@@ -0,0 +1,325 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/scene"
+	"btui/internal/state"
+	"btui/internal/ui"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Keys answering what a new scene does with the marked devices
+const (
+	intentConnect    = "c"
+	intentDisconnect = "d"
+	intentAsNow      = "n"
+)
+
+// scenePrompt saves the marked devices as a scene: first what to do with
+// them, then the scene name typed into Input
+type scenePrompt struct {
+	Intent string
+	Input  textinput.Model
+}
+
+// sceneKeys are the extra bindings of the scenes view
+var sceneKeys = struct {
+	Apply  key.Binding
+	New    key.Binding
+	Delete key.Binding
+	Close  key.Binding
+}{
+	Apply:  key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "apply")),
+	New:    key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new from marked")),
+	Delete: key.NewBinding(key.WithKeys("x", "delete"), key.WithHelp("x", "delete")),
+	Close:  key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+}
+
+// toggleMark marks or unmarks a device for a new scene. Marks keep the order
+// they were made in, which becomes the order of the scene's steps.
+func (m Model) toggleMark(device bluetooth.BluetoothDevice) (tea.Model, tea.Cmd) {
+	if i := slices.Index(m.Marked, device.MacAddress); i >= 0 {
+		m.Marked = slices.Delete(slices.Clone(m.Marked), i, i+1)
+	} else {
+		m.Marked = append(slices.Clone(m.Marked), device.MacAddress)
+	}
+	m.refreshDevices()
+	return m, nil
+}
+
+// isMarked reports whether a device is marked
+func (m Model) isMarked(mac string) bool {
+	return slices.Contains(m.Marked, mac)
+}
+
+// markItems prefixes the titles of marked devices with the mark
+func (m Model) markItems(items []list.Item) []list.Item {
+	if len(m.Marked) == 0 {
+		return items
+	}
+	for i, item := range items {
+		if deviceItem, ok := item.(ui.DeviceItem); ok && m.isMarked(itemMAC(item)) {
+			items[i] = ui.NewDeviceItem(ui.MarkedMarker()+deviceItem.Title(), deviceItem.Description(), deviceItem.Device())
+		}
+	}
+	return items
+}
+
+// knownDevices returns every paired and discovered device, paired entries
+// taking priority
+func (m Model) knownDevices() []bluetooth.BluetoothDevice {
+	devices := slices.Clone(m.PairedDevices)
+	seen := make(map[string]bool, len(devices))
+	for _, device := range devices {
+		seen[device.MacAddress] = true
+	}
+	for _, device := range m.DiscoveredDevices {
+		if !seen[device.MacAddress] {
+			devices = append(devices, device.BluetoothDevice)
+		}
+	}
+	return devices
+}
+
+// stepName names a step's device as the list shows it
+func (m Model) stepName(step config.SceneStep) string {
+	if device, err := bluetooth.MatchDevice(m.knownDevices(), step.Device); err == nil {
+		return displayName(device)
+	}
+	return step.Device
+}
+
+// sceneItems lists every scene with its steps
+func (m Model) sceneItems() []list.Item {
+	scenes := scene.List()
+	items := make([]list.Item, len(scenes))
+	for i, s := range scenes {
+		steps := make([]string, len(s.Steps))
+		for j, step := range s.Steps {
+			steps[j] = step.Action + " " + m.stepName(step)
+		}
+		source := "config"
+		if s.Saved {
+			source = "saved"
+		}
+		items[i] = ui.NewDeviceItem(s.Name, ui.JoinDescription(source, strings.Join(steps, ", ")), s)
+	}
+	return items
+}
+
+// openScenes shows the scenes view
+func (m Model) openScenes() (tea.Model, tea.Cmd) {
+	pane := m.layout().Main
+	m.SceneList = ui.NewList(m.sceneItems(), "Scenes", pane.Width, pane.Height)
+	m.SceneList.SetFilteringEnabled(false)
+	m.SceneList.SetStatusBarItemName("scene", "scenes")
+	m.SceneList.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{sceneKeys.Apply, sceneKeys.New, sceneKeys.Delete, sceneKeys.Close}
+	}
+	m.ChoosingScene = true
+	if len(m.SceneList.Items()) == 0 {
+		m.StatusMessage = "No scenes yet: mark devices with " + m.Keys.Mark.Help().Key + " and press " + sceneKeys.New.Help().Key
+	}
+	return m, nil
+}
+
+// updateScenes handles keys in the scenes view
+func (m Model) updateScenes(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, sceneKeys.Close, m.Keys.Scenes):
+		m.ChoosingScene = false
+		return m, nil
+
+	case key.Matches(msg, m.Keys.Quit):
+		m.ChoosingScene = false
+		return m.update(msg)
+
+	case key.Matches(msg, sceneKeys.New):
+		if len(m.Marked) == 0 {
+			m.StatusMessage = "Mark devices with " + m.Keys.Mark.Help().Key + " first"
+			return m, nil
+		}
+		m.SavingScene = &scenePrompt{}
+		return m, nil
+
+	case key.Matches(msg, sceneKeys.Apply):
+		if item, ok := m.SceneList.SelectedItem().(ui.DeviceItem); ok {
+			m.ChoosingScene = false
+			return m.applyScene(item.Device().(scene.Scene))
+		}
+		return m, nil
+
+	case key.Matches(msg, sceneKeys.Delete):
+		item, ok := m.SceneList.SelectedItem().(ui.DeviceItem)
+		if !ok {
+			return m, nil
+		}
+		s := item.Device().(scene.Scene)
+		if !s.Saved {
+			m.StatusMessage = "Scene " + s.Name + " is defined in the config file"
+			return m, nil
+		}
+		state.Get().DeleteScene(s.Name)
+		m.StatusMessage = "Deleted scene " + s.Name
+
+		index := m.SceneList.Index()
+		m.SceneList.SetItems(m.sceneItems())
+		m.SceneList.Select(min(index, len(m.SceneList.Items())-1))
+		return m, saveStateCmd()
+	}
+
+	var cmd tea.Cmd
+	m.SceneList, cmd = m.SceneList.Update(msg)
+	return m, cmd
+}
+
+// scenePromptText is the status line shown while saving a scene
+func (m Model) scenePromptText() string {
+	p := m.SavingScene
+	if p.Intent != "" {
+		return "Scene name (esc cancels): " + p.Input.View()
+	}
+	devices := fmt.Sprintf("%d marked devices", len(m.Marked))
+	if len(m.Marked) == 1 {
+		devices = "1 marked device"
+	}
+	return fmt.Sprintf("Save %s to: %s connect%s%s disconnect%s%s keep as now%sesc cancel",
+		devices, intentConnect, ui.Separator(), intentDisconnect, ui.Separator(), intentAsNow, ui.Separator())
+}
+
+// updateSaveScene handles keys while saving the marked devices as a scene
+func (m Model) updateSaveScene(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := *m.SavingScene
+	if msg.Type == tea.KeyEsc {
+		m.SavingScene = nil
+		return m, nil
+	}
+
+	if p.Intent != "" {
+		if msg.Type == tea.KeyEnter {
+			return m.saveScene(p.Input.Value(), p.Intent)
+		}
+		var cmd tea.Cmd
+		p.Input, cmd = p.Input.Update(msg)
+		m.SavingScene = &p
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case intentConnect, intentDisconnect, intentAsNow:
+		p.Intent = msg.String()
+		p.Input = textinput.New()
+		p.Input.Prompt = ""
+		p.Input.Focus()
+		m.SavingScene = &p
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
+// saveScene stores the marked devices as a scene and clears the marks
+func (m Model) saveScene(name, intent string) (tea.Model, tea.Cmd) {
+	connected := make(map[string]bool)
+	for _, device := range m.knownDevices() {
+		connected[device.MacAddress] = device.Connected
+	}
+
+	s := config.Scene{Steps: make([]config.SceneStep, len(m.Marked))}
+	for i, mac := range m.Marked {
+		action := config.SceneConnect
+		if intent == intentDisconnect || (intent == intentAsNow && !connected[mac]) {
+			action = config.SceneDisconnect
+		}
+		s.Steps[i] = config.SceneStep{Device: mac, Action: action}
+	}
+
+	name = strings.TrimSpace(name)
+	if _, ok := config.Get().Scenes[name]; ok {
+		m.StatusMessage = "Scene " + name + " is defined in the config file"
+		return m, nil
+	}
+	if err := state.Get().SaveScene(name, s); err != nil {
+		m.StatusMessage = "Could not save scene: " + err.Error()
+		return m, nil
+	}
+	m.SavingScene = nil
+	m.Marked = nil
+	m.StatusMessage = "Saved scene " + name
+	m.refreshDevices()
+	if m.ChoosingScene {
+		m.SceneList.SetItems(m.sceneItems())
+	}
+	return m, saveStateCmd()
+}
+
+// applyScene starts running a scene's steps
+func (m Model) applyScene(s scene.Scene) (tea.Model, tea.Cmd) {
+	if m.SceneRun != nil {
+		m.StatusMessage = "Scene " + m.SceneRun.Scene.Name + " is still running"
+		return m, nil
+	}
+	execute := m.ExecuteStep
+	if execute == nil {
+		execute = scene.Execute
+	}
+	m.SceneRun = scene.Start(s, m.knownDevices(), execute)
+	m.StatusMessage = m.sceneProgressText()
+	// Steps run through the operation queue, which reports device statuses
+	return m, tea.Batch(m.SceneRun.Next(), m.Ops.Listen())
+}
+
+// sceneProgressText shows how far each step of the running scene has got
+func (m Model) sceneProgressText() string {
+	r := m.SceneRun
+	steps := make([]string, len(r.Steps))
+	for i, p := range r.Steps {
+		name := m.stepName(p.Step)
+		switch p.Status {
+		case scene.Running:
+			steps[i] = p.Step.Action + "ing " + name + "..."
+		case scene.Succeeded:
+			steps[i] = ui.SuccessMarker() + " " + name
+		case scene.Failed:
+			steps[i] = ui.FailureMarker() + " " + name
+		case scene.Skipped:
+			steps[i] = name + " " + p.Output
+		default:
+			steps[i] = name + " waiting"
+		}
+	}
+	return fmt.Sprintf("Scene %s %d/%d: %s", r.Scene.Name, r.Finished(), len(r.Steps), ui.JoinDescription(steps...))
+}
+
+// updateSceneProgress records a step change and waits for the next one
+func (m Model) updateSceneProgress(msg scene.ProgressMsg) (tea.Model, tea.Cmd) {
+	if msg.Run != m.SceneRun {
+		return m, nil
+	}
+	p := msg.Progress
+	m.SceneRun.Record(p)
+	if (p.Status == scene.Succeeded || p.Status == scene.Failed) && p.Device.MacAddress != "" {
+		m.recordResult(p.Device, p.Step.Action, p.Status == scene.Succeeded, p.Output)
+	}
+	m.StatusMessage = m.sceneProgressText()
+	m.refreshDevices()
+	return m, m.SceneRun.Next()
+}
+
+// finishScene shows the summary of a finished scene and refreshes the devices
+func (m Model) finishScene(msg scene.DoneMsg) (tea.Model, tea.Cmd) {
+	if msg.Run != m.SceneRun {
+		return m, nil
+	}
+	m.SceneRun = nil
+	m.settleStatus()
+	toast := m.toast(sceneSeverity(msg.Run.Steps), scene.Summary(msg.Run.Scene.Name, msg.Run.Steps))
+	return m, tea.Batch(toast, bluetooth.FetchDevicesCmd())
+}
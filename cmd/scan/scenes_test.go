@@ -0,0 +1,127 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/scene"
+	"btui/internal/ui"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mark selects a device and marks it with the space bar
+func mark(t *testing.T, m Model, name string) Model {
+	t.Helper()
+	m = selectDevice(t, m, name)
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	return m
+}
+
+func TestMarkAndSaveScene(t *testing.T) {
+	store := useState(t)
+	model := mark(t, viewFixture(), "Living Room TV")
+	model = mark(t, model, "Bose NC 700 Headphones")
+
+	if !strings.Contains(model.List.Title, "(2 marked)") || !strings.Contains(model.View(), ui.MarkedMarker()+"Bose") {
+		t.Errorf("Expected the marks in the list, got title %q", model.List.Title)
+	}
+
+	model = keys(model, "Sn")
+	if model.SavingScene == nil || !strings.Contains(model.View(), "Save 2 marked devices to:") {
+		t.Fatal("Expected the save prompt")
+	}
+	model = keys(model, "cdesk")
+	model, cmd := updateModel(model, tea.KeyMsg{Type: tea.KeyEnter})
+
+	desk, ok := store.Scenes()["desk"]
+	if !ok || cmd == nil {
+		t.Fatal("Expected the desk scene to be saved")
+	}
+	// Steps follow the order devices were marked in
+	expected := []config.SceneStep{
+		{Device: "F0:99:B6:12:34:56", Action: config.SceneConnect},
+		{Device: "4C:87:5D:28:86:DD", Action: config.SceneConnect},
+	}
+	if len(desk.Steps) != 2 || desk.Steps[0] != expected[0] || desk.Steps[1] != expected[1] {
+		t.Errorf("Expected %+v, got %+v", expected, desk.Steps)
+	}
+	if len(model.Marked) != 0 || !strings.Contains(model.View(), "connect Living Room TV, connect Bose NC 700 Headphones") {
+		t.Errorf("Expected the marks cleared and the scene listed, got:\n%s", model.View())
+	}
+}
+
+func TestSaveSceneAsNow(t *testing.T) {
+	store := useState(t)
+	model := mark(t, viewFixture(), "Keychron K4")
+	model = mark(t, model, "Bose NC 700 Headphones")
+	model = mark(t, model, "Living Room TV")
+	model = mark(t, model, "Living Room TV")
+
+	model = keys(model, "Snnmorning")
+	updateModel(model, tea.KeyMsg{Type: tea.KeyEnter})
+
+	steps := store.Scenes()["morning"].Steps
+	if len(steps) != 2 || steps[0].Action != config.SceneConnect || steps[1].Action != config.SceneDisconnect {
+		t.Errorf("Expected the keyboard kept connected and the headphones disconnected, got %+v", steps)
+	}
+}
+
+func TestApplyScene(t *testing.T) {
+	store := useState(t)
+	store.SaveScene("desk", config.Scene{Steps: []config.SceneStep{
+		{Device: "Bose NC 700 Headphones", Action: config.SceneConnect},
+		{Device: "Keychron K4", Action: config.SceneConnect},
+	}})
+	model := viewFixture()
+	model.ExecuteStep = func(action string, device bluetooth.BluetoothDevice) (bool, string) {
+		return false, "Failed to connect: org.bluez.Error.Failed br-connection-page-timeout"
+	}
+
+	model = keys(model, "S")
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.ChoosingScene || model.SceneRun == nil {
+		t.Fatal("Expected the scene to start")
+	}
+	cmd := model.SceneRun.Next()
+
+	var progress []string
+	for {
+		msg := cmd()
+		model, cmd = updateModel(model, msg)
+		if _, done := msg.(scene.DoneMsg); done {
+			break
+		}
+		progress = append(progress, model.StatusMessage)
+	}
+
+	if !strings.Contains(strings.Join(progress, "\n"), "connecting Bose NC 700 Headphones...") {
+		t.Errorf("Expected per-device progress, got %q", progress)
+	}
+	expected := "Scene desk: 0 done, 1 skipped, 1 failed (Bose NC 700 Headphones: Failed to connect"
+	if !strings.HasPrefix(lastToast(model), expected) || model.SceneRun != nil {
+		t.Errorf("Expected a summary of failures, got %q", lastToast(model))
+	}
+	if result := model.LastResult["4C:87:5D:28:86:DD"]; result.Action != "connect" || result.Success {
+		t.Errorf("Expected the failure recorded for the detail pane, got %+v", result)
+	}
+}
+
+func TestConfigScenesCannotBeDeleted(t *testing.T) {
+	useState(t)
+	cfg := config.Default()
+	cfg.Scenes = map[string]config.Scene{"desk": {Steps: []config.SceneStep{{Device: "Keychron K4", Action: config.SceneConnect}}}}
+	config.Set(cfg)
+	t.Cleanup(func() { config.Set(config.Default()) })
+
+	model := keys(viewFixture(), "Sx")
+	if model.StatusMessage != "Scene desk is defined in the config file" || len(model.SceneList.Items()) != 1 {
+		t.Errorf("Expected config scenes to be kept, got %q", model.StatusMessage)
+	}
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.ChoosingScene {
+		t.Error("Expected esc to close the scenes view")
+	}
+}
@@ -0,0 +1,359 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/state"
+	"btui/internal/ui"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// deviceRow holds everything the table view shows about one device
+type deviceRow struct {
+	Device   bluetooth.BluetoothDevice
+	Status   string
+	RSSI     int
+	Icon     string
+	LastSeen time.Time
+	Favorite bool
+	Marked   bool
+}
+
+// columnSpec describes how a table column is titled and sized
+type columnSpec struct {
+	title string
+	width int
+}
+
+// columnSpecs holds the title and preferred width of every column; the name
+// column takes whatever width is left over
+var columnSpecs = map[string]columnSpec{
+	config.ColumnName:     {"Name", 0},
+	config.ColumnMAC:      {"MAC", 17},
+	config.ColumnStatus:   {"Status", 16},
+	config.ColumnRSSI:     {"Signal", 6},
+	config.ColumnType:     {"Type", 14},
+	config.ColumnBattery:  {"Battery", 7},
+	config.ColumnLastSeen: {"Last seen", 9},
+}
+
+// deviceRows merges paired and discovered devices into table rows, with
+// paired entries taking priority like in the list view
+func (m Model) deviceRows() []deviceRow {
+	discovered := make(map[string]bluetooth.DiscoveredDevice, len(m.DiscoveredDevices))
+	for _, device := range m.DiscoveredDevices {
+		discovered[device.MacAddress] = device
+	}
+
+	// Rows follow the query applied to the list and the favorites toggle
+	q, _ := m.activeQuery()
+	store := state.Get()
+	hidden := m.hiddenDevices()
+
+	rows := make([]deviceRow, 0, len(m.PairedDevices)+len(m.DiscoveredDevices))
+	seen := make(map[string]bool, len(m.PairedDevices))
+	add := func(device bluetooth.BluetoothDevice) {
+		seen[device.MacAddress] = true
+		row := deviceRow{
+			Device:   device,
+			Status:   deviceStatus(device, m.operationStatuses()),
+			Icon:     m.DeviceInfo[device.MacAddress].Icon,
+			Favorite: store.IsFavorite(device.MacAddress),
+			Marked:   m.isMarked(device.MacAddress),
+		}
+		if m.FavoritesOnly && !row.Favorite {
+			return
+		}
+		if d, ok := discovered[device.MacAddress]; ok {
+			row.RSSI = d.RSSI
+			row.LastSeen = d.Timestamp
+		}
+		if q.Match(m.queryDevice(device, row.RSSI)) {
+			rows = append(rows, row)
+		}
+	}
+
+	for _, device := range m.PairedDevices {
+		add(device)
+	}
+	for _, device := range m.DiscoveredDevices {
+		if _, ok := hidden[device.MacAddress]; !ok && !seen[device.MacAddress] {
+			add(device.BluetoothDevice)
+		}
+	}
+	return rows
+}
+
+// statusRank orders devices connected -> paired -> discovered
+func statusRank(d bluetooth.BluetoothDevice) int {
+	switch {
+	case d.Connected:
+		return 0
+	case d.Paired:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// sortRows sorts table rows by a column, falling back to name order for ties
+func sortRows(rows []deviceRow, column string, descending bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		cmp := 0
+		switch column {
+		case config.ColumnMAC:
+			cmp = strings.Compare(a.Device.MacAddress, b.Device.MacAddress)
+		case config.ColumnStatus:
+			cmp = statusRank(a.Device) - statusRank(b.Device)
+		case config.ColumnRSSI:
+			// Devices without a reading sort last in either direction
+			if (a.RSSI == 0) != (b.RSSI == 0) {
+				return b.RSSI == 0
+			}
+			// Stronger signal sorts first in ascending order
+			cmp = b.RSSI - a.RSSI
+		case config.ColumnType:
+			cmp = strings.Compare(a.Icon, b.Icon)
+		case config.ColumnBattery:
+			// Emptiest battery sorts first in ascending order
+			cmp = chargeRank(a.Device) - chargeRank(b.Device)
+		case config.ColumnLastSeen:
+			// Most recently seen sorts first in ascending order
+			cmp = b.LastSeen.Compare(a.LastSeen)
+		}
+		if descending {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp < 0
+		}
+		return strings.ToLower(displayName(a.Device)) < strings.ToLower(displayName(b.Device))
+	})
+}
+
+// nextColumn returns the column after current, wrapping around
+func nextColumn(columns []string, current string) string {
+	for i, column := range columns {
+		if column == current {
+			return columns[(i+1)%len(columns)]
+		}
+	}
+	if len(columns) > 0 {
+		return columns[0]
+	}
+	return current
+}
+
+// nameMinWidth is the narrowest the name column may get before other
+// columns are hidden to make room
+const nameMinWidth = 12
+
+// visibleColumns drops columns from the end of the configured order until
+// the rest fit the available width, always keeping the first column
+func visibleColumns(columns []string, width int) []string {
+	visible := append([]string(nil), columns...)
+	for len(visible) > 1 && columnsWidth(visible) > width {
+		visible = visible[:len(visible)-1]
+	}
+	return visible
+}
+
+// columnsWidth returns the width the columns need, with the name column at
+// its minimum width
+func columnsWidth(columns []string) int {
+	total := 0
+	for _, column := range columns {
+		// Every cell is padded by one space on each side
+		w := columnSpecs[column].width
+		if column == config.ColumnName {
+			w = nameMinWidth
+		}
+		total += w + 2
+	}
+	return total
+}
+
+// tableColumns builds the table columns, giving the name column whatever
+// width the others leave over
+func tableColumns(columns []string, width int) []table.Column {
+	nameWidth := max(width-columnsWidth(columns)+nameMinWidth, nameMinWidth)
+
+	result := make([]table.Column, 0, len(columns))
+	for _, column := range columns {
+		spec := columnSpecs[column]
+		w := spec.width
+		if column == config.ColumnName {
+			w = nameWidth
+		}
+		result = append(result, table.Column{Title: spec.title, Width: w})
+	}
+	return result
+}
+
+// rowCells renders a row's values for the visible columns
+func rowCells(row deviceRow, columns []string, now time.Time) table.Row {
+	cells := make(table.Row, 0, len(columns))
+	for _, column := range columns {
+		switch column {
+		case config.ColumnName:
+			name := displayName(row.Device)
+			if name == "" {
+				name = "Unknown Device"
+			}
+			if name != row.Device.Name && row.Device.Name != "" {
+				// Keep the Bluetooth name visible beside the nickname
+				name += " (" + row.Device.Name + ")"
+			}
+			if row.Favorite {
+				name = ui.FavoriteMarker() + name
+			}
+			if row.Marked {
+				name = ui.MarkedMarker() + name
+			}
+			cells = append(cells, name)
+		case config.ColumnMAC:
+			cells = append(cells, row.Device.MacAddress)
+		case config.ColumnStatus:
+			cells = append(cells, row.Status)
+		case config.ColumnRSSI:
+			cells = append(cells, ui.SignalBars(row.RSSI))
+		case config.ColumnType:
+			cells = append(cells, orDash(row.Icon))
+		case config.ColumnBattery:
+			level := ""
+			if row.Device.HasBattery {
+				level = ui.BatteryLevel(row.Device.Battery, lowBattery(row.Device.Battery))
+			}
+			cells = append(cells, orDash(level))
+		case config.ColumnLastSeen:
+			cells = append(cells, lastSeen(row.LastSeen, now))
+		}
+	}
+	return cells
+}
+
+// lastSeen formats how long ago a device was last heard from
+func lastSeen(t, now time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	ago := now.Sub(t)
+	switch {
+	case ago < time.Minute:
+		return fmt.Sprintf("%ds ago", int(ago.Seconds()))
+	case ago < time.Hour:
+		return fmt.Sprintf("%dm ago", int(ago.Minutes()))
+	default:
+		return fmt.Sprintf("%dh ago", int(ago.Hours()))
+	}
+}
+
+// orDash returns s, or "-" when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// tableSize returns the table dimensions within the main pane
+func (m Model) tableSize() (int, int) {
+	pane := m.layout().Main
+	// Leave room for the title (2 rows) and help line (1 row)
+	return pane.Width, pane.Height - 3
+}
+
+// updateDeviceTable rebuilds the table rows, keeping the cursor on the same device
+func (m *Model) updateDeviceTable() {
+	if m.Layout != config.LayoutTable {
+		return
+	}
+
+	var selected string
+	if m.TableCursor < len(m.TableRows) {
+		selected = m.TableRows[m.TableCursor].Device.MacAddress
+	}
+
+	rows := m.deviceRows()
+	sortRows(rows, m.TableSort, m.TableDescending)
+	if config.Get().List.Favorites == config.FavoritesTop {
+		// Pinned favorites stay on top whichever column the table is sorted by
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].Favorite && !rows[j].Favorite })
+	}
+	m.TableRows = rows
+
+	width, height := m.tableSize()
+	visible := visibleColumns(m.TableColumns, width)
+
+	now := time.Now()
+	cells := make([]table.Row, len(rows))
+	cursor := 0
+	for i, row := range rows {
+		cells[i] = rowCells(row, visible, now)
+		if row.Device.MacAddress == selected {
+			cursor = i
+		}
+	}
+
+	columns := tableColumns(visible, width)
+	if m.Table.Columns() == nil {
+		m.Table = ui.NewTable(columns, nil, width, height)
+	} else {
+		// Columns must be replaced before rows so cells match the new layout
+		m.Table.SetRows(nil)
+		m.Table.SetColumns(columns)
+		m.Table.SetWidth(width)
+		m.Table.SetHeight(height)
+	}
+	m.TableCells = cells
+	m.setTableCursor(cursor)
+}
+
+// setTableCursor selects row i, scrolling just far enough to keep it on
+// screen. The table is only handed the rows that fit, so TableOffset is the
+// one record of which row is drawn first.
+func (m *Model) setTableCursor(i int) {
+	height := max(m.Table.Height(), 1)
+	m.TableCursor = max(min(i, len(m.TableCells)-1), 0)
+	if m.TableCursor < m.TableOffset {
+		m.TableOffset = m.TableCursor
+	}
+	if m.TableCursor >= m.TableOffset+height {
+		m.TableOffset = m.TableCursor - height + 1
+	}
+	m.TableOffset = max(min(m.TableOffset, len(m.TableCells)-height), 0)
+
+	end := min(m.TableOffset+height, len(m.TableCells))
+	m.Table.SetRows(m.TableCells[m.TableOffset:end])
+	m.Table.SetCursor(m.TableCursor - m.TableOffset)
+}
+
+// tableTitle returns the list title with the active sort column and direction
+func (m Model) tableTitle() string {
+	direction := "asc"
+	if m.TableDescending {
+		direction = "desc"
+	}
+	return fmt.Sprintf("%s - sorted by %s (%s)", m.listTitle(), columnSpecs[m.TableSort].title, direction)
+}
+
+// tableHelp renders the short help line shown under the table
+func (m Model) tableHelp() string {
+	h := help.New()
+	h.Styles = ui.HelpStyles()
+	h.Width = m.layout().Main.Width
+	if ui.Accessible() {
+		h.ShortSeparator = " | "
+	}
+	return h.ShortHelpView([]key.Binding{
+		m.Keys.Enter, m.Keys.Scan, m.Keys.ToggleView, m.Keys.SortColumn, m.Keys.SortOrder, m.Keys.Quit,
+	})
+}
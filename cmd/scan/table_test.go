@@ -0,0 +1,209 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSortRows(t *testing.T) {
+	now := time.Now()
+	rows := []deviceRow{
+		{Device: bluetooth.BluetoothDevice{Name: "Speaker", MacAddress: "CC:00:00:00:00:00"}, RSSI: -80, LastSeen: now.Add(-time.Minute)},
+		{Device: bluetooth.BluetoothDevice{Name: "keyboard", MacAddress: "AA:00:00:00:00:00", Connected: true, Paired: true, Battery: 15, HasBattery: true}},
+		{Device: bluetooth.BluetoothDevice{Name: "Headphones", MacAddress: "BB:00:00:00:00:00", Paired: true, Battery: 80, HasBattery: true}, RSSI: -50, LastSeen: now},
+	}
+
+	tests := []struct {
+		column     string
+		descending bool
+		expected   []string
+	}{
+		{config.ColumnName, false, []string{"Headphones", "keyboard", "Speaker"}},
+		{config.ColumnName, true, []string{"Headphones", "keyboard", "Speaker"}},
+		{config.ColumnMAC, false, []string{"keyboard", "Headphones", "Speaker"}},
+		{config.ColumnMAC, true, []string{"Speaker", "Headphones", "keyboard"}},
+		{config.ColumnStatus, false, []string{"keyboard", "Headphones", "Speaker"}},
+		{config.ColumnRSSI, false, []string{"Headphones", "Speaker", "keyboard"}},
+		{config.ColumnRSSI, true, []string{"Speaker", "Headphones", "keyboard"}},
+		{config.ColumnLastSeen, false, []string{"Headphones", "Speaker", "keyboard"}},
+		{config.ColumnBattery, false, []string{"keyboard", "Headphones", "Speaker"}},
+		{config.ColumnBattery, true, []string{"Speaker", "Headphones", "keyboard"}},
+	}
+
+	for _, tt := range tests {
+		sorted := append([]deviceRow(nil), rows...)
+		sortRows(sorted, tt.column, tt.descending)
+
+		names := make([]string, len(sorted))
+		for i, row := range sorted {
+			names[i] = row.Device.Name
+		}
+		if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("sort by %s (descending %v): expected %v, got %v", tt.column, tt.descending, tt.expected, names)
+		}
+	}
+}
+
+func TestSortRowsUnknownRSSI(t *testing.T) {
+	rows := []deviceRow{
+		{Device: bluetooth.BluetoothDevice{Name: "Mouse", Paired: true}},
+		{Device: bluetooth.BluetoothDevice{Name: "Speaker"}, RSSI: -85},
+		{Device: bluetooth.BluetoothDevice{Name: "Keyboard", Paired: true}},
+		{Device: bluetooth.BluetoothDevice{Name: "Headphones"}, RSSI: -40},
+		{Device: bluetooth.BluetoothDevice{Name: "Watch"}, RSSI: -60},
+	}
+
+	tests := []struct {
+		descending bool
+		expected   []string
+	}{
+		{false, []string{"Headphones", "Watch", "Speaker", "Keyboard", "Mouse"}},
+		{true, []string{"Speaker", "Watch", "Headphones", "Keyboard", "Mouse"}},
+	}
+
+	for _, tt := range tests {
+		sorted := append([]deviceRow(nil), rows...)
+		sortRows(sorted, config.ColumnRSSI, tt.descending)
+
+		names := make([]string, len(sorted))
+		for i, row := range sorted {
+			names[i] = row.Device.Name
+		}
+		if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("sort by signal (descending %v): expected %v, got %v", tt.descending, tt.expected, names)
+		}
+	}
+}
+
+func TestNextColumn(t *testing.T) {
+	columns := []string{config.ColumnName, config.ColumnStatus, config.ColumnRSSI}
+
+	if got := nextColumn(columns, config.ColumnName); got != config.ColumnStatus {
+		t.Errorf("Expected status after name, got %q", got)
+	}
+	if got := nextColumn(columns, config.ColumnRSSI); got != config.ColumnName {
+		t.Errorf("Expected wrap around to name, got %q", got)
+	}
+	if got := nextColumn(columns, config.ColumnMAC); got != config.ColumnName {
+		t.Errorf("Expected hidden sort column to reset to name, got %q", got)
+	}
+}
+
+func TestVisibleColumns(t *testing.T) {
+	all := config.AllColumns
+
+	if got := visibleColumns(all, 200); len(got) != len(all) {
+		t.Errorf("Expected all columns on a wide table, got %v", got)
+	}
+
+	got := visibleColumns(all, 76)
+	expected := []string{config.ColumnName, config.ColumnMAC, config.ColumnStatus, config.ColumnRSSI, config.ColumnType}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	if got := visibleColumns(all, 5); len(got) != 1 || got[0] != config.ColumnName {
+		t.Errorf("Expected only the first column, got %v", got)
+	}
+}
+
+func TestRowCells(t *testing.T) {
+	now := time.Now()
+	row := deviceRow{
+		Device:   bluetooth.BluetoothDevice{MacAddress: "AA:BB:CC:DD:EE:FF"},
+		Status:   statusDiscovered,
+		LastSeen: now.Add(-90 * time.Second),
+	}
+	columns := []string{config.ColumnName, config.ColumnMAC, config.ColumnType, config.ColumnBattery, config.ColumnLastSeen}
+
+	cells := rowCells(row, columns, now)
+	expected := []string{"Unknown Device", "AA:BB:CC:DD:EE:FF", "-", "-", "1m ago"}
+	if strings.Join(cells, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, cells)
+	}
+}
+
+func TestLastSeen(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		t        time.Time
+		expected string
+	}{
+		{time.Time{}, "-"},
+		{now.Add(-5 * time.Second), "5s ago"},
+		{now.Add(-3 * time.Minute), "3m ago"},
+		{now.Add(-2 * time.Hour), "2h ago"},
+	}
+
+	for _, tt := range tests {
+		if got := lastSeen(tt.t, now); got != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, got)
+		}
+	}
+}
+
+func TestToggleTableView(t *testing.T) {
+	model := viewFixture()
+	if model.Layout != config.LayoutList {
+		t.Fatalf("Expected list layout by default, got %q", model.Layout)
+	}
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if model.Layout != config.LayoutTable {
+		t.Fatalf("Expected table layout after toggle, got %q", model.Layout)
+	}
+	if len(model.Table.Rows()) != 3 {
+		t.Errorf("Expected 3 table rows, got %d", len(model.Table.Rows()))
+	}
+	if !strings.Contains(model.View(), "sorted by Status (asc)") {
+		t.Error("Expected table title to show the sort column")
+	}
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if model.Layout != config.LayoutList {
+		t.Errorf("Expected list layout after second toggle, got %q", model.Layout)
+	}
+}
+
+func TestTableSortKeys(t *testing.T) {
+	model := viewFixture()
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+
+	expected := nextColumn(model.TableColumns, model.TableSort)
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	if model.TableSort != expected {
+		t.Errorf("Expected sort to advance to the next column, got %q", model.TableSort)
+	}
+
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("O")})
+	if !model.TableDescending {
+		t.Error("Expected sort order to flip to descending")
+	}
+}
+
+func TestTableSelectedDevice(t *testing.T) {
+	model := viewFixture()
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+
+	// Rows sort by status: Keychron (connected), Bose (paired), Living Room TV
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyDown})
+	device, ok := model.selectedDevice()
+	if !ok {
+		t.Fatal("Expected a selected device")
+	}
+	if device.Name != "Bose NC 700 Headphones" {
+		t.Errorf("Expected Bose NC 700 Headphones to be selected, got %q", device.Name)
+	}
+
+	// The cursor follows the device when the sort order changes
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("O")})
+	device, _ = model.selectedDevice()
+	if device.Name != "Bose NC 700 Headphones" {
+		t.Errorf("Expected selection to stay on Bose NC 700 Headphones, got %q", device.Name)
+	}
+}
@@ -0,0 +1,152 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/notify"
+	"btui/internal/scene"
+	"btui/internal/ui"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Toasts above the status line: how many are shown at once and for how long
+const (
+	toastLimit    = 3
+	toastLifetime = 5 * time.Second
+)
+
+// toast shows a transient notification above the status line, making room
+// for it in the main pane, and returns the command that dismisses it
+func (m *Model) toast(severity ui.Severity, message string) tea.Cmd {
+	cmd := m.Toasts.Push(severity, message)
+	m.resize()
+	return cmd
+}
+
+// expireToast dismisses a toast whose time is up
+func (m Model) expireToast(msg ui.ToastExpiredMsg) (tea.Model, tea.Cmd) {
+	if m.Toasts.Expire(msg.ID) {
+		m.resize()
+	}
+	return m, nil
+}
+
+// scanStatusText is the status line while nothing else is going on, which
+// says whether discovery is running
+func (m Model) scanStatusText() string {
+	switch m.ScanState {
+	case ScanActive:
+		return "Scanning for devices... Press '" + m.Keys.Scan.Help().Key + "' to stop"
+	case ScanStarting:
+		return "Starting real-time device discovery..."
+	case ScanStopping:
+		return "Stopping discovery..."
+	default:
+		return ""
+	}
+}
+
+// settleStatus puts the status line back to what is still going on once a
+// result has gone to a toast: the running batch, another operation the user
+// started, or else the scan state
+func (m *Model) settleStatus() {
+	m.StatusMessage = ""
+	if m.Batch != nil {
+		m.StatusMessage = m.Batch.progress()
+		return
+	}
+	var pending []bluetooth.Operation
+	for id, op := range m.Operations {
+		if m.Requested[id] {
+			pending = append(pending, op)
+		}
+	}
+	if len(pending) > 0 {
+		op := slices.MinFunc(pending, func(a, b bluetooth.Operation) int { return a.ID - b.ID })
+		m.StatusMessage = m.startText(op)
+	}
+}
+
+// severity rates the outcome of a finished batch
+func (b *batch) severity() ui.Severity {
+	severity := ui.SeveritySuccess
+	for _, result := range b.Results {
+		switch {
+		case result.Cancelled:
+			severity = max(severity, ui.SeverityWarning)
+		case !result.Success:
+			return ui.SeverityError
+		}
+	}
+	return severity
+}
+
+// sceneSeverity rates the outcome of a finished scene
+func sceneSeverity(steps []scene.Progress) ui.Severity {
+	if slices.ContainsFunc(steps, func(p scene.Progress) bool { return p.Status == scene.Failed }) {
+		return ui.SeverityError
+	}
+	return ui.SeveritySuccess
+}
+
+// connectionChanges toasts and logs paired devices that connected or
+// disconnected since the previous device list without btui having asked
+// them to, such as headphones switched on by hand
+func (m *Model) connectionChanges(previous, current []bluetooth.BluetoothDevice) tea.Cmd {
+	connected := make(map[string]bool, len(previous))
+	for _, device := range previous {
+		connected[device.MacAddress] = device.Connected
+	}
+	var cmds []tea.Cmd
+	for _, device := range current {
+		was, known := connected[device.MacAddress]
+		if !known || was == device.Connected || m.expectedChange(device) {
+			continue
+		}
+		message := displayName(device) + " disconnected"
+		if device.Connected {
+			message = displayName(device) + " connected"
+		}
+		m.logActivity(ui.SeverityInfo, device.MacAddress, message)
+		cmds = append(cmds, m.toast(ui.SeverityInfo, message), m.notifyDesktop(connectionEvent(device)))
+	}
+	return tea.Batch(cmds...)
+}
+
+// connectionEvent describes a device's new connection state for desktop
+// notifications
+func connectionEvent(device bluetooth.BluetoothDevice) notify.Event {
+	kind := notify.EventDisconnected
+	if device.Connected {
+		kind = notify.EventConnected
+	}
+	return notify.Event{Kind: kind, MAC: device.MacAddress, Name: displayName(device)}
+}
+
+// notifyDesktop returns a command raising a desktop notification for an
+// event, as far as the notification settings allow
+func (m Model) notifyDesktop(e notify.Event) tea.Cmd {
+	if m.Notify == nil {
+		return nil
+	}
+	dispatcher := m.Notify
+	return func() tea.Msg {
+		if _, err := dispatcher.Dispatch(e); err != nil {
+			return NotifyFailedMsg{Err: err}
+		}
+		return nil
+	}
+}
+
+// expectedChange reports whether a device's new connection state is down to
+// btui: an operation is still under way on it, or one finished since the
+// previous device list
+func (m Model) expectedChange(device bluetooth.BluetoothDevice) bool {
+	if m.busy(device) {
+		return true
+	}
+	result, ok := m.LastResult[device.MacAddress]
+	return ok && !result.At.Before(m.DevicesAt)
+}
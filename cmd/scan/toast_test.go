@@ -0,0 +1,149 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/notify"
+	"btui/internal/ui"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// lastToast returns the message of the newest toast shown, if any
+func lastToast(m Model) string {
+	if len(m.Toasts.Items) == 0 {
+		return ""
+	}
+	return m.Toasts.Items[len(m.Toasts.Items)-1].Message
+}
+
+// relist feeds the model a device list with the given devices connected
+func relist(m Model, connected ...string) Model {
+	msg := bluetooth.DevicesMsg{Devices: []string{
+		"Device 4C:87:5D:28:86:DD Bose NC 700 Headphones",
+		"Device DC:2C:26:09:D0:0C Keychron K4",
+	}}
+	for _, mac := range connected {
+		msg.ConnectedDevices = append(msg.ConnectedDevices, "Device "+mac+" x")
+	}
+	m, _ = updateModel(m, msg)
+	return m
+}
+
+func TestExternalConnectionToasts(t *testing.T) {
+	model := viewFixture()
+	if len(model.Toasts.Items) != 0 {
+		t.Fatalf("Expected no toasts for the first device list, got %v", model.Toasts.Items)
+	}
+
+	// The headphones connect and the keyboard drops without btui asking
+	model = relist(model, bose.MacAddress)
+	messages := make([]string, len(model.Toasts.Items))
+	for i, toast := range model.Toasts.Items {
+		messages[i] = toast.Message
+	}
+	if strings.Join(messages, "\n") != "Bose NC 700 Headphones connected\nKeychron K4 disconnected" {
+		t.Errorf("Unexpected toasts %q", messages)
+	}
+	if last := model.Activity[len(model.Activity)-1]; last.Message != "Keychron K4 disconnected" {
+		t.Errorf("Expected the change logged, got %+v", last)
+	}
+}
+
+func TestOwnConnectionNotToasted(t *testing.T) {
+	model := keys(selectDevice(t, viewFixture(), "Bose NC 700 Headphones"), "c")
+	model = untilIdle(model)
+	if lastToast(model) != "Successfully connected to Bose NC 700 Headphones" {
+		t.Fatalf("Unexpected result %q", lastToast(model))
+	}
+
+	// The relist after the connect shows the change btui made
+	model = relist(model, bose.MacAddress, keychron.MacAddress)
+	if got := len(model.Toasts.Items); got != 1 {
+		t.Errorf("Expected only the result toasted, got %v", model.Toasts.Items)
+	}
+}
+
+func TestDiscoveryErrorToast(t *testing.T) {
+	model := viewFixture()
+	model.StatusMessage = ""
+	model.ScanState = ScanActive
+	model, _ = updateModel(model, bluetooth.DiscoveryUpdateMsg{Err: errors.New("adapter gone")})
+
+	if lastToast(model) != "Discovery error: adapter gone" || model.Toasts.Items[0].Severity != ui.SeverityError {
+		t.Errorf("Unexpected toasts %v", model.Toasts.Items)
+	}
+	// The status line keeps saying discovery is running
+	view := model.View()
+	if !strings.Contains(view, "Discovery error: adapter gone") || !strings.Contains(view, "Scanning for devices... Press 's' to stop") {
+		t.Errorf("Expected the toast above the scan state, got:\n%s", view)
+	}
+}
+
+func TestToastsExpire(t *testing.T) {
+	model := viewFixture()
+	height := model.List.Height()
+	model, cmd := updateModel(model, bluetooth.DiscoveryUpdateMsg{Err: errors.New("adapter gone")})
+	if cmd == nil || model.List.Height() != height-1 {
+		t.Fatalf("Expected the list to make room for the toast, got height %d", model.List.Height())
+	}
+
+	model, _ = updateModel(model, ui.ToastExpiredMsg{ID: model.Toasts.Items[0].ID})
+	if len(model.Toasts.Items) != 0 || model.List.Height() != height {
+		t.Errorf("Expected the toast gone and the list back to height %d, got %d", height, model.List.Height())
+	}
+}
+
+func TestToastsAnnounced(t *testing.T) {
+	t.Cleanup(func() {
+		ui.SetAccessible(false)
+		ui.ApplyTheme(ui.Presets[ui.ThemeDark])
+	})
+	ui.SetAccessible(true)
+
+	model := viewFixture()
+	_, cmd := updateModel(model, bluetooth.DiscoveryUpdateMsg{Err: errors.New("adapter gone")})
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("Expected the expiry and the announcement, got %T", cmd())
+	}
+}
+
+// desktop stands in for the desktop notification service
+type desktop struct {
+	sent []notify.Notification
+	err  error
+}
+
+func (d *desktop) Notify(n notify.Notification) error {
+	d.sent = append(d.sent, n)
+	return d.err
+}
+
+func TestExternalConnectionNotifiesDesktop(t *testing.T) {
+	cfg := config.Default().Notifications
+	cfg.Enabled = true
+	service := &desktop{}
+	model := viewFixture()
+	model.Notify = notify.NewDispatcher(service, cfg)
+
+	if msg := model.notifyDesktop(connectionEvent(keychron))(); msg != nil {
+		t.Fatalf("Unexpected message %v", msg)
+	}
+	if len(service.sent) != 1 || service.sent[0].Summary != "Keychron K4 connected" {
+		t.Errorf("Unexpected notifications %+v", service.sent)
+	}
+
+	// A notification that cannot be shown ends up in the activity log
+	service.err = errors.New("no notification service")
+	disconnected := keychron
+	disconnected.Connected = false
+	msg := model.notifyDesktop(connectionEvent(disconnected))()
+	model, _ = updateModel(model, msg)
+	if last := model.Activity[len(model.Activity)-1]; last.Severity != ui.SeverityWarning || !strings.Contains(last.Message, "no notification service") {
+		t.Errorf("Expected the failure logged, got %+v", last)
+	}
+}
@@ -1,6 +1,6 @@
 package scan
 
-import "btui/internal/bluetooth"
+import "time"
 
 // ScanState represents the current scanning state
 type ScanState int
@@ -28,12 +28,19 @@ func (s ScanState) String() string {
 	}
 }
 
-// ConnectingMsg is sent when starting to connect to a device
-type ConnectingMsg struct {
-	Device bluetooth.BluetoothDevice
+// AutoScanMsg is sent on startup to begin discovery when auto_scan is enabled
+type AutoScanMsg struct{}
+
+// PresenceTickMsg is sent periodically to check whether devices have left
+// their presence rules
+type PresenceTickMsg time.Time
+
+// NotifyFailedMsg is sent when a desktop notification could not be shown
+type NotifyFailedMsg struct {
+	Err error
 }
 
-// DisconnectingMsg is sent when starting to disconnect from a device
-type DisconnectingMsg struct {
-	Device bluetooth.BluetoothDevice
+// StateSavedMsg is sent when the state file has been written
+type StateSavedMsg struct {
+	Err error
 }
@@ -2,66 +2,115 @@ package scan
 
 import (
 	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/scene"
+	"btui/internal/state"
 	"btui/internal/ui"
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 // deviceToListItem converts a BluetoothDevice to a device list item
-func deviceToListItem(d bluetooth.BluetoothDevice, connectingTo *bluetooth.BluetoothDevice, disconnectingFrom *bluetooth.BluetoothDevice) list.Item {
-	title := d.Name
-	if title == "" {
-		title = "Unknown Device"
-	}
+func deviceToListItem(d bluetooth.BluetoothDevice, statuses map[string]string) list.Item {
+	title := itemTitle(d)
 
 	// Create status description with device status and MAC address, applying colors
-	// Priority: Connecting/Disconnecting > Connected > Paired > Discovered
-	var status string
-	if connectingTo != nil && connectingTo.MacAddress == d.MacAddress {
-		status = ui.ConnectingStatusStyle.Render("Connecting...")
-	} else if disconnectingFrom != nil && disconnectingFrom.MacAddress == d.MacAddress {
-		status = ui.DisconnectingStatusStyle.Render("Disconnecting...")
-	} else if d.Connected {
-		status = ui.ConnectedStatusStyle.Render("Connected")
-	} else if d.Paired {
-		status = ui.PairedStatusStyle.Render("Paired")
-	} else {
-		status = ui.DiscoveredStatusStyle.Render("Discovered")
-	}
+	// Priority: operation in progress > Connected > Paired > Discovered
+	status := renderStatus(deviceStatus(d, statuses))
 
-	// Description includes colored status and muted MAC address
-	description := status + " • " + ui.MacAddressStyle.Render(d.MacAddress)
+	// Description includes colored status, battery, any metadata and muted MAC address
+	parts := append([]string{status}, batteryParts(d)...)
+	parts = append(parts, metaParts(d)...)
+	description := ui.JoinDescription(append(parts, ui.MacAddressStyle.Render(d.MacAddress))...)
 
 	return ui.NewDeviceItem(title, description, d)
 }
 
-// discoveredDeviceToListItem converts a DiscoveredDevice to a device list item
-func discoveredDeviceToListItem(d bluetooth.DiscoveredDevice, connectingTo *bluetooth.BluetoothDevice, disconnectingFrom *bluetooth.BluetoothDevice) list.Item {
-	title := d.Name
+// itemTitle returns a device's list title: its nickname or name, starred when
+// it is a favorite
+func itemTitle(d bluetooth.BluetoothDevice) string {
+	title := state.Get().DisplayName(d.MacAddress, d.Name)
 	if title == "" {
 		title = "Unknown Device"
 	}
+	if state.Get().IsFavorite(d.MacAddress) {
+		title = ui.FavoriteMarker() + title
+	}
+	return title
+}
 
-	// Create description with colored status, RSSI, and MAC address
-	// Priority: Connecting/Disconnecting > Discovered
-	var status string
-	if connectingTo != nil && connectingTo.MacAddress == d.MacAddress {
-		status = ui.ConnectingStatusStyle.Render("Connecting...")
-	} else if disconnectingFrom != nil && disconnectingFrom.MacAddress == d.MacAddress {
-		status = ui.DisconnectingStatusStyle.Render("Disconnecting...")
-	} else {
-		status = ui.DiscoveredStatusStyle.Render("Discovered")
+// Device status labels, in display priority order
+const (
+	statusConnecting    = "Connecting..."
+	statusDisconnecting = "Disconnecting..."
+	statusTrusting      = "Trusting..."
+	statusRemoving      = "Removing..."
+	statusQueued        = "Queued"
+	statusDone          = "Done"
+	statusFailed        = "Failed"
+	statusCancelled     = "Cancelled"
+	statusConnected     = "Connected"
+	statusPaired        = "Paired"
+	statusDiscovered    = "Discovered"
+)
+
+// deviceStatus returns the status label for a device. Statuses of devices
+// with an operation in progress, keyed by MAC address, take priority over
+// the connection state.
+func deviceStatus(d bluetooth.BluetoothDevice, statuses map[string]string) string {
+	if status, ok := statuses[d.MacAddress]; ok {
+		return status
 	}
+	switch {
+	case d.Connected:
+		return statusConnected
+	case d.Paired:
+		return statusPaired
+	default:
+		return statusDiscovered
+	}
+}
 
-	description := status
+// renderStatus colours a status label with its status style
+func renderStatus(status string) string {
+	switch status {
+	case statusConnecting, statusTrusting:
+		return ui.ConnectingStatusStyle.Render(status)
+	case statusDisconnecting, statusRemoving:
+		return ui.DisconnectingStatusStyle.Render(status)
+	case statusFailed:
+		return ui.ErrorStyle().Render(status)
+	case statusConnected, statusDone:
+		return ui.ConnectedStatusStyle.Render(status)
+	case statusPaired:
+		return ui.PairedStatusStyle.Render(status)
+	default:
+		return ui.DiscoveredStatusStyle.Render(status)
+	}
+}
+
+// discoveredDeviceToListItem converts a DiscoveredDevice to a device list item
+func discoveredDeviceToListItem(d bluetooth.DiscoveredDevice, statuses map[string]string) list.Item {
+	title := itemTitle(d.BluetoothDevice)
+
+	// Create description with colored status, RSSI, and MAC address
+	// Priority: operation in progress > Discovered
+	status := renderStatus(deviceStatus(d.BluetoothDevice, statuses))
+
+	parts := []string{status}
 	if d.RSSI != 0 {
-		description += " • " + ui.RSSIStyle.Render(fmt.Sprintf("RSSI: %d", d.RSSI))
+		parts = append(parts, ui.RSSIStyle.Render(fmt.Sprintf("RSSI: %d", d.RSSI)))
 	}
-	description += " • " + ui.MacAddressStyle.Render(d.MacAddress)
+	parts = append(parts, batteryParts(d.BluetoothDevice)...)
+	parts = append(parts, metaParts(d.BluetoothDevice)...)
+	parts = append(parts, ui.MacAddressStyle.Render(d.MacAddress))
+	description := ui.JoinDescription(parts...)
 
 	return ui.NewDeviceItem(title, description, d.BluetoothDevice)
 }
@@ -83,25 +132,25 @@ func devicesToListItems(deviceLines []string, connectedDeviceLines []string) []l
 		device := bluetooth.ParseDeviceLine(line, connectedMacs)
 		// Check if device is paired by checking if it appears in regular device list
 		device.Paired = true // All devices from bluetoothctl devices are paired
-		items[i] = deviceToListItem(device, nil, nil)
+		items[i] = deviceToListItem(device, nil)
 	}
 	return items
 }
 
 // combineDevicesToListItems combines paired and discovered devices into list items
-func combineDevicesToListItems(pairedDevices []bluetooth.BluetoothDevice, discoveredDevices []bluetooth.DiscoveredDevice, connectingTo *bluetooth.BluetoothDevice, disconnectingFrom *bluetooth.BluetoothDevice) []list.Item {
+func combineDevicesToListItems(pairedDevices []bluetooth.BluetoothDevice, discoveredDevices []bluetooth.DiscoveredDevice, statuses map[string]string) []list.Item {
 	// Create a map to avoid duplicates (prioritize paired devices)
 	deviceMap := make(map[string]list.Item)
 
 	// Add paired devices first (they take priority)
 	for _, device := range pairedDevices {
-		deviceMap[device.MacAddress] = deviceToListItem(device, connectingTo, disconnectingFrom)
+		deviceMap[device.MacAddress] = deviceToListItem(device, statuses)
 	}
 
 	// Add discovered devices (only if not already paired)
 	for _, device := range discoveredDevices {
 		if _, exists := deviceMap[device.MacAddress]; !exists {
-			deviceMap[device.MacAddress] = discoveredDeviceToListItem(device, connectingTo, disconnectingFrom)
+			deviceMap[device.MacAddress] = discoveredDeviceToListItem(device, statuses)
 		}
 	}
 
@@ -124,32 +173,22 @@ func combineDevicesToListItems(pairedDevices []bluetooth.BluetoothDevice, discov
 		}
 	}
 
-	// Sort connected devices alphabetically by name
-	sort.Slice(connectedItems, func(i, j int) bool {
-		itemI := connectedItems[i].(ui.DeviceItem)
-		itemJ := connectedItems[j].(ui.DeviceItem)
-		deviceI := itemI.Device().(bluetooth.BluetoothDevice)
-		deviceJ := itemJ.Device().(bluetooth.BluetoothDevice)
-		return strings.ToLower(deviceI.Name) < strings.ToLower(deviceJ.Name)
-	})
-
-	// Sort paired devices alphabetically by name
-	sort.Slice(pairedItems, func(i, j int) bool {
-		itemI := pairedItems[i].(ui.DeviceItem)
-		itemJ := pairedItems[j].(ui.DeviceItem)
-		deviceI := itemI.Device().(bluetooth.BluetoothDevice)
-		deviceJ := itemJ.Device().(bluetooth.BluetoothDevice)
-		return strings.ToLower(deviceI.Name) < strings.ToLower(deviceJ.Name)
-	})
-
-	// Sort discovered devices alphabetically by name
-	sort.Slice(discoveredItems, func(i, j int) bool {
-		itemI := discoveredItems[i].(ui.DeviceItem)
-		itemJ := discoveredItems[j].(ui.DeviceItem)
-		deviceI := itemI.Device().(bluetooth.BluetoothDevice)
-		deviceJ := itemJ.Device().(bluetooth.BluetoothDevice)
-		return strings.ToLower(deviceI.Name) < strings.ToLower(deviceJ.Name)
-	})
+	// Sort each tier using the configured order
+	rssi := make(map[string]int, len(discoveredDevices))
+	for _, device := range discoveredDevices {
+		if device.RSSI != 0 {
+			rssi[device.MacAddress] = device.RSSI
+		}
+	}
+	cfg := config.Get()
+	sortDeviceItems(connectedItems, cfg.List.Sort, rssi)
+	sortDeviceItems(pairedItems, cfg.List.Sort, rssi)
+	sortDeviceItems(discoveredItems, cfg.List.Sort, rssi)
+	if cfg.List.Favorites == config.FavoritesTier {
+		favoritesFirst(connectedItems)
+		favoritesFirst(pairedItems)
+		favoritesFirst(discoveredItems)
+	}
 
 	// Combine: connected first, then paired, then discovered
 	items := make([]list.Item, 0, len(connectedItems)+len(pairedItems)+len(discoveredItems))
@@ -157,20 +196,41 @@ func combineDevicesToListItems(pairedDevices []bluetooth.BluetoothDevice, discov
 	items = append(items, pairedItems...)
 	items = append(items, discoveredItems...)
 
+	// Pinned favorites go above every tier, keeping their tier order
+	if cfg.List.Favorites == config.FavoritesTop {
+		favoritesFirst(items)
+	}
+
 	return items
 }
 
-// updateDeviceList updates or creates the device list with proper dimensions and preserves position
-func (m *Model) updateDeviceList(items []list.Item) {
-	width := m.Width
-	height := m.Height
-	if width == 0 {
-		width = 80
-	}
-	if height == 0 {
-		height = 14
-	}
+// sortDeviceItems sorts device list items in place by name, signal strength or
+// MAC address. Ties and devices without a known RSSI fall back to name order.
+func sortDeviceItems(items []list.Item, order string, rssi map[string]int) {
+	sort.SliceStable(items, func(i, j int) bool {
+		deviceI := items[i].(ui.DeviceItem).Device().(bluetooth.BluetoothDevice)
+		deviceJ := items[j].(ui.DeviceItem).Device().(bluetooth.BluetoothDevice)
+		switch order {
+		case config.SortRSSI:
+			rssiI, okI := rssi[deviceI.MacAddress]
+			rssiJ, okJ := rssi[deviceJ.MacAddress]
+			if okI != okJ {
+				return okI
+			}
+			if rssiI != rssiJ {
+				return rssiI > rssiJ
+			}
+		case config.SortMAC:
+			if deviceI.MacAddress != deviceJ.MacAddress {
+				return deviceI.MacAddress < deviceJ.MacAddress
+			}
+		}
+		return strings.ToLower(displayName(deviceI)) < strings.ToLower(displayName(deviceJ))
+	})
+}
 
+// listTitle returns the list title with the current scan state
+func (m Model) listTitle() string {
 	// Create clean title without status messages (status messages now shown below list)
 	title := "Bluetooth Devices"
 	if m.ScanState == ScanActive {
@@ -178,20 +238,45 @@ func (m *Model) updateDeviceList(items []list.Item) {
 	} else if m.ScanState == ScanStopped {
 		title += " - Ready"
 	}
+	if m.FavoritesOnly {
+		title += " - Favorites"
+	}
+	if hidden := len(m.hiddenDevices()); hidden > 0 {
+		title += fmt.Sprintf(" (%d hidden)", hidden)
+	}
+	if len(m.Marked) > 0 {
+		title += fmt.Sprintf(" (%d marked)", len(m.Marked))
+	}
+	if q, err := m.activeQuery(); err == nil && !q.Empty() {
+		title += " [" + q.String() + "]"
+	}
+	return title
+}
+
+// updateDeviceList updates or creates the device list with proper dimensions and preserves position
+func (m *Model) updateDeviceList(items []list.Item) {
+	pane := m.layout().Main
+	title := m.listTitle()
 
 	if m.List.Items() == nil {
 		// Create new list if it doesn't exist yet
-		// Account for padding (2 rows) + status line (1 row) = 3 rows total
-		m.List = ui.NewListWithKeys(items, title, width-4, height-3, scanKeys)
+		m.List = ui.NewListWithKeys(items, title, pane.Width, pane.Height, m.Keys)
+		m.List.Filter = queryFilter(m.queryDevices(items))
+		m.applyListKeys()
 	} else {
 		// Preserve cursor position during updates
 		currentIndex := m.List.Index()
 		
-		// Update existing list
-		m.List.SetItems(items)
-		m.List.SetWidth(width - 4)   // Account for padding (4 columns left/right)
-		m.List.SetHeight(height - 3) // Account for padding (2 rows) + status line (1 row)
-		m.List.Title = title         // Update title with current status
+		// Update existing list; the filter must see the new items before
+		// SetItems re-applies an active query
+		m.List.Filter = queryFilter(m.queryDevices(items))
+		if filterCmd := m.List.SetItems(items); filterCmd != nil {
+			// Matching is cheap, so apply it now rather than showing an empty
+			// list until the filter command comes back
+			m.List, _ = m.List.Update(filterCmd())
+		}
+		m.List.SetSize(pane.Width, pane.Height)
+		m.List.Title = title // Update title with current status
 		
 		// Restore cursor position if it's still valid
 		if currentIndex < len(items) && currentIndex >= 0 {
@@ -200,27 +285,304 @@ func (m *Model) updateDeviceList(items []list.Item) {
 	}
 }
 
+// refreshDevices rebuilds the list and table from the current device state
+func (m *Model) refreshDevices() {
+	items := combineDevicesToListItems(m.PairedDevices, m.DiscoveredDevices, m.operationStatuses())
+	items = withoutHidden(items, m.hiddenDevices())
+	if m.FavoritesOnly {
+		items = onlyFavorites(items)
+	}
+	items = m.markItems(items)
+	m.updateDeviceList(items)
+	m.updateDeviceTable()
+}
+
+// selectedDevice returns the device under the cursor in the active view
+func (m Model) selectedDevice() (bluetooth.BluetoothDevice, bool) {
+	if m.Loading {
+		return bluetooth.BluetoothDevice{}, false
+	}
+	if m.Layout == config.LayoutTable {
+		if m.TableCursor >= len(m.TableRows) {
+			return bluetooth.BluetoothDevice{}, false
+		}
+		return m.TableRows[m.TableCursor].Device, true
+	}
+	if len(m.List.Items()) == 0 {
+		return bluetooth.BluetoothDevice{}, false
+	}
+	if deviceItem, ok := m.List.SelectedItem().(ui.DeviceItem); ok {
+		if device, ok := deviceItem.Device().(bluetooth.BluetoothDevice); ok {
+			return device, true
+		}
+	}
+	return bluetooth.BluetoothDevice{}, false
+}
+
+// requestDeviceInfo looks up properties for devices seen for the first time.
+// An empty entry marks a lookup as pending so it is only requested once; a
+// failed lookup drops it again so the next refresh retries.
+func (m *Model) requestDeviceInfo(macs []string) tea.Cmd {
+	if m.DeviceInfo == nil {
+		m.DeviceInfo = make(map[string]bluetooth.DeviceInfo)
+	}
+	var cmds []tea.Cmd
+	for _, mac := range macs {
+		if _, known := m.DeviceInfo[mac]; !known {
+			m.DeviceInfo[mac] = bluetooth.DeviceInfo{}
+			cmds = append(cmds, bluetooth.DeviceInfoCmd(mac))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// smartConnect disconnects the selected device if it is connected and
+// connects to it otherwise. A busy device gets the opposite of whatever its
+// queued operations leave it in. It reports false when nothing was started.
+func (m Model) smartConnect() (tea.Model, tea.Cmd, bool) {
+	device, ok := m.selectedDevice()
+	if !ok {
+		return m, nil, false
+	}
+	if m.willConnect(device) {
+		next, cmd := m.disconnectDevice(device)
+		return next, cmd, true
+	}
+	next, cmd := m.connectDevice(device)
+	return next, cmd, true
+}
+
+// connectDevice queues connecting to a device and shows the connecting status
+func (m Model) connectDevice(device bluetooth.BluetoothDevice) (tea.Model, tea.Cmd) {
+	op, cmd := m.startOperation(device, opConnect)
+	m.StatusMessage = m.startText(op)
+	// Immediately refresh UI to show connecting status
+	m.refreshDevices()
+	return m, cmd
+}
+
+// disconnectDevice queues disconnecting from a device and shows the disconnecting status
+func (m Model) disconnectDevice(device bluetooth.BluetoothDevice) (tea.Model, tea.Cmd) {
+	op, cmd := m.startOperation(device, opDisconnect)
+	m.StatusMessage = m.startText(op)
+	// Immediately refresh UI to show disconnecting status
+	m.refreshDevices()
+	return m, cmd
+}
+
+// toggleScan starts discovery when stopped and stops it when active
+func (m Model) toggleScan() (tea.Model, tea.Cmd) {
+	switch m.ScanState {
+	case ScanStopped:
+		m.ScanState = ScanStarting
+		m.StatusMessage = "Starting real-time device discovery..."
+		if err := m.DiscoveryScanner.StartDiscovery(); err != nil {
+			m.StatusMessage = "Failed to start discovery: " + err.Error()
+			m.ScanState = ScanStopped
+			m.logActivity(ui.SeverityError, "", m.StatusMessage)
+		} else {
+			m.ScanState = ScanActive
+			m.logActivity(ui.SeverityInfo, "", "Discovery started")
+			m.StatusMessage = m.scanStatusText()
+			// Update title to reflect new state
+			if m.List.Items() != nil {
+				m.refreshDevices()
+			}
+			return m, bluetooth.DiscoveryTickCmd(m.DiscoveryScanner)
+		}
+	case ScanActive:
+		m.ScanState = ScanStopping
+		m.StatusMessage = "Stopping discovery..."
+		if err := m.DiscoveryScanner.StopDiscovery(); err != nil {
+			m.StatusMessage = "Failed to stop discovery: " + err.Error()
+			m.logActivity(ui.SeverityError, "", m.StatusMessage)
+		} else {
+			m.ScanState = ScanStopped
+			m.StatusMessage = "Discovery stopped"
+			m.logActivity(ui.SeverityInfo, "", m.StatusMessage)
+		}
+		// Update title to reflect new state
+		if m.List.Items() != nil {
+			m.refreshDevices()
+		}
+	}
+	return m, nil
+}
+
+// applyListKeys makes the list's built-in navigation and quit bindings follow
+// the configured key map so defaults cannot shadow rebound actions. Paging,
+// jump and force quit keys that an action claims are taken from the list.
+func (m *Model) applyListKeys() {
+	m.List.KeyMap.CursorUp = mergeBindings(m.Keys.Up, m.Keys.ViUp)
+	m.List.KeyMap.CursorDown = mergeBindings(m.Keys.Down, m.Keys.ViDown)
+	m.List.KeyMap.Quit = m.Keys.Quit
+
+	claimed := make(map[string]bool)
+	for _, group := range m.Keys.FullHelp() {
+		for _, b := range group {
+			for _, k := range b.Keys() {
+				claimed[k] = true
+			}
+		}
+	}
+	for _, b := range []*key.Binding{
+		&m.List.KeyMap.PrevPage, &m.List.KeyMap.NextPage,
+		&m.List.KeyMap.GoToStart, &m.List.KeyMap.GoToEnd, &m.List.KeyMap.ForceQuit,
+	} {
+		*b = withoutKeys(*b, claimed)
+	}
+}
+
+// withoutKeys drops the claimed keys from a binding, relabelling it with the
+// keys it keeps when its help names one that was dropped
+func withoutKeys(b key.Binding, claimed map[string]bool) key.Binding {
+	var kept []string
+	for _, k := range b.Keys() {
+		if !claimed[k] {
+			kept = append(kept, k)
+		}
+	}
+	if len(kept) == len(b.Keys()) {
+		return b
+	}
+	b.SetKeys(kept...)
+	for _, label := range strings.Split(b.Help().Key, "/") {
+		if claimed[label] {
+			b.SetHelp(strings.Join(kept, "/"), b.Help().Desc)
+			break
+		}
+	}
+	return b
+}
+
+// mergeBindings combines the keys of several bindings into one, labelled
+// like the list's own "↑/k" style help entries
+func mergeBindings(bindings ...key.Binding) key.Binding {
+	var keys, labels []string
+	desc := ""
+	for _, b := range bindings {
+		keys = append(keys, b.Keys()...)
+		labels = append(labels, b.Help().Key)
+		if desc == "" {
+			desc = b.Help().Desc
+		}
+	}
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(labels, "/"), desc))
+}
+
 // Init implements tea.Model
 func (m Model) Init() tea.Cmd {
-	// Start by fetching existing devices
-	return bluetooth.FetchDevicesCmd()
+	// Start by fetching existing devices, and begin discovery right away if configured
+	cfg := config.Get()
+	if cfg.AutoScan {
+		return tea.Batch(
+			bluetooth.FetchDevicesCmd(),
+			bluetooth.DevicesTickCmd(),
+			bluetooth.BatteryTickCmd(),
+			m.presenceTickCmd(),
+			func() tea.Msg { return AutoScanMsg{} },
+			ui.MouseCmd(cfg.Mouse),
+		)
+	}
+	return tea.Batch(bluetooth.FetchDevicesCmd(), bluetooth.DevicesTickCmd(), bluetooth.BatteryTickCmd(), m.presenceTickCmd(), ui.MouseCmd(cfg.Mouse))
 }
 
-// Update implements tea.Model
+// Update implements tea.Model. In accessible mode every new status message
+// and toast is also printed as a plain line so it is announced by screen
+// readers.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	previous, lastToast := m.StatusMessage, m.Toasts.LastID()
+	updated, cmd := m.update(msg)
+	if !ui.Accessible() {
+		return updated, cmd
+	}
+	next, ok := updated.(Model)
+	if !ok {
+		return updated, cmd
+	}
+	if next.StatusMessage != "" && next.StatusMessage != previous {
+		cmd = tea.Batch(cmd, tea.Println(next.StatusMessage))
+	}
+	for _, toast := range next.Toasts.Since(lastToast) {
+		cmd = tea.Batch(cmd, tea.Println(toast.Message))
+	}
+	return updated, cmd
+}
+
+// resize fits the list and any open view to the main pane
+func (m *Model) resize() {
+	pane := m.layout().Main
+	if m.List.Items() != nil {
+		m.List.SetSize(pane.Width, pane.Height)
+		m.updateDeviceTable()
+	}
+	if m.Reviewing {
+		m.RuleList.SetSize(pane.Width, pane.Height)
+	}
+	if m.ChoosingScene {
+		m.SceneList.SetSize(pane.Width, pane.Height)
+	}
+	if m.ViewingQueue {
+		m.QueueList.SetSize(pane.Width, pane.Height)
+	}
+	if m.ViewingLog {
+		m.sizeLog()
+	}
+}
+
+// update applies a message to the model
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.Width = msg.Width
 		m.Height = msg.Height
-		if m.List.Items() != nil {
-			m.List.SetWidth(msg.Width - 4) // Account for padding
-			m.List.SetHeight(msg.Height - 3) // Account for padding (2 rows) + status line (1 row)
-		}
+		m.resize()
 		return m, nil
 
+	case tea.MouseMsg:
+		if m.modalOpen() {
+			return m, nil
+		}
+		return m.handleMouse(msg)
+
 	case tea.KeyMsg:
-		switch keypress := msg.String(); keypress {
-		case "ctrl+c", "q":
+		// While the filter is being typed, every key belongs to the list
+		if m.List.SettingFilter() {
+			break
+		}
+		if m.ViewingError != nil {
+			return m.updateErrorDetails(msg)
+		}
+		if m.Hiding != nil {
+			return m.updateHide(msg)
+		}
+		if m.Editing != nil {
+			return m.updateEdit(msg)
+		}
+		if m.Renaming != nil {
+			return m.updateAlias(msg)
+		}
+		if m.Reviewing {
+			return m.updateReview(msg)
+		}
+		if m.Removing != nil {
+			return m.updateRemove(msg)
+		}
+		if m.SavingScene != nil {
+			return m.updateSaveScene(msg)
+		}
+		if m.ChoosingScene {
+			return m.updateScenes(msg)
+		}
+		if m.ViewingQueue {
+			return m.updateQueue(msg)
+		}
+		if m.ViewingLog {
+			return m.updateLog(msg)
+		}
+
+		switch {
+		case key.Matches(msg, m.Keys.Quit):
 			m.Quitting = true
 			// Stop discovery scanner if active
 			if m.DiscoveryScanner != nil && m.DiscoveryScanner.IsScanning() {
@@ -228,147 +590,184 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, tea.Quit
 
-		case "enter":
+		case key.Matches(msg, m.Keys.Enter):
 			// Smart connect/disconnect: if connected, disconnect; otherwise, connect
-			if !m.Loading && len(m.List.Items()) > 0 && m.ConnectingTo == nil && m.DisconnectingFrom == nil {
-				selectedItem := m.List.SelectedItem()
-				if deviceItem, ok := selectedItem.(ui.DeviceItem); ok {
-					if device, ok := deviceItem.Device().(bluetooth.BluetoothDevice); ok {
-						if device.Connected {
-							// Device is connected, so disconnect it
-							m.DisconnectingFrom = &device
-							m.StatusMessage = "Disconnecting from " + device.Name + "..."
-							// Immediately refresh UI to show disconnecting status
-							items := combineDevicesToListItems(m.PairedDevices, m.DiscoveredDevices, m.ConnectingTo, m.DisconnectingFrom)
-							m.updateDeviceList(items)
-							return m, tea.Batch(
-								func() tea.Msg { return DisconnectingMsg{Device: device} },
-								bluetooth.DisconnectCmd(device),
-								bluetooth.UIUpdateCmd(),
-							)
-						} else {
-							// Device is not connected, so connect to it
-							m.ConnectingTo = &device
-							m.StatusMessage = "Connecting to " + device.Name + "..."
-							// Immediately refresh UI to show connecting status
-							items := combineDevicesToListItems(m.PairedDevices, m.DiscoveredDevices, m.ConnectingTo, m.DisconnectingFrom)
-							m.updateDeviceList(items)
-							return m, tea.Batch(
-								func() tea.Msg { return ConnectingMsg{Device: device} },
-								bluetooth.ConnectCmd(device),
-								bluetooth.UIUpdateCmd(),
-							)
-						}
-					}
-				}
+			if next, cmd, ok := m.smartConnect(); ok {
+				return next, cmd
 			}
 
-		case "s":
+		case key.Matches(msg, m.Keys.Scan):
 			// Toggle scanning
-			switch m.ScanState {
-			case ScanStopped:
-				m.ScanState = ScanStarting
-				m.StatusMessage = "Starting real-time device discovery..."
-				if err := m.DiscoveryScanner.StartDiscovery(); err != nil {
-					m.StatusMessage = "Failed to start discovery: " + err.Error()
-					m.ScanState = ScanStopped
-				} else {
-					m.ScanState = ScanActive
-					m.StatusMessage = "Scanning for devices... Press 's' to stop"
-					// Update title to reflect new state
-					if m.List.Items() != nil {
-						items := combineDevicesToListItems(m.PairedDevices, m.DiscoveredDevices, m.ConnectingTo, m.DisconnectingFrom)
-						m.updateDeviceList(items)
-					}
-					return m, bluetooth.DiscoveryTickCmd(m.DiscoveryScanner)
-				}
-			case ScanActive:
-				m.ScanState = ScanStopping
-				m.StatusMessage = "Stopping discovery..."
-				if err := m.DiscoveryScanner.StopDiscovery(); err != nil {
-					m.StatusMessage = "Failed to stop discovery: " + err.Error()
-				} else {
-					m.ScanState = ScanStopped
-					m.StatusMessage = "Discovery stopped"
-				}
-				// Update title to reflect new state
-				if m.List.Items() != nil {
-					items := combineDevicesToListItems(m.PairedDevices, m.DiscoveredDevices, m.ConnectingTo, m.DisconnectingFrom)
-					m.updateDeviceList(items)
-				}
+			return m.toggleScan()
+
+		case key.Matches(msg, m.Keys.Connect):
+			// Connect to the marked devices, or else the selected one
+			if len(m.Marked) > 0 {
+				return m.startBatch(opConnect)
+			}
+			if device, ok := m.selectedDevice(); ok && !m.willConnect(device) {
+				return m.connectDevice(device)
 			}
 
-		case "c":
-			// Connect to selected device
-			if !m.Loading && len(m.List.Items()) > 0 && m.ConnectingTo == nil {
-				selectedItem := m.List.SelectedItem()
-				if deviceItem, ok := selectedItem.(ui.DeviceItem); ok {
-					if device, ok := deviceItem.Device().(bluetooth.BluetoothDevice); ok {
-						if !device.Connected {
-							m.ConnectingTo = &device
-							m.StatusMessage = "Connecting to " + device.Name + "..."
-							// Immediately refresh UI to show connecting status
-							items := combineDevicesToListItems(m.PairedDevices, m.DiscoveredDevices, m.ConnectingTo, m.DisconnectingFrom)
-							m.updateDeviceList(items)
-							return m, tea.Batch(
-								func() tea.Msg { return ConnectingMsg{Device: device} },
-								bluetooth.ConnectCmd(device),
-								bluetooth.UIUpdateCmd(),
-							)
-						}
-					}
-				}
+		case key.Matches(msg, m.Keys.Disconnect):
+			// Disconnect from the marked devices, or else the selected one
+			if len(m.Marked) > 0 {
+				return m.startBatch(opDisconnect)
+			}
+			if device, ok := m.selectedDevice(); ok && m.willConnect(device) {
+				return m.disconnectDevice(device)
 			}
 
-		case "d":
-			// Disconnect from selected device
-			if !m.Loading && len(m.List.Items()) > 0 && m.DisconnectingFrom == nil {
-				selectedItem := m.List.SelectedItem()
-				if deviceItem, ok := selectedItem.(ui.DeviceItem); ok {
-					if device, ok := deviceItem.Device().(bluetooth.BluetoothDevice); ok {
-						if device.Connected {
-							m.DisconnectingFrom = &device
-							m.StatusMessage = "Disconnecting from " + device.Name + "..."
-							// Immediately refresh UI to show disconnecting status
-							items := combineDevicesToListItems(m.PairedDevices, m.DiscoveredDevices, m.ConnectingTo, m.DisconnectingFrom)
-							m.updateDeviceList(items)
-							return m, tea.Batch(
-								func() tea.Msg { return DisconnectingMsg{Device: device} },
-								bluetooth.DisconnectCmd(device),
-								bluetooth.UIUpdateCmd(),
-							)
-						}
-					}
-				}
+		case key.Matches(msg, m.Keys.Trust):
+			// Trust the marked devices, or else the selected one
+			if len(m.Marked) > 0 {
+				return m.startBatch(opTrust)
+			}
+			if device, ok := m.selectedDevice(); ok {
+				op, cmd := m.startOperation(device, opTrust)
+				m.StatusMessage = m.startText(op)
+				m.refreshDevices()
+				return m, cmd
+			}
+			return m, nil
+
+		case key.Matches(msg, m.Keys.Remove):
+			// Ask before unpairing the marked devices, or else the selected one
+			return m.confirmRemove()
+
+		case key.Matches(msg, m.Keys.ToggleView):
+			// Switch between the two-line list and the table
+			if m.Layout == config.LayoutTable {
+				m.Layout = config.LayoutList
+			} else {
+				m.Layout = config.LayoutTable
+			}
+			m.updateDeviceTable()
+			return m, nil
+
+		case key.Matches(msg, m.Keys.SortColumn):
+			// Sort the table by the next visible column
+			if m.Layout == config.LayoutTable {
+				m.TableSort = nextColumn(m.TableColumns, m.TableSort)
+				m.updateDeviceTable()
+			}
+			return m, nil
+
+		case key.Matches(msg, m.Keys.SortOrder):
+			// Flip between ascending and descending table order
+			if m.Layout == config.LayoutTable {
+				m.TableDescending = !m.TableDescending
+				m.updateDeviceTable()
+			}
+			return m, nil
+
+		case key.Matches(msg, m.Keys.Favorite):
+			// Star or unstar the selected device
+			if device, ok := m.selectedDevice(); ok {
+				return m.toggleFavorite(device)
+			}
+			return m, nil
+
+		case key.Matches(msg, m.Keys.FavoritesOnly):
+			// Show only starred devices, or everything again
+			m.FavoritesOnly = !m.FavoritesOnly
+			m.refreshDevices()
+			return m, nil
+
+		case key.Matches(msg, m.Keys.Hide):
+			// Hide the marked devices by address, or ask how to hide the
+			// selected device from discovery
+			if len(m.Marked) > 0 {
+				return m.hideMarked()
+			}
+			if device, ok := m.selectedDevice(); ok {
+				return m.startHide(device)
+			}
+			return m, nil
+
+		case key.Matches(msg, m.Keys.Hidden):
+			// Review the ignore rules and un-hide devices
+			return m.openReview()
+
+		case key.Matches(msg, m.Keys.Edit):
+			// Give the selected device a nickname, tags and a note
+			if device, ok := m.selectedDevice(); ok {
+				return m.startEdit(device)
 			}
+			return m, nil
+
+		case key.Matches(msg, m.Keys.Alias):
+			// Change the Bluetooth alias other tools show for the device
+			if device, ok := m.selectedDevice(); ok {
+				return m.startAlias(device)
+			}
+			return m, nil
+
+		case key.Matches(msg, m.Keys.Mark):
+			// Mark the selected device for a new scene
+			if device, ok := m.selectedDevice(); ok {
+				return m.toggleMark(device)
+			}
+			return m, nil
+
+		case key.Matches(msg, m.Keys.Scenes):
+			// Apply, create or delete scenes
+			return m.openScenes()
 
-		case "r":
+		case key.Matches(msg, m.Keys.Queue):
+			// Show queued, running and recent operations
+			return m.openQueue()
+
+		case key.Matches(msg, m.Keys.Details):
+			// Explain why the selected device's last operation failed
+			return m.openSelectedFailure()
+
+		case key.Matches(msg, m.Keys.Log):
+			// Show what has happened since btui started
+			return m.openLog()
+
+		case key.Matches(msg, m.Keys.Cancel):
+			// Call off the selected device's operations, unless esc has a
+			// filter to clear first
+			if msg.Type != tea.KeyEsc || m.List.FilterState() == list.Unfiltered {
+				return m.cancelOperations()
+			}
+
+		case key.Matches(msg, m.Keys.Refresh):
 			// Refresh device list
 			m.Loading = true
 			return m, bluetooth.FetchDevicesCmd()
 
-		case "j":
-			// Move down in list (vi-style navigation)
-			if m.List.Items() != nil {
-				var cmd tea.Cmd
-				m.List, cmd = m.List.Update(tea.KeyMsg{Type: tea.KeyDown})
-				return m, cmd
+		case key.Matches(msg, m.Keys.Down, m.Keys.ViDown):
+			// Move down in list (arrow or vi-style navigation)
+			if m.Layout == config.LayoutTable {
+				m.setTableCursor(m.TableCursor + 1)
+			} else if m.List.Items() != nil {
+				m.List.CursorDown()
 			}
+			return m, nil
 
-		case "k":
-			// Move up in list (vi-style navigation)
-			if m.List.Items() != nil {
-				var cmd tea.Cmd
-				m.List, cmd = m.List.Update(tea.KeyMsg{Type: tea.KeyUp})
-				return m, cmd
+		case key.Matches(msg, m.Keys.Up, m.Keys.ViUp):
+			// Move up in list (arrow or vi-style navigation)
+			if m.Layout == config.LayoutTable {
+				m.setTableCursor(m.TableCursor - 1)
+			} else if m.List.Items() != nil {
+				m.List.CursorUp()
 			}
+			return m, nil
 		}
 
 	case bluetooth.DevicesMsg:
 		m.Loading = false
 		if msg.Err != nil {
-			m.Err = msg.Err
-			return m, nil
+			message := "Could not list devices: " + msg.Err.Error()
+			m.logActivity(ui.SeverityError, "", message)
+			if m.PairedDevices == nil {
+				m.Err = msg.Err
+				return m, nil
+			}
+			// Once devices are listed, a failed relist leaves them as they were
+			return m, m.toast(ui.SeverityError, message)
 		}
 
 		// Parse paired devices
@@ -381,73 +780,156 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		previous := m.PairedDevices
 		m.PairedDevices = make([]bluetooth.BluetoothDevice, len(msg.Devices))
 		for i, line := range msg.Devices {
 			device := bluetooth.ParseDeviceLine(line, connectedMacs)
 			device.Paired = true // All devices from bluetoothctl devices are paired
 			m.PairedDevices[i] = device
 		}
+		changes := m.connectionChanges(previous, m.PairedDevices)
+		connectionHooks := m.connectionHooks(previous, m.PairedDevices)
+		m.DevicesAt = time.Now()
+		m.applyBatteries()
 
 		// Update the list with combined devices
-		items := combineDevicesToListItems(m.PairedDevices, m.DiscoveredDevices, m.ConnectingTo, m.DisconnectingFrom)
-		m.updateDeviceList(items)
+		m.refreshDevices()
+
+		// Look up properties (such as the device type) for devices seen for the first time
+		macs := make([]string, len(m.PairedDevices))
+		for i, device := range m.PairedDevices {
+			macs[i] = device.MacAddress
+		}
+		return m, tea.Batch(changes, connectionHooks, m.requestDeviceInfo(macs))
+
+	case bluetooth.DevicesTickMsg:
+		// Relist quietly, unless a relist is already under way
+		if m.Loading {
+			return m, bluetooth.DevicesTickCmd()
+		}
+		return m, tea.Batch(bluetooth.FetchDevicesCmd(), bluetooth.DevicesTickCmd())
+
+	case bluetooth.BatteryTickMsg:
+		// Connected devices are asked for their charge now and then, in
+		// case bluetoothctl is not running discovery to report changes
+		return m, tea.Batch(m.readBatteries(), bluetooth.BatteryTickCmd())
+
+	case PresenceTickMsg:
+		// Devices leave once they go unheard, whether or not discovery is
+		// still sending updates
+		return m, tea.Batch(m.updatePresence(nil), m.presenceTickCmd())
+
+	case HookDoneMsg:
+		return m.hookDone(msg)
+
+	case ui.ToastExpiredMsg:
+		return m.expireToast(msg)
+
+	case bluetooth.AliasMsg:
+		m.applyAlias(msg)
+		return m, nil
+
+	case bluetooth.DeviceInfoMsg:
+		if msg.Err != nil {
+			delete(m.DeviceInfo, msg.Info.MacAddress)
+		} else {
+			if m.DeviceInfo == nil {
+				m.DeviceInfo = make(map[string]bluetooth.DeviceInfo)
+			}
+			m.DeviceInfo[msg.Info.MacAddress] = msg.Info
+			if previous, known := m.Batteries[msg.Info.MacAddress]; msg.Info.HasBattery && (!known || msg.Info.Battery != previous) {
+				cmd := m.updateBatteries(map[string]int{msg.Info.MacAddress: msg.Info.Battery})
+				m.refreshDevices()
+				return m, cmd
+			}
+			m.updateDeviceTable()
+		}
 		return m, nil
 
 	case bluetooth.DiscoveryUpdateMsg:
 		if msg.Err != nil {
-			m.StatusMessage = "Discovery error: " + msg.Err.Error()
-			return m, nil
+			message := "Discovery error: " + msg.Err.Error()
+			m.logActivity(ui.SeverityError, "", message)
+			return m, m.toast(ui.SeverityError, message)
 		}
 
 		// Update discovered devices
+		m.logDiscovered(m.DiscoveredDevices, msg.Devices)
+		discoveryHooks := m.discoveryHooks(m.DiscoveredDevices, msg.Devices)
 		m.DiscoveredDevices = msg.Devices
+		m.recordRSSI(msg.Devices)
+		m.applyBatteries()
+		warnings := m.updateBatteries(msg.Batteries)
+		arrivals := m.updatePresence(msg.Devices)
 
 		// Update the list with combined devices
-		items := combineDevicesToListItems(m.PairedDevices, m.DiscoveredDevices, m.ConnectingTo, m.DisconnectingFrom)
-		m.updateDeviceList(items)
+		m.refreshDevices()
 
-		// Continue discovery updates if still scanning
+		// Continue discovery updates if still scanning, looking up properties
+		// of newly discovered devices along the way
 		var cmd tea.Cmd
 		if m.ScanState == ScanActive {
-			cmd = bluetooth.DiscoveryTickCmd(m.DiscoveryScanner)
+			macs := make([]string, len(msg.Devices))
+			for i, device := range msg.Devices {
+				macs[i] = device.MacAddress
+			}
+			cmd = tea.Batch(m.requestDeviceInfo(macs), bluetooth.DiscoveryTickCmd(m.DiscoveryScanner))
 		}
-		return m, cmd
+		return m, tea.Batch(discoveryHooks, warnings, arrivals, cmd)
+
+	case scene.ProgressMsg:
+		return m.updateSceneProgress(msg)
+
+	case scene.DoneMsg:
+		return m.finishScene(msg)
 
-	case ConnectingMsg:
-		m.ConnectingTo = &msg.Device
+	case AutoScanMsg:
+		if m.ScanState == ScanStopped {
+			return m.toggleScan()
+		}
 		return m, nil
 
-	case bluetooth.ConnectMsg:
-		m.ConnectingTo = nil
-		if msg.Success {
-			m.StatusMessage = "Successfully connected to " + msg.Device.Name
-		} else {
-			m.StatusMessage = "Failed to connect to " + msg.Device.Name + ": " + msg.Output
+	case StateSavedMsg:
+		if msg.Err != nil {
+			m.StatusMessage = "Could not save state: " + msg.Err.Error()
+			m.logActivity(ui.SeverityError, "", m.StatusMessage)
 		}
-		// Refresh device list to show updated connection status
-		return m, bluetooth.FetchDevicesCmd()
+		return m, nil
 
-	case DisconnectingMsg:
-		m.DisconnectingFrom = &msg.Device
+	case NotifyFailedMsg:
+		// Not worth interrupting for; the log says why nothing popped up
+		m.logActivity(ui.SeverityWarning, "", "Could not show a desktop notification: "+msg.Err.Error())
+		return m, nil
+
+	case ActivitySavedMsg:
+		if msg.Err != nil {
+			m.StatusMessage = "Could not save the activity log: " + msg.Err.Error()
+		} else {
+			m.StatusMessage = "Saved the activity log to " + msg.Path
+		}
 		return m, nil
 
-	case bluetooth.DisconnectMsg:
-		m.DisconnectingFrom = nil
-		if msg.Success {
-			m.StatusMessage = "Successfully disconnected from " + msg.Device.Name
+	case bluetooth.OperationMsg:
+		return m.updateOperation(msg)
+
+	case ui.ClipboardMsg:
+		if msg.Err != nil {
+			m.StatusMessage = "Could not copy the error details: " + msg.Err.Error()
+			m.logActivity(ui.SeverityError, "", m.StatusMessage)
 		} else {
-			m.StatusMessage = "Failed to disconnect from " + msg.Device.Name + ": " + msg.Output
+			m.StatusMessage = "Copied the error details to the clipboard"
 		}
-		// Refresh device list to show updated connection status
-		return m, bluetooth.FetchDevicesCmd()
+		return m, nil
 
 	case bluetooth.UIUpdateMsg:
-		// Refresh UI during operations to show connecting/disconnecting status
-		if m.ConnectingTo != nil || m.DisconnectingFrom != nil {
-			items := combineDevicesToListItems(m.PairedDevices, m.DiscoveredDevices, m.ConnectingTo, m.DisconnectingFrom)
-			m.updateDeviceList(items)
+		// Refresh UI during operations to show their per-device status
+		if len(m.Operations) > 0 {
+			m.refreshDevices()
+			if m.ViewingQueue {
+				m.QueueList.SetItems(m.queueItems())
+			}
 			// Continue periodic updates while operations are in progress
-			return m, bluetooth.UIUpdateCmd()
+			return m, uiUpdateCmd()
 		}
 		// No operations in progress, stop periodic updates
 		return m, nil
@@ -457,6 +939,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if m.List.Items() != nil {
 		var cmd tea.Cmd
 		m.List, cmd = m.List.Update(msg)
+
+		// Keep the title and table in step with the query being typed or cleared
+		_, m.QueryErr = m.activeQuery()
+		m.List.Title = m.listTitle()
+		m.updateDeviceTable()
 		return m, cmd
 	}
 
@@ -2,10 +2,13 @@ package scan
 
 import (
 	"btui/internal/bluetooth"
+	"btui/internal/config"
 	"btui/internal/ui"
+	"errors"
 	"strings"
 	"testing"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -27,7 +30,7 @@ func TestDeviceToListItem(t *testing.T) {
 		Paired:     true,
 	}
 
-	item := deviceToListItem(device, nil, nil)
+	item := deviceToListItem(device, nil)
 	deviceItem, ok := item.(ui.DeviceItem)
 	if !ok {
 		t.Fatal("Expected item to be ui.DeviceItem")
@@ -53,7 +56,7 @@ func TestDiscoveredDeviceToListItem(t *testing.T) {
 		RSSI: -72,
 	}
 
-	item := discoveredDeviceToListItem(discovered, nil, nil)
+	item := discoveredDeviceToListItem(discovered, nil)
 	deviceItem, ok := item.(ui.DeviceItem)
 	if !ok {
 		t.Fatal("Expected item to be ui.DeviceItem")
@@ -101,7 +104,7 @@ func TestCombineDevicesToListItems(t *testing.T) {
 		},
 	}
 
-	items := combineDevicesToListItems(pairedDevices, discoveredDevices, nil, nil)
+	items := combineDevicesToListItems(pairedDevices, discoveredDevices, nil)
 
 	// Should have 2 items: 1 paired, 1 discovered (duplicate ignored)
 	if len(items) != 2 {
@@ -117,32 +120,25 @@ func TestConnectingDisconnectingStatus(t *testing.T) {
 		Paired:     true,
 	}
 
-	connectingDevice := &bluetooth.BluetoothDevice{
-		MacAddress: "AA:BB:CC:DD:EE:FF",
-		Name:       "Test Device",
-	}
-
-	disconnectingDevice := &bluetooth.BluetoothDevice{
-		MacAddress: "AA:BB:CC:DD:EE:FF", 
-		Name:       "Test Device",
-	}
+	connecting := map[string]string{"AA:BB:CC:DD:EE:FF": statusConnecting}
+	disconnecting := map[string]string{"AA:BB:CC:DD:EE:FF": statusDisconnecting}
 
 	// Test connecting status
-	item := deviceToListItem(device, connectingDevice, nil)
+	item := deviceToListItem(device, connecting)
 	deviceItem := item.(ui.DeviceItem)
 	if !strings.Contains(deviceItem.Description(), "Connecting...") {
 		t.Errorf("Expected connecting status, got: %s", deviceItem.Description())
 	}
 
 	// Test disconnecting status  
-	item = deviceToListItem(device, nil, disconnectingDevice)
+	item = deviceToListItem(device, disconnecting)
 	deviceItem = item.(ui.DeviceItem)
 	if !strings.Contains(deviceItem.Description(), "Disconnecting...") {
 		t.Errorf("Expected disconnecting status, got: %s", deviceItem.Description())
 	}
 
 	// Test normal status when no operations in progress
-	item = deviceToListItem(device, nil, nil)
+	item = deviceToListItem(device, nil)
 	deviceItem = item.(ui.DeviceItem)
 	if !strings.Contains(deviceItem.Description(), "Paired") {
 		t.Errorf("Expected paired status, got: %s", deviceItem.Description())
@@ -157,7 +153,7 @@ func TestUIUpdateHandling(t *testing.T) {
 		MacAddress: "AA:BB:CC:DD:EE:FF",
 		Name:       "Test Device",
 	}
-	model.ConnectingTo = &device
+	model.Operations[1] = bluetooth.Operation{ID: 1, Action: opConnect, Device: device, State: bluetooth.OpRunning}
 	
 	// Simulate UI update message
 	msg := bluetooth.UIUpdateMsg{}
@@ -170,8 +166,7 @@ func TestUIUpdateHandling(t *testing.T) {
 	
 	// Cast back to Model type and clear connecting state
 	newModel := newModelInterface.(Model)
-	newModel.ConnectingTo = nil
-	newModel.DisconnectingFrom = nil
+	newModel.Operations = nil
 	
 	// Simulate another UI update message
 	finalModel, finalCmd := newModel.Update(msg)
@@ -255,3 +250,237 @@ func TestUpdateDiscoveryUpdateMsg(t *testing.T) {
 		t.Error("Should not return command when not actively scanning")
 	}
 }
+
+func TestSortDeviceItems(t *testing.T) {
+	items := []list.Item{
+		deviceToListItem(bluetooth.BluetoothDevice{MacAddress: "CC:00:00:00:00:00", Name: "alpha"}, nil),
+		deviceToListItem(bluetooth.BluetoothDevice{MacAddress: "AA:00:00:00:00:00", Name: "Charlie"}, nil),
+		deviceToListItem(bluetooth.BluetoothDevice{MacAddress: "BB:00:00:00:00:00", Name: "bravo"}, nil),
+	}
+	rssi := map[string]int{
+		"AA:00:00:00:00:00": -80,
+		"BB:00:00:00:00:00": -40,
+	}
+
+	names := func() []string {
+		var out []string
+		for _, item := range items {
+			out = append(out, item.(ui.DeviceItem).Device().(bluetooth.BluetoothDevice).Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		order    string
+		expected string
+	}{
+		{config.SortName, "alpha,bravo,Charlie"},
+		{config.SortMAC, "Charlie,bravo,alpha"},
+		// Strongest signal first, devices without RSSI last
+		{config.SortRSSI, "bravo,Charlie,alpha"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			sortDeviceItems(items, tt.order, rssi)
+			if got := strings.Join(names(), ","); got != tt.expected {
+				t.Errorf("Expected order %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestInitAutoScan(t *testing.T) {
+	t.Cleanup(func() { config.Set(config.Default()) })
+
+	cfg := config.Default()
+	cfg.AutoScan = true
+	config.Set(cfg)
+
+	model := NewModel()
+	if cmd := model.Init(); cmd == nil {
+		t.Error("Expected Init to return a command")
+	}
+
+	// Auto-scan is ignored once discovery is already running
+	model.ScanState = ScanActive
+	updated, cmd := model.Update(AutoScanMsg{})
+	if cmd != nil {
+		t.Error("Expected no command when discovery is already active")
+	}
+	if updated.(Model).ScanState != ScanActive {
+		t.Error("Expected scan state to stay active")
+	}
+}
+
+func TestReboundKeysDispatch(t *testing.T) {
+	t.Cleanup(func() { config.Set(config.Default()) })
+
+	cfg := config.Default()
+	cfg.Keys = map[string][]string{
+		config.ActionConnect: {"x"},
+		config.ActionQuit:    {"ctrl+q"},
+	}
+	config.Set(cfg)
+
+	model := NewModel()
+	model, _ = updateModel(model, bluetooth.DevicesMsg{
+		Devices: []string{"Device AA:BB:CC:DD:EE:FF Test Device"},
+	})
+
+	// The default key no longer triggers the action
+	updated, _ := updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if len(updated.Operations) != 0 {
+		t.Error("Expected 'c' to no longer connect after rebinding")
+	}
+
+	updated, cmd := updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if actionOn(updated, "AA:BB:CC:DD:EE:FF") != opConnect || cmd == nil {
+		t.Error("Expected 'x' to start connecting after rebinding")
+	}
+
+	// Neither the scan view nor the list may quit on the old quit key
+	updated, cmd = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if updated.Quitting || cmd != nil {
+		t.Error("Expected 'q' to be ignored after rebinding quit")
+	}
+}
+
+func TestActionKeysTakenFromList(t *testing.T) {
+	t.Cleanup(func() { config.Set(config.Default()) })
+
+	cfg := config.Default()
+	cfg.Keys = map[string][]string{
+		config.ActionRefresh: {"G"},
+	}
+	config.Set(cfg)
+
+	model := NewModel()
+	model, _ = updateModel(model, bluetooth.DevicesMsg{
+		Devices: []string{
+			"Device AA:BB:CC:DD:EE:01 First Device",
+			"Device AA:BB:CC:DD:EE:02 Second Device",
+		},
+	})
+
+	// The list keeps its other keys but no longer jumps to the end on G
+	g := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}}
+	if key.Matches(g, model.List.KeyMap.GoToEnd) {
+		t.Error("Expected G to be taken from the list's go to end binding")
+	}
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyEnd}, model.List.KeyMap.GoToEnd) {
+		t.Error("Expected end to still go to the end of the list")
+	}
+	// Default actions claim the list's f and d paging keys
+	if got := strings.Join(model.List.KeyMap.NextPage.Keys(), ","); got != "right,l,pgdown" {
+		t.Errorf("Expected f and d taken from the list's next page binding, got %s", got)
+	}
+
+	updated, cmd := updateModel(model, g)
+	if !updated.Loading || cmd == nil || updated.List.Index() != 0 {
+		t.Error("Expected G to refresh without moving the cursor")
+	}
+}
+
+func TestHelpUsesEffectiveBindings(t *testing.T) {
+	keys := newScanKeyMap(map[string][]string{
+		config.ActionEnter: {"space"},
+		config.ActionScan:  {"ctrl+s", "s"},
+		config.ActionQuit:  {"Q"},
+	})
+
+	var labels []string
+	for _, b := range keys.ShortHelp() {
+		labels = append(labels, b.Help().Key)
+	}
+	if got := strings.Join(labels, ","); got != "space,ctrl+s,Q" {
+		t.Errorf("Expected help keys space,ctrl+s,Q, got %s", got)
+	}
+}
+
+func TestKeysIgnoredWhileFiltering(t *testing.T) {
+	model := NewModel()
+	model, _ = updateModel(model, bluetooth.DevicesMsg{
+		Devices: []string{"Device AA:BB:CC:DD:EE:FF Test Device"},
+	})
+
+	// Start filtering, then type a key that is bound to an action
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	model, _ = updateModel(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+
+	if len(model.Operations) != 0 {
+		t.Error("Expected bound keys to be typed into the filter instead of triggering actions")
+	}
+	if model.List.FilterValue() != "c" {
+		t.Errorf("Expected filter value 'c', got %q", model.List.FilterValue())
+	}
+}
+
+// updateModel is a helper that runs Update and returns the concrete Model
+func updateModel(m Model, msg tea.Msg) (Model, tea.Cmd) {
+	updated, cmd := m.Update(msg)
+	return updated.(Model), cmd
+}
+
+func TestAccessibleAnnouncesStatus(t *testing.T) {
+	t.Cleanup(func() {
+		ui.SetAccessible(false)
+		ui.ApplyTheme(ui.Presets[ui.ThemeDark])
+	})
+	ui.SetAccessible(true)
+
+	model := NewModel()
+	device := bluetooth.BluetoothDevice{MacAddress: "AA:BB:CC:DD:EE:FF", Name: "Test Device"}
+	op, _ := model.startOperation(device, opConnect)
+	op.State = bluetooth.OpSucceeded
+	updated, cmd := updateModel(model, bluetooth.OperationMsg{Op: op})
+	if lastToast(updated) != "Successfully connected to Test Device" {
+		t.Fatalf("Unexpected result %q", lastToast(updated))
+	}
+	if cmd == nil {
+		t.Fatal("Expected a command")
+	}
+
+	// The result is printed alongside the toast expiry and device refresh
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("Expected a batch with the refresh and the announcement, got %T", cmd())
+	}
+
+	// Periodic redraws are skipped while an operation runs
+	_, _ = model.startOperation(device, opDisconnect)
+	if _, cmd := updateModel(model, bluetooth.UIUpdateMsg{}); cmd != nil {
+		t.Error("Expected no periodic redraws in accessible mode")
+	}
+
+	item := discoveredDeviceToListItem(bluetooth.DiscoveredDevice{
+		BluetoothDevice: device,
+		RSSI:            -50,
+	}, nil).(ui.DeviceItem)
+	if item.Description() != "Discovered, RSSI: -50, AA:BB:CC:DD:EE:FF" {
+		t.Errorf("Expected plain description, got %q", item.Description())
+	}
+}
+
+func TestDeviceInfoRetriedAfterError(t *testing.T) {
+	model := viewFixture()
+	mac := "F0:99:B6:12:34:56"
+
+	if cmd := model.requestDeviceInfo([]string{mac}); cmd == nil {
+		t.Fatal("Expected the TV's properties to be requested")
+	}
+	if cmd := model.requestDeviceInfo([]string{mac}); cmd != nil {
+		t.Fatal("Expected a pending lookup not to be requested again")
+	}
+
+	model, _ = updateModel(model, bluetooth.DeviceInfoMsg{
+		Info: bluetooth.DeviceInfo{MacAddress: mac},
+		Err:  errors.New("timed out"),
+	})
+	if _, known := model.DeviceInfo[mac]; known {
+		t.Error("Expected a failed lookup to be forgotten")
+	}
+	if cmd := model.requestDeviceInfo([]string{mac}); cmd == nil {
+		t.Error("Expected a failed lookup to be requested again")
+	}
+}
@@ -1,6 +1,7 @@
 package scan
 
 import (
+	"btui/internal/config"
 	"btui/internal/ui"
 	"fmt"
 )
@@ -20,20 +21,52 @@ func (m Model) View() string {
 		return ui.AppStyle.Render("Loading devices...")
 	}
 
-	// Render the list and add status message area below
+	// Render the list or table and add status message area below
 	if m.List.Items() != nil {
-		listView := m.List.View()
-		
+		mainView := m.List.View()
+		if m.ViewingError != nil {
+			mainView = m.ViewingError.View(m.layout().Main.Width)
+		} else if m.Editing != nil {
+			mainView = m.editorView()
+		} else if m.Reviewing {
+			mainView = m.RuleList.View()
+		} else if m.ChoosingScene {
+			mainView = m.SceneList.View()
+		} else if m.ViewingQueue {
+			mainView = m.QueueList.View()
+		} else if m.ViewingLog {
+			mainView = m.logView()
+		} else if m.Layout == config.LayoutTable {
+			mainView = ui.TitleStyle.Render(m.tableTitle()) + "\n\n" + m.Table.View() + "\n" + m.tableHelp()
+		}
+
 		// Add status message area below the list (always present to prevent jumping)
 		statusLine := ""
-		if m.StatusMessage != "" {
+		if m.Hiding != nil {
+			statusLine = m.hidePromptText()
+		} else if m.Renaming != nil {
+			statusLine = m.aliasPromptText()
+		} else if m.SavingScene != nil {
+			statusLine = m.scenePromptText()
+		} else if m.Removing != nil {
+			statusLine = m.removePromptText()
+		} else if m.QueryErr != nil {
+			statusLine = ui.ErrorStyle().Render("Invalid query: " + m.QueryErr.Error())
+		} else if m.StatusMessage != "" {
 			statusLine = m.StatusMessage
+		} else if text := m.scanStatusText(); text != "" {
+			statusLine = text
 		} else {
 			statusLine = " " // blank line to maintain consistent spacing
 		}
-		
-		// Combine list view with status message area
-		fullView := listView + "\n" + statusLine
+		if m.Toasts.Height() > 0 {
+			statusLine = m.Toasts.View(m.layout().Main.Width) + "\n" + statusLine
+		}
+
+		// Combine the main view with the status message area, and place the
+		// detail pane beside it on wide terminals
+		layout := m.layout()
+		fullView := layout.Join(mainView+"\n"+statusLine, layout.RenderDetail(m.detailView()))
 		return ui.AppStyle.Render(fullView)
 	}
 
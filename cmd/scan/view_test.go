@@ -0,0 +1,93 @@
+package scan
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/ui"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// viewFixture returns a scan model with one device in each status tier
+func viewFixture() Model {
+	model := NewModel()
+	model, _ = updateModel(model, tea.WindowSizeMsg{Width: 80, Height: 20})
+	model, _ = updateModel(model, bluetooth.DevicesMsg{
+		Devices: []string{
+			"Device 4C:87:5D:28:86:DD Bose NC 700 Headphones",
+			"Device DC:2C:26:09:D0:0C Keychron K4",
+		},
+		ConnectedDevices: []string{
+			"Device DC:2C:26:09:D0:0C Keychron K4",
+		},
+	})
+	model, _ = updateModel(model, bluetooth.DiscoveryUpdateMsg{
+		Devices: []bluetooth.DiscoveredDevice{
+			{
+				BluetoothDevice: bluetooth.BluetoothDevice{
+					MacAddress: "F0:99:B6:12:34:56",
+					Name:       "Living Room TV",
+				},
+				RSSI: -67,
+			},
+		},
+	})
+	model.StatusMessage = "Successfully connected to Keychron K4"
+	return model
+}
+
+func TestViewGolden(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	lipgloss.SetHasDarkBackground(true)
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(termenv.Ascii)
+		ui.ApplyTheme(ui.Presets[ui.ThemeDark])
+	})
+
+	for _, name := range ui.PresetNames() {
+		t.Run(name, func(t *testing.T) {
+			ui.ApplyTheme(ui.Presets[name])
+			got := viewFixture().View()
+
+			path := filepath.Join("testdata", "view_"+name+".golden")
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Missing golden file (run go test -update): %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("View under %s theme does not match %s\ngot:\n%s\nwant:\n%s", name, path, got, want)
+			}
+		})
+	}
+}
+
+func TestPresetsRenderDifferently(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(termenv.Ascii)
+		ui.ApplyTheme(ui.Presets[ui.ThemeDark])
+	})
+
+	seen := make(map[string]string)
+	for _, name := range ui.PresetNames() {
+		ui.ApplyTheme(ui.Presets[name])
+		view := viewFixture().View()
+		if other, ok := seen[view]; ok {
+			t.Errorf("Themes %s and %s render identically", name, other)
+		}
+		seen[view] = name
+	}
+}
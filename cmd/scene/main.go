@@ -0,0 +1,111 @@
+// Package scene contains the commands that list and apply scenes, named
+// groups of devices connected or disconnected together
+package scene
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/scene"
+	"btui/internal/ui"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// New creates a new cobra command for scenes
+func New() *cobra.Command {
+	c := &cobra.Command{}
+	c.Use = "scene"
+	c.Short = "List or apply device scenes"
+	c.Long = "Scenes connect or disconnect a group of devices in one go. " +
+		"Define them under [scenes] in the config file or save them from the scan view."
+
+	list := &cobra.Command{}
+	list.Use = "list"
+	list.Short = "List the scenes and their steps"
+	list.Args = cobra.NoArgs
+	list.Run = run(listScenes)
+
+	apply := &cobra.Command{}
+	apply.Use = "apply <name>"
+	apply.Short = "Connect and disconnect the devices of a scene"
+	apply.Args = cobra.ExactArgs(1)
+	apply.Run = run(applyScene)
+
+	c.AddCommand(list, apply)
+	return c
+}
+
+// run adapts a subcommand so its errors are reported like the other commands
+func run(f func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if err := f(cmd, args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// listScenes prints every scene with its steps
+func listScenes(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	scenes := scene.List()
+	if len(scenes) == 0 {
+		fmt.Fprintln(out, "No scenes defined")
+		return nil
+	}
+	for _, s := range scenes {
+		steps := make([]string, len(s.Steps))
+		for i, step := range s.Steps {
+			steps[i] = step.Action + " " + step.Device
+		}
+		fmt.Fprintf(out, "%s: %s\n", s.Name, strings.Join(steps, ", "))
+	}
+	return nil
+}
+
+// applyScene runs a scene against the paired devices
+func applyScene(cmd *cobra.Command, args []string) error {
+	s, err := scene.Find(args[0])
+	if err != nil {
+		return err
+	}
+	msg := bluetooth.FetchDevicesCmd()().(bluetooth.DevicesMsg)
+	if msg.Err != nil {
+		return msg.Err
+	}
+	devices := bluetooth.ParseDevices(msg.Devices, msg.ConnectedDevices)
+	return report(cmd.OutOrStdout(), s, scene.Apply(s.Scene, devices, scene.Execute))
+}
+
+// report prints each step as it finishes and then the summary, returning an
+// error when any step failed
+func report(out io.Writer, s scene.Scene, updates <-chan scene.Progress) error {
+	steps := make([]scene.Progress, len(s.Steps))
+	for p := range updates {
+		steps[p.Index] = p
+		switch p.Status {
+		case scene.Succeeded:
+			fmt.Fprintf(out, "%s %s %s\n", ui.SuccessMarker(), p.Step.Action, p.Name())
+		case scene.Skipped:
+			fmt.Fprintf(out, "- %s %s: %s\n", p.Step.Action, p.Name(), p.Output)
+		case scene.Failed:
+			fmt.Fprintf(out, "%s %s %s: %s\n", ui.FailureMarker(), p.Step.Action, p.Name(), p.Output)
+			if hint := bluetooth.Classify(p.Output).Hint(); hint != "" {
+				fmt.Fprintf(out, "  hint: %s\n", hint)
+			}
+		}
+	}
+
+	summary := scene.Summary(s.Name, steps)
+	for _, p := range steps {
+		if p.Status == scene.Failed {
+			return errors.New(summary)
+		}
+	}
+	fmt.Fprintln(out, summary)
+	return nil
+}
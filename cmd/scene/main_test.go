@@ -0,0 +1,65 @@
+package scene
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/scene"
+	"btui/internal/state"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// useScenes makes the desk scene the only one defined
+func useScenes(t *testing.T) scene.Scene {
+	t.Helper()
+	desk := config.Scene{Steps: []config.SceneStep{
+		{Device: "Keychron K4", Action: config.SceneConnect},
+		{Device: "Bose NC 700 Headphones", Action: config.SceneConnect},
+		{Device: "MX Master 3", Action: config.SceneConnect},
+	}}
+	store := state.New("")
+	store.SaveScene("desk", desk)
+	state.Set(store)
+	t.Cleanup(func() { state.Set(state.New("")) })
+	return scene.Scene{Scene: desk, Name: "desk", Saved: true}
+}
+
+func TestListScenes(t *testing.T) {
+	useScenes(t)
+	c := New()
+	var out bytes.Buffer
+	c.SetOut(&out)
+	c.SetArgs([]string{"list"})
+	if _, err := c.ExecuteC(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "desk: connect Keychron K4, connect Bose NC 700 Headphones, connect MX Master 3\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestReport(t *testing.T) {
+	desk := useScenes(t)
+	devices := []bluetooth.BluetoothDevice{
+		{MacAddress: "DC:2C:26:09:D0:0C", Name: "Keychron K4", Connected: true},
+		{MacAddress: "4C:87:5D:28:86:DD", Name: "Bose NC 700 Headphones"},
+	}
+	execute := func(string, bluetooth.BluetoothDevice) (bool, string) { return true, "" }
+
+	var out bytes.Buffer
+	err := report(&out, desk, scene.Apply(desk.Scene, devices, execute))
+	if err == nil || !strings.Contains(err.Error(), `1 failed (MX Master 3: no device named "MX Master 3")`) {
+		t.Errorf("Expected the missing device to fail the scene, got %v", err)
+	}
+	for _, line := range []string{
+		"- connect Keychron K4: already connected",
+		"✓ connect Bose NC 700 Headphones",
+		"✗ connect MX Master 3",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("Expected %q in the output, got:\n%s", line, out.String())
+		}
+	}
+}
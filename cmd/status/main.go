@@ -0,0 +1,115 @@
+// Package status contains the entry point for the status command, which
+// prints the paired devices with their connection state and battery level
+package status
+
+import (
+	"btui/internal/battery"
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/state"
+	"btui/internal/ui"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// New creates a new cobra command for printing device status
+func New() *cobra.Command {
+	c := &cobra.Command{}
+	c.Use = "status"
+	c.Short = "Show paired devices, whether they are connected and their battery"
+	c.Long = "Print every paired device, connected ones first, with the battery level of those that report one"
+	c.Run = run
+	c.Flags().Bool("json", false, "print a JSON array")
+	return c
+}
+
+// Device is one line of the status output
+type Device struct {
+	MAC       string `json:"mac"`
+	Name      string `json:"name"`
+	Connected bool   `json:"connected"`
+	// Battery is the charge left in percent, left out when unknown
+	Battery *int `json:"battery,omitempty"`
+}
+
+// run executes the status command
+func run(cmd *cobra.Command, args []string) {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	msg := bluetooth.FetchDevicesCmd()().(bluetooth.DevicesMsg)
+	if msg.Err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", msg.Err)
+		os.Exit(1)
+	}
+
+	// Only connected devices have a battery to read
+	paired := bluetooth.ParseDevices(msg.Devices, msg.ConnectedDevices)
+	for i, device := range paired {
+		if device.Connected {
+			info, _ := bluetooth.FetchDeviceInfo(device.MacAddress)
+			paired[i].Battery, paired[i].HasBattery = info.Battery, info.HasBattery
+		}
+	}
+
+	if err := write(cmd.OutOrStdout(), devices(paired), asJSON); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// devices orders paired devices connected first, then by name
+func devices(paired []bluetooth.BluetoothDevice) []Device {
+	store := state.Get()
+	result := make([]Device, len(paired))
+	for i, d := range paired {
+		result[i] = Device{
+			MAC:       d.MacAddress,
+			Name:      store.DisplayName(d.MacAddress, d.Name),
+			Connected: d.Connected,
+		}
+		if d.HasBattery {
+			result[i].Battery = &d.Battery
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Connected != result[j].Connected {
+			return result[i].Connected
+		}
+		return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name)
+	})
+	return result
+}
+
+// write prints the devices as a JSON array or one line each
+func write(out io.Writer, devices []Device, asJSON bool) error {
+	if asJSON {
+		if devices == nil {
+			devices = []Device{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(devices)
+	}
+
+	thresholds := config.Get().Battery.Thresholds
+	for _, d := range devices {
+		status := "paired"
+		if d.Connected {
+			status = "connected"
+		}
+		line := fmt.Sprintf("%-9s %s %s", status, d.MAC, d.Name)
+		if d.Battery != nil {
+			line += " " + ui.BatteryLevel(*d.Battery, battery.Low(*d.Battery, thresholds))
+		}
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package status
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/state"
+	"btui/internal/ui"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+var paired = []bluetooth.BluetoothDevice{
+	{MacAddress: "F0:99:B6:12:34:56", Name: "MX Master 3", Paired: true},
+	{MacAddress: "DC:2C:26:09:D0:0C", Name: "Keychron K4", Paired: true, Connected: true, Battery: 8, HasBattery: true},
+	{MacAddress: "4C:87:5D:28:86:DD", Name: "Bose NC 700 Headphones", Paired: true, Connected: true, Battery: 90, HasBattery: true},
+}
+
+func TestWriteText(t *testing.T) {
+	state.Set(state.New(""))
+	var out bytes.Buffer
+	if err := write(&out, devices(paired), false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := "connected 4C:87:5D:28:86:DD Bose NC 700 Headphones 🔋 90%\n" +
+		"connected DC:2C:26:09:D0:0C Keychron K4 🪫 8%\n" +
+		"paired    F0:99:B6:12:34:56 MX Master 3\n"
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestWriteTextAccessible(t *testing.T) {
+	t.Cleanup(func() {
+		ui.SetAccessible(false)
+		ui.ApplyTheme(ui.Presets[ui.ThemeDark])
+	})
+	ui.SetAccessible(true)
+
+	var out bytes.Buffer
+	if err := write(&out, devices(paired[1:2]), false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if out.String() != "connected DC:2C:26:09:D0:0C Keychron K4 8% low\n" {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	state.Set(state.New(""))
+	var out bytes.Buffer
+	if err := write(&out, devices(paired), true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var decoded []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(decoded) != 3 || decoded[0]["battery"] != float64(90) || decoded[0]["connected"] != true {
+		t.Errorf("Unexpected devices %v", decoded)
+	}
+	if _, ok := decoded[2]["battery"]; ok {
+		t.Errorf("Expected no battery for a disconnected device, got %v", decoded[2])
+	}
+
+	out.Reset()
+	if err := write(&out, nil, true); err != nil || out.String() != "[]\n" {
+		t.Errorf("Expected an empty array, got %q (%v)", out.String(), err)
+	}
+}
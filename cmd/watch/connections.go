@@ -0,0 +1,87 @@
+package watch
+
+import (
+	"btui/internal/battery"
+	"btui/internal/bluetooth"
+	"btui/internal/notify"
+	"btui/internal/query"
+	"btui/internal/state"
+	"sort"
+)
+
+// connections follows which paired devices are connected from one device
+// list to the next, for desktop notifications while watching
+type connections struct {
+	connected map[string]bool
+}
+
+// update compares a device list with the previous one and returns a
+// connected or disconnected event for each device that changed, ordered by
+// MAC address. The first list only sets the starting point.
+func (c *connections) update(devices []bluetooth.BluetoothDevice) []notify.Event {
+	var events []notify.Event
+	current := make(map[string]bool, len(devices))
+	for _, d := range devices {
+		current[d.MacAddress] = d.Connected
+		was, known := c.connected[d.MacAddress]
+		if c.connected == nil || !known || was == d.Connected {
+			continue
+		}
+		kind := notify.EventDisconnected
+		if d.Connected {
+			kind = notify.EventConnected
+		}
+		events = append(events, notify.Event{Kind: kind, MAC: d.MacAddress, Name: d.Name})
+	}
+	c.connected = current
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].MAC < events[j].MAC })
+	return events
+}
+
+// lowBatteries passes the charge of each connected device to the monitor and
+// returns the warnings it raises, ordered by MAC address
+func lowBatteries(monitor *battery.Monitor, devices []bluetooth.BluetoothDevice) []battery.Warning {
+	sorted := append([]bluetooth.BluetoothDevice(nil), devices...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].MacAddress < sorted[j].MacAddress })
+
+	var warnings []battery.Warning
+	for _, d := range sorted {
+		if !d.Connected || !d.HasBattery {
+			continue
+		}
+		name := d.Name
+		if name == "" {
+			name = d.MacAddress
+		}
+		if w, ok := monitor.Update(d.MacAddress, name, d.Battery); ok {
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+// batteryChanges records the charge of each connected device and returns the
+// devices whose charge changed since the last time, ordered by MAC address
+func batteryChanges(charges map[string]int, devices []bluetooth.BluetoothDevice) []bluetooth.BluetoothDevice {
+	var changed []bluetooth.BluetoothDevice
+	for _, d := range devices {
+		if !d.Connected || !d.HasBattery {
+			delete(charges, d.MacAddress)
+			continue
+		}
+		if previous, known := charges[d.MacAddress]; !known || previous != d.Battery {
+			changed = append(changed, d)
+		}
+		charges[d.MacAddress] = d.Battery
+	}
+
+	sort.SliceStable(changed, func(i, j int) bool { return changed[i].MacAddress < changed[j].MacAddress })
+	return changed
+}
+
+// hookDevice returns the view of a device that hook device queries are
+// matched against, with its btui-side name, note and tags
+func hookDevice(d bluetooth.BluetoothDevice, rssi int) query.Device {
+	return bluetooth.QueryDevice(d, state.Get().Meta(d.MacAddress), "", rssi)
+}
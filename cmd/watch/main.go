@@ -0,0 +1,187 @@
+// Package watch contains the entry point for the watch command, which prints
+// devices as they appear, change and disappear during discovery
+package watch
+
+import (
+	"btui/internal/battery"
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/hooks"
+	"btui/internal/notify"
+	"btui/internal/presence"
+	"btui/internal/state"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// New creates a new cobra command for watching discovery
+func New() *cobra.Command {
+	c := &cobra.Command{}
+	c.Use = "watch"
+	c.Short = "Print nearby devices as they are discovered"
+	c.Long = "Run discovery and print a line whenever a device appears, changes signal strength or disappears, " +
+		"and whenever one arrives at or leaves a presence rule"
+	c.Run = run
+	c.Flags().Bool("json", false, "print one JSON object per event")
+	c.Flags().Bool("respect-ignore", false, "leave out devices hidden with the scan view's ignore list")
+	c.Flags().Duration("duration", 0, "stop after this long, e.g. 30s (default: until interrupted)")
+	return c
+}
+
+// run executes the watch command
+func run(cmd *cobra.Command, args []string) {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	respectIgnore, _ := cmd.Flags().GetBool("respect-ignore")
+	duration, _ := cmd.Flags().GetDuration("duration")
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	cfg := config.Get()
+	runner := hooks.NewRunner(cfg)
+	// Hooks still running when watching stops are left to finish
+	defer runner.Wait()
+
+	w := newWatcher(os.Stdout, asJSON)
+	w.hooks = runner
+	if len(cfg.Presence.Rules) > 0 {
+		w.presence = presence.NewEvaluator(cfg.Presence.Resolved(), time.Now)
+	}
+	if respectIgnore {
+		w.ignore = state.Get()
+	}
+
+	scanner := bluetooth.NewDiscoveryScanner()
+	if err := scanner.StartDiscovery(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer scanner.StopDiscovery()
+
+	ticker := time.NewTicker(cfg.Intervals.Discovery)
+	defer ticker.Stop()
+
+	// With desktop notifications on, or hooks for them, paired devices are
+	// relisted to notice them connecting and disconnecting, and connected
+	// devices are asked for their charge. The session bus is only reached
+	// once a notification is sent.
+	session := notify.Session()
+	defer session.Close()
+	dispatcher := notify.NewDispatcher(session, cfg.Notifications)
+
+	var relist, charge <-chan time.Time
+	var paired connections
+	if cfg.Notifications.Enabled || runner.Has(config.HookConnect) || runner.Has(config.HookDisconnect) {
+		devices := time.NewTicker(cfg.Intervals.Devices)
+		defer devices.Stop()
+		relist = devices.C
+		checkConnections(dispatcher, runner, &paired, w.rssi)
+	}
+	monitor := battery.NewMonitor(cfg.Battery.Thresholds)
+	charges := make(map[string]int)
+	if cfg.Notifications.Enabled || runner.Has(config.HookBattery) || runner.Has(config.HookLowBattery) {
+		batteries := time.NewTicker(cfg.Battery.Interval)
+		defer batteries.Stop()
+		charge = batteries.C
+		checkBatteries(dispatcher, runner, monitor, charges, w.rssi)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := w.update(scanner.GetDiscoveredDevices(), now); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+		case <-relist:
+			checkConnections(dispatcher, runner, &paired, w.rssi)
+		case <-charge:
+			checkBatteries(dispatcher, runner, monitor, charges, w.rssi)
+		}
+	}
+}
+
+// stderr serializes hook reports, which arrive from several goroutines
+var stderr sync.Mutex
+
+// reportHook writes what a hook printed, or why it failed, to stderr
+func reportHook(r hooks.Result) {
+	stderr.Lock()
+	defer stderr.Unlock()
+	if r.Err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n%s", r.Err, r.Output)
+		return
+	}
+	if r.Output != "" {
+		fmt.Fprintf(os.Stderr, "%s hook for %s: %s", r.Event.Kind, r.Event.Name(), r.Output)
+	}
+}
+
+// checkConnections relists the paired devices and, for each that connected
+// or disconnected since the last time, raises a desktop notification and
+// runs the connect or disconnect hooks with the device's last signal reading
+// from rssi
+func checkConnections(dispatcher *notify.Dispatcher, runner *hooks.Runner, paired *connections, rssi func(mac string) int) {
+	msg := bluetooth.FetchDevicesCmd()().(bluetooth.DevicesMsg)
+	if msg.Err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", msg.Err)
+		return
+	}
+	devices := bluetooth.ParseDevices(msg.Devices, msg.ConnectedDevices)
+	for _, event := range paired.update(devices) {
+		if _, err := dispatcher.Dispatch(event); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		kind := config.HookDisconnect
+		if event.Kind == notify.EventConnected {
+			kind = config.HookConnect
+		}
+		device := bluetooth.BluetoothDevice{MacAddress: event.MAC, Name: event.Name, Paired: true, Connected: kind == config.HookConnect}
+		runner.Dispatch(hooks.Event{Kind: kind, Device: hookDevice(device, rssi(device.MacAddress))}, reportHook)
+	}
+}
+
+// checkBatteries reads the charge of each connected device, runs the
+// battery hooks of those that changed and, for those that ran low, raises a
+// desktop notification and runs the low_battery hooks, with the device's
+// last signal reading from rssi
+func checkBatteries(dispatcher *notify.Dispatcher, runner *hooks.Runner, monitor *battery.Monitor, charges map[string]int, rssi func(mac string) int) {
+	msg := bluetooth.FetchDevicesCmd()().(bluetooth.DevicesMsg)
+	if msg.Err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", msg.Err)
+		return
+	}
+	devices := bluetooth.ParseDevices(msg.Devices, msg.ConnectedDevices)
+	for i, device := range devices {
+		if device.Connected {
+			info, _ := bluetooth.FetchDeviceInfo(device.MacAddress)
+			devices[i].Battery, devices[i].HasBattery = info.Battery, info.HasBattery
+		}
+	}
+
+	for _, device := range batteryChanges(charges, devices) {
+		runner.Dispatch(hooks.Event{Kind: config.HookBattery, Device: hookDevice(device, rssi(device.MacAddress)), Battery: device.Battery}, reportHook)
+	}
+	for _, w := range lowBatteries(monitor, devices) {
+		event := notify.Event{Kind: notify.EventLowBattery, MAC: w.MAC, Name: w.Name, Battery: w.Percent}
+		if _, err := dispatcher.Dispatch(event); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		device := bluetooth.BluetoothDevice{MacAddress: w.MAC, Name: w.Name, Paired: true, Connected: true}
+		hook := hooks.Event{Kind: config.HookLowBattery, Device: hookDevice(device, rssi(w.MAC)), Battery: w.Percent, Threshold: w.Threshold}
+		runner.Dispatch(hook, reportHook)
+	}
+}
@@ -0,0 +1,223 @@
+package watch
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/hooks"
+	"btui/internal/presence"
+	"btui/internal/state"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kinds of event
+const (
+	EventNew    = "new"    // a device was seen for the first time
+	EventUpdate = "update" // a device's signal strength changed
+	EventLost   = "lost"   // a device is no longer reported
+	// A device matching a presence rule arrived or left
+	EventPresent = config.HookPresent
+	EventAbsent  = config.HookAbsent
+)
+
+// Event is one change to the set of nearby devices
+type Event struct {
+	Event string    `json:"event"`
+	MAC   string    `json:"mac"`
+	Name  string    `json:"name"`
+	RSSI  int       `json:"rssi,omitempty"`
+	Time  time.Time `json:"time"`
+	// Rule names the presence rule of present and absent events
+	Rule string `json:"rule,omitempty"`
+}
+
+// watcher turns discovery snapshots into events and prints them
+type watcher struct {
+	out    io.Writer
+	json   bool
+	seen   map[string]bluetooth.DiscoveredDevice
+	ignore *state.Store // nil unless --respect-ignore is set
+	// hooks runs the discover hooks of new devices, reporting each result
+	// to report
+	hooks  *hooks.Runner
+	report func(hooks.Result)
+	// presence turns snapshots into present and absent events; nil without
+	// presence rules
+	presence *presence.Evaluator
+
+	// manufacturer looks up a device's company identifier for manufacturer
+	// rules. Lookups run in the background; successful ones are cached per
+	// device and failed ones are tried again on a later snapshot.
+	manufacturer func(mac string) (string, error)
+	lookups      sync.WaitGroup
+	mu           sync.Mutex
+	// manufacturers holds successful lookups, pending the devices being
+	// looked up, and tried the devices whose lookup has finished at least once
+	manufacturers map[string]string
+	pending       map[string]bool
+	tried         map[string]bool
+}
+
+// newWatcher returns a watcher that prints to out
+func newWatcher(out io.Writer, asJSON bool) *watcher {
+	return &watcher{
+		out:           out,
+		json:          asJSON,
+		seen:          make(map[string]bluetooth.DiscoveredDevice),
+		report:        reportHook,
+		manufacturer:  lookupManufacturer,
+		manufacturers: make(map[string]string),
+		pending:       make(map[string]bool),
+		tried:         make(map[string]bool),
+	}
+}
+
+// lookupManufacturer asks bluetoothctl for a device's manufacturer
+func lookupManufacturer(mac string) (string, error) {
+	info, err := bluetooth.FetchDeviceInfo(mac)
+	return info.Manufacturer, err
+}
+
+// manufacturerOf returns a device's manufacturer if a lookup has found it,
+// starting one in the background otherwise. ready is false until the first
+// lookup of the device has finished.
+func (w *watcher) manufacturerOf(mac string) (manufacturer string, ready bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if manufacturer, ok := w.manufacturers[mac]; ok {
+		return manufacturer, true
+	}
+	if !w.pending[mac] {
+		w.pending[mac] = true
+		w.lookups.Add(1)
+		go func() {
+			defer w.lookups.Done()
+			manufacturer, err := w.manufacturer(mac)
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			if err == nil {
+				w.manufacturers[mac] = manufacturer
+			}
+			delete(w.pending, mac)
+			w.tried[mac] = true
+		}()
+	}
+	return "", w.tried[mac]
+}
+
+// ignored reports whether the ignore list hides a device. A device new to
+// a manufacturer rule is held back until its manufacturer has been looked
+// up, so it is not reported only to disappear again.
+func (w *watcher) ignored(d bluetooth.DiscoveredDevice) bool {
+	if w.ignore == nil {
+		return false
+	}
+	target := state.Target{MAC: d.MacAddress, Name: d.Name}
+
+	// Only ask for the manufacturer when a rule needs it
+	for _, rule := range w.ignore.IgnoreRules() {
+		if rule.Kind != state.IgnoreManufacturer {
+			continue
+		}
+		manufacturer, ready := w.manufacturerOf(d.MacAddress)
+		if _, known := w.seen[d.MacAddress]; !ready && !known {
+			return true
+		}
+		target.Manufacturer = manufacturer
+		break
+	}
+
+	_, hidden := w.ignore.Ignored(target)
+	return hidden
+}
+
+// rssi returns the last signal reading of a device in range, or 0 if it has
+// not been heard during discovery
+func (w *watcher) rssi(mac string) int {
+	return w.seen[mac].RSSI
+}
+
+// diff compares a discovery snapshot with the previous one and returns the
+// events between them, ordered by MAC address
+func (w *watcher) diff(devices []bluetooth.DiscoveredDevice, now time.Time) []Event {
+	var events []Event
+	current := make(map[string]bool, len(devices))
+	for _, d := range devices {
+		if w.ignored(d) {
+			continue
+		}
+		current[d.MacAddress] = true
+
+		previous, known := w.seen[d.MacAddress]
+		switch {
+		case !known:
+			events = append(events, Event{Event: EventNew, MAC: d.MacAddress, Name: d.Name, RSSI: d.RSSI, Time: now})
+		case previous.RSSI != d.RSSI:
+			events = append(events, Event{Event: EventUpdate, MAC: d.MacAddress, Name: d.Name, RSSI: d.RSSI, Time: now})
+		}
+		w.seen[d.MacAddress] = d
+	}
+
+	for mac, d := range w.seen {
+		if !current[mac] {
+			events = append(events, Event{Event: EventLost, MAC: mac, Name: d.Name, Time: now})
+			delete(w.seen, mac)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].MAC < events[j].MAC })
+	return events
+}
+
+// update prints the events since the previous snapshot, followed by the
+// devices arriving and leaving presence rules, and runs their hooks
+func (w *watcher) update(devices []bluetooth.DiscoveredDevice, now time.Time) error {
+	for _, event := range w.diff(devices, now) {
+		if err := w.print(event); err != nil {
+			return err
+		}
+		if event.Event == EventNew && w.hooks != nil {
+			d := w.seen[event.MAC]
+			w.hooks.Dispatch(hooks.Event{Kind: config.HookDiscover, Device: hookDevice(d.BluetoothDevice, d.RSSI)}, w.report)
+		}
+	}
+
+	if w.presence == nil {
+		return nil
+	}
+	// Presence rules pick their own devices, so the ignore list does not apply
+	for _, change := range w.presence.Observe(presence.Sightings(devices, state.Get())) {
+		event := Event{Event: EventAbsent, MAC: change.Device.MAC, Name: change.Device.Name, Time: change.At, Rule: change.Rule}
+		if change.Present {
+			event.Event = EventPresent
+			event.RSSI = change.Device.RSSI
+		}
+		if err := w.print(event); err != nil {
+			return err
+		}
+		if w.hooks != nil {
+			w.hooks.Dispatch(hooks.Event{Kind: event.Event, Device: change.Device, Rule: change.Rule}, w.report)
+		}
+	}
+	return nil
+}
+
+// print writes one event as a JSON object or a line of text
+func (w *watcher) print(e Event) error {
+	if w.json {
+		return json.NewEncoder(w.out).Encode(e)
+	}
+	line := fmt.Sprintf("%s %-6s %s %s", e.Time.Format("15:04:05"), e.Event, e.MAC, e.Name)
+	if e.RSSI != 0 {
+		line += fmt.Sprintf(" (%d dBm)", e.RSSI)
+	}
+	if e.Rule != "" {
+		line += " [" + e.Rule + "]"
+	}
+	_, err := fmt.Fprintln(w.out, line)
+	return err
+}
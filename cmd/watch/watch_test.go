@@ -0,0 +1,327 @@
+package watch
+
+import (
+	"btui/internal/battery"
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/hooks"
+	"btui/internal/notify"
+	"btui/internal/presence"
+	"btui/internal/state"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// discovered returns a discovered device with a signal reading
+func discovered(mac, name string, rssi int) bluetooth.DiscoveredDevice {
+	return bluetooth.DiscoveredDevice{
+		BluetoothDevice: bluetooth.BluetoothDevice{MacAddress: mac, Name: name},
+		RSSI:            rssi,
+	}
+}
+
+// eventKinds summarises events as "kind MAC" strings
+func eventKinds(events []Event) string {
+	var parts []string
+	for _, e := range events {
+		parts = append(parts, e.Event+" "+e.MAC)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func TestDiff(t *testing.T) {
+	w := newWatcher(&bytes.Buffer{}, false)
+	now := time.Now()
+
+	events := w.diff([]bluetooth.DiscoveredDevice{
+		discovered("BB:00:00:00:00:02", "Speaker", -70),
+		discovered("AA:00:00:00:00:01", "TV", -60),
+	}, now)
+	if got := eventKinds(events); got != "new AA:00:00:00:00:01, new BB:00:00:00:00:02" {
+		t.Errorf("Unexpected first events: %s", got)
+	}
+
+	// Unchanged readings are quiet, changed ones are reported
+	events = w.diff([]bluetooth.DiscoveredDevice{
+		discovered("AA:00:00:00:00:01", "TV", -60),
+		discovered("BB:00:00:00:00:02", "Speaker", -65),
+	}, now)
+	if got := eventKinds(events); got != "update BB:00:00:00:00:02" {
+		t.Errorf("Unexpected update events: %s", got)
+	}
+
+	events = w.diff([]bluetooth.DiscoveredDevice{
+		discovered("BB:00:00:00:00:02", "Speaker", -65),
+	}, now)
+	if got := eventKinds(events); got != "lost AA:00:00:00:00:01" {
+		t.Errorf("Unexpected lost events: %s", got)
+	}
+	if events[0].Name != "TV" {
+		t.Errorf("Expected the lost device's name, got %q", events[0].Name)
+	}
+}
+
+func TestRespectIgnore(t *testing.T) {
+	store := state.New("")
+	store.AddIgnore(state.IgnoreRule{Kind: state.IgnoreName, Value: "LG*"})
+	store.AddIgnore(state.IgnoreRule{Kind: state.IgnoreManufacturer, Value: "0x004C"})
+
+	w := newWatcher(&bytes.Buffer{}, false)
+	w.ignore = store
+	var lookups atomic.Int32
+	w.manufacturer = func(mac string) (string, error) {
+		lookups.Add(1)
+		if mac == "CC:00:00:00:00:03" {
+			return "0x004C", nil
+		}
+		return "", nil
+	}
+
+	devices := []bluetooth.DiscoveredDevice{
+		discovered("AA:00:00:00:00:01", "LG TV", -60),
+		discovered("BB:00:00:00:00:02", "Speaker", -70),
+		discovered("CC:00:00:00:00:03", "Beacon", -80),
+	}
+	// New devices wait for their manufacturer before being reported
+	if events := w.diff(devices, time.Now()); len(events) != 0 {
+		t.Errorf("Expected devices held back during the lookups, got %v", events)
+	}
+	w.lookups.Wait()
+	if got := eventKinds(w.diff(devices, time.Now())); got != "new BB:00:00:00:00:02" {
+		t.Errorf("Expected ignored devices left out, got %s", got)
+	}
+
+	w.diff(devices, time.Now())
+	w.lookups.Wait()
+	if lookups.Load() != 3 {
+		t.Errorf("Expected one manufacturer lookup per device, got %d", lookups.Load())
+	}
+}
+
+func TestFailedManufacturerLookupRetried(t *testing.T) {
+	store := state.New("")
+	store.AddIgnore(state.IgnoreRule{Kind: state.IgnoreManufacturer, Value: "0x004C"})
+
+	w := newWatcher(&bytes.Buffer{}, false)
+	w.ignore = store
+	fail := true
+	w.manufacturer = func(mac string) (string, error) {
+		if fail {
+			return "", errors.New("timed out")
+		}
+		return "0x004C", nil
+	}
+
+	devices := []bluetooth.DiscoveredDevice{discovered("CC:00:00:00:00:03", "Beacon", -80)}
+	w.diff(devices, time.Now())
+	w.lookups.Wait()
+
+	// Once looked up, even unsuccessfully, the device is reported rather
+	// than held back for good
+	if got := eventKinds(w.diff(devices, time.Now())); got != "new CC:00:00:00:00:03" {
+		t.Errorf("Expected the device reported after a failed lookup, got %s", got)
+	}
+
+	// The failure is not cached: the next lookup finds the manufacturer
+	w.lookups.Wait()
+	fail = false
+	w.diff(devices, time.Now())
+	w.lookups.Wait()
+	if got := eventKinds(w.diff(devices, time.Now())); got != "lost CC:00:00:00:00:03" {
+		t.Errorf("Expected the device hidden once its manufacturer is known, got %s", got)
+	}
+}
+
+func TestIgnoreListUnusedByDefault(t *testing.T) {
+	w := newWatcher(&bytes.Buffer{}, false)
+	w.manufacturer = func(string) (string, error) {
+		t.Error("Expected no manufacturer lookups without --respect-ignore")
+		return "", nil
+	}
+	if events := w.diff([]bluetooth.DiscoveredDevice{discovered("AA:00:00:00:00:01", "LG TV", -60)}, time.Now()); len(events) != 1 {
+		t.Errorf("Expected every device reported, got %v", events)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	at := time.Date(2025, 6, 1, 14, 30, 5, 0, time.UTC)
+
+	var text bytes.Buffer
+	w := newWatcher(&text, false)
+	if err := w.update([]bluetooth.DiscoveredDevice{discovered("AA:00:00:00:00:01", "TV", -60)}, at); err != nil {
+		t.Fatal(err)
+	}
+	if got := text.String(); got != "14:30:05 new    AA:00:00:00:00:01 TV (-60 dBm)\n" {
+		t.Errorf("Unexpected text output %q", got)
+	}
+
+	var out bytes.Buffer
+	w = newWatcher(&out, true)
+	w.update([]bluetooth.DiscoveredDevice{discovered("AA:00:00:00:00:01", "TV", -60)}, at)
+	w.update(nil, at)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one JSON object per event, got %q", out.String())
+	}
+	var event Event
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", lines[1], err)
+	}
+	if event.Event != EventLost || event.MAC != "AA:00:00:00:00:01" || !event.Time.Equal(at) {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if strings.Contains(lines[1], "rssi") {
+		t.Errorf("Expected no signal strength for a lost device, got %s", lines[1])
+	}
+}
+
+func TestConnections(t *testing.T) {
+	var c connections
+	paired := func(connected ...bool) []bluetooth.BluetoothDevice {
+		return []bluetooth.BluetoothDevice{
+			{MacAddress: "BB:00:00:00:00:02", Name: "Mouse", Connected: connected[0]},
+			{MacAddress: "AA:00:00:00:00:01", Name: "Headphones", Connected: connected[1]},
+		}
+	}
+
+	if events := c.update(paired(true, false)); len(events) != 0 {
+		t.Errorf("Expected the first list to set the starting point, got %v", events)
+	}
+	events := c.update(paired(false, true))
+	if len(events) != 2 || events[0].Kind != notify.EventConnected || events[0].Name != "Headphones" ||
+		events[1].Kind != notify.EventDisconnected || events[1].MAC != "BB:00:00:00:00:02" {
+		t.Errorf("Unexpected events %+v", events)
+	}
+	if events := c.update(paired(false, true)); len(events) != 0 {
+		t.Errorf("Expected no events without changes, got %v", events)
+	}
+}
+
+func TestLowBatteries(t *testing.T) {
+	monitor := battery.NewMonitor([]int{20, 10})
+	devices := []bluetooth.BluetoothDevice{
+		{MacAddress: "BB:00:00:00:00:02", Name: "Mouse", Connected: true, Battery: 9, HasBattery: true},
+		{MacAddress: "AA:00:00:00:00:01", Name: "Headphones", Connected: true, Battery: 19, HasBattery: true},
+		{MacAddress: "CC:00:00:00:00:03", Name: "Keyboard", Connected: true, Battery: 70, HasBattery: true},
+		{MacAddress: "DD:00:00:00:00:04", Name: "Speaker", Battery: 5, HasBattery: true},
+	}
+
+	warnings := lowBatteries(monitor, devices)
+	if len(warnings) != 2 || warnings[0].Name != "Headphones" || warnings[0].Threshold != 20 ||
+		warnings[1].Name != "Mouse" || warnings[1].Threshold != 10 {
+		t.Errorf("Unexpected warnings %+v", warnings)
+	}
+	if warnings := lowBatteries(monitor, devices); len(warnings) != 0 {
+		t.Errorf("Expected each device warned once, got %+v", warnings)
+	}
+}
+
+func TestBatteryChanges(t *testing.T) {
+	charges := make(map[string]int)
+	devices := []bluetooth.BluetoothDevice{
+		{MacAddress: "BB:00:00:00:00:02", Name: "Mouse", Connected: true, Battery: 40, HasBattery: true},
+		{MacAddress: "AA:00:00:00:00:01", Name: "Headphones", Connected: true, Battery: 80, HasBattery: true},
+		{MacAddress: "CC:00:00:00:00:03", Name: "Keyboard", Connected: true},
+	}
+
+	changed := batteryChanges(charges, devices)
+	if len(changed) != 2 || changed[0].Name != "Headphones" || changed[1].Name != "Mouse" {
+		t.Errorf("Expected every known charge reported first, got %+v", changed)
+	}
+
+	devices[0].Battery = 39
+	devices[1].Connected = false
+	if changed := batteryChanges(charges, devices); len(changed) != 1 || changed[0].Battery != 39 {
+		t.Errorf("Expected only the mouse reported, got %+v", changed)
+	}
+	if _, ok := charges["AA:00:00:00:00:01"]; ok {
+		t.Error("Expected a disconnected device's charge forgotten")
+	}
+}
+
+func TestRSSIForHooks(t *testing.T) {
+	w := newWatcher(&bytes.Buffer{}, false)
+	w.diff([]bluetooth.DiscoveredDevice{discovered("AA:00:00:00:00:01", "Headphones", -58)}, time.Now())
+
+	// Connection and battery hooks take the last reading of a device in range
+	if rssi := w.rssi("AA:00:00:00:00:01"); rssi != -58 {
+		t.Errorf("Expected -58 dBm, got %d", rssi)
+	}
+	if rssi := w.rssi("BB:00:00:00:00:02"); rssi != 0 {
+		t.Errorf("Expected no reading for a device not heard, got %d", rssi)
+	}
+}
+
+func TestDiscoverHooks(t *testing.T) {
+	cfg := config.Default()
+	cfg.Hooks.Run = []config.Hook{{Event: config.HookDiscover, Device: "name:phone", Command: `echo "$BTUI_MAC $BTUI_RSSI"`}}
+
+	var mu sync.Mutex
+	var results []hooks.Result
+	w := newWatcher(&bytes.Buffer{}, false)
+	w.hooks = hooks.NewRunner(cfg)
+	w.report = func(r hooks.Result) {
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, r)
+	}
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	devices := []bluetooth.DiscoveredDevice{discovered("AA:00:00:00:00:01", "Phone", -50), discovered("BB:00:00:00:00:02", "Speaker", -60)}
+	if err := w.update(devices, at); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	devices[0].RSSI = -40
+	if err := w.update(devices, at.Add(time.Second)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	w.hooks.Wait()
+
+	if len(results) != 1 || strings.TrimSpace(results[0].Output) != "AA:00:00:00:00:01 -50" {
+		t.Errorf("Expected one discover hook for the phone, got %+v", results)
+	}
+}
+
+func TestPresenceEvents(t *testing.T) {
+	state.Set(state.New(""))
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := at
+	rules := []config.PresenceRule{{Name: "phone", Device: "name:pixel", Enter: -70, Exit: -80, Timeout: time.Minute}}
+
+	var out bytes.Buffer
+	w := newWatcher(&out, true)
+	w.presence = presence.NewEvaluator(rules, func() time.Time { return now })
+
+	phone := discovered("AA:00:00:00:00:01", "Pixel", -60)
+	phone.Timestamp = at
+	if err := w.update([]bluetooth.DiscoveredDevice{phone}, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Still listed by the scanner, but not heard from for a minute
+	now = at.Add(time.Minute)
+	if err := w.update([]bluetooth.DiscoveredDevice{phone}, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var events []Event
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var e Event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", line, err)
+		}
+		events = append(events, e)
+	}
+	if eventKinds(events) != "new AA:00:00:00:00:01, present AA:00:00:00:00:01, absent AA:00:00:00:00:01" {
+		t.Fatalf("Unexpected events %s", eventKinds(events))
+	}
+	if events[1].Rule != "phone" || events[1].RSSI != -60 || !events[2].Time.Equal(now) {
+		t.Errorf("Unexpected presence events %+v", events[1:])
+	}
+}
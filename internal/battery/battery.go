@@ -0,0 +1,69 @@
+// Package battery follows the charge of connected devices and decides when a
+// low one is worth a warning
+package battery
+
+import (
+	"sync"
+)
+
+// Warning is a device's charge falling to or below a threshold
+type Warning struct {
+	MAC     string
+	Name    string
+	Percent int
+	// Threshold is the lowest threshold the charge is at or below
+	Threshold int
+}
+
+// Monitor remembers which threshold each device was last warned about, so a
+// device is warned once per threshold on the way down and again only after
+// it has been charged back above it
+type Monitor struct {
+	thresholds []int
+
+	mu     sync.Mutex
+	warned map[string]int
+}
+
+// NewMonitor returns a monitor warning at the given thresholds, in percent
+func NewMonitor(thresholds []int) *Monitor {
+	return &Monitor{thresholds: append([]int(nil), thresholds...), warned: make(map[string]int)}
+}
+
+// lowest returns the lowest of the thresholds that percent is at or below
+func lowest(percent int, thresholds []int) (int, bool) {
+	found := false
+	result := 0
+	for _, t := range thresholds {
+		if percent <= t && (!found || t < result) {
+			result, found = t, true
+		}
+	}
+	return result, found
+}
+
+// Low reports whether a known charge is at or below any of the thresholds
+func Low(percent int, thresholds []int) bool {
+	_, low := lowest(percent, thresholds)
+	return low
+}
+
+// Update records a device's known charge and returns a warning when it has fallen
+// to a lower threshold than the device was last warned about
+func (m *Monitor) Update(mac, name string, percent int) (Warning, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, low := lowest(percent, m.thresholds)
+	if !low {
+		// Charged above every threshold: warn afresh next time it runs low
+		delete(m.warned, mac)
+		return Warning{}, false
+	}
+	last, warned := m.warned[mac]
+	m.warned[mac] = t
+	if warned && t >= last {
+		return Warning{}, false
+	}
+	return Warning{MAC: mac, Name: name, Percent: percent, Threshold: t}, true
+}
@@ -0,0 +1,67 @@
+package battery
+
+import "testing"
+
+func TestMonitorWarnsOncePerThreshold(t *testing.T) {
+	m := NewMonitor([]int{10, 20, 5})
+	const mac = "4C:87:5D:28:86:DD"
+
+	steps := []struct {
+		percent   int
+		warn      bool
+		threshold int
+	}{
+		{80, false, 0},
+		{21, false, 0},
+		{20, true, 20},
+		{18, false, 0},
+		{12, false, 0},
+		{9, true, 10},
+		{9, false, 0},
+		{4, true, 5},
+		{3, false, 0},
+		// Charging back up past a threshold re-arms it
+		{15, false, 0},
+		{9, true, 10},
+		{60, false, 0},
+		{19, true, 20},
+	}
+
+	for i, step := range steps {
+		w, ok := m.Update(mac, "Bose NC 700", step.percent)
+		if ok != step.warn {
+			t.Fatalf("step %d (%d%%): warned = %v, expected %v", i, step.percent, ok, step.warn)
+		}
+		if ok && (w.Threshold != step.threshold || w.Percent != step.percent || w.MAC != mac) {
+			t.Errorf("step %d: unexpected warning %+v", i, w)
+		}
+	}
+}
+
+func TestMonitorFirstReadingBelowThreshold(t *testing.T) {
+	m := NewMonitor([]int{20, 10})
+
+	w, ok := m.Update("AA:BB:CC:DD:EE:FF", "Mouse", 7)
+	if !ok || w.Threshold != 10 {
+		t.Errorf("Expected a warning at the 10%% threshold, got %+v (%v)", w, ok)
+	}
+	if _, ok := m.Update("11:22:33:44:55:66", "Keyboard", 50); ok {
+		t.Error("Expected no warning for a charged device")
+	}
+	if w, ok := m.Update("AA:BB:CC:DD:EE:00", "Headset", 0); !ok || w.Percent != 0 || w.Threshold != 10 {
+		t.Errorf("Expected an empty battery warned about, got %+v (%v)", w, ok)
+	}
+}
+
+func TestLow(t *testing.T) {
+	thresholds := []int{10, 20}
+	if !Low(20, thresholds) || !Low(3, thresholds) || !Low(0, thresholds) {
+		t.Error("Expected 20%, 3% and an empty battery to be low")
+	}
+	if Low(21, thresholds) {
+		t.Error("Expected 21% not to be low")
+	}
+	if Low(1, nil) {
+		t.Error("Expected nothing to be low without thresholds")
+	}
+}
@@ -0,0 +1,112 @@
+package bluetooth
+
+import (
+	"btui/internal/config"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/godbus/dbus/v5"
+)
+
+// BlueZ D-Bus names used to set aliases
+const (
+	bluezService     = "org.bluez"
+	bluezDevice      = "org.bluez.Device1"
+	bluezAdapter     = "org.bluez.Adapter1"
+	objectManager    = "org.freedesktop.DBus.ObjectManager.GetManagedObjects"
+	propertiesSetter = "org.freedesktop.DBus.Properties.Set"
+)
+
+// managedObjects is the reply of ObjectManager.GetManagedObjects: object
+// path -> interface -> property -> value
+type managedObjects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+
+// AliasMsg is sent when setting a device's alias completes. Info holds the
+// device's properties after the change.
+type AliasMsg struct {
+	Device BluetoothDevice
+	Info   DeviceInfo
+	Err    error
+}
+
+// SetAliasCmd returns a command that sets a device's alias; an empty alias
+// reverts to the name the device advertises
+func SetAliasCmd(device BluetoothDevice, alias string) tea.Cmd {
+	return func() tea.Msg {
+		if err := SetAlias(device.MacAddress, alias); err != nil {
+			return AliasMsg{Device: device, Err: err}
+		}
+		info, err := FetchDeviceInfo(device.MacAddress)
+		if err != nil {
+			err = fmt.Errorf("alias set, but reading it back failed: %w", err)
+		}
+		return AliasMsg{Device: device, Info: info, Err: err}
+	}
+}
+
+// SetAlias sets the Alias property of a device through BlueZ on the system
+// bus, on the configured controller if there is one. Other Bluetooth tools
+// see the new name too. An empty alias reverts to the remote name.
+func SetAlias(macAddress, alias string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.Fetch)
+	defer cancel()
+
+	conn, err := dbus.ConnectSystemBus(dbus.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("cannot reach BlueZ: %w", err)
+	}
+	defer conn.Close()
+
+	var objects managedObjects
+	if err := conn.Object(bluezService, "/").CallWithContext(ctx, objectManager, 0).Store(&objects); err != nil {
+		return fmt.Errorf("cannot list BlueZ devices: %w", err)
+	}
+	path, err := devicePath(objects, macAddress, config.Get().Adapter)
+	if err != nil {
+		return err
+	}
+
+	call := conn.Object(bluezService, path).CallWithContext(ctx, propertiesSetter, 0,
+		bluezDevice, "Alias", dbus.MakeVariant(alias))
+	if call.Err != nil {
+		return fmt.Errorf("set alias: %w", call.Err)
+	}
+	return nil
+}
+
+// devicePath finds the BlueZ object of a device, on the given controller
+// when adapter is not empty
+func devicePath(objects managedObjects, macAddress, adapter string) (dbus.ObjectPath, error) {
+	for path, ifaces := range objects {
+		props, ok := ifaces[bluezDevice]
+		if !ok || !strings.EqualFold(variantString(props["Address"]), macAddress) {
+			continue
+		}
+		if adapter != "" {
+			adapterPath, _ := props["Adapter"].Value().(dbus.ObjectPath)
+			if !adapterMatches(objects, adapterPath, adapter) {
+				continue
+			}
+		}
+		return path, nil
+	}
+	return "", fmt.Errorf("device %s not found", macAddress)
+}
+
+// adapterMatches reports whether the controller at adapterPath is the
+// configured one, given by its address or by its name such as "hci0"
+func adapterMatches(objects managedObjects, adapterPath dbus.ObjectPath, adapter string) bool {
+	if strings.EqualFold(variantString(objects[adapterPath][bluezAdapter]["Address"]), adapter) {
+		return true
+	}
+	return adapterPath.IsValid() && path.Base(string(adapterPath)) == adapter
+}
+
+// variantString returns a string property, or "" for other types
+func variantString(v dbus.Variant) string {
+	s, _ := v.Value().(string)
+	return s
+}
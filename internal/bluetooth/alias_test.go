@@ -0,0 +1,76 @@
+package bluetooth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// bluezObjects returns managed objects for two controllers that both know
+// the same device
+func bluezObjects() managedObjects {
+	device := func(adapter string) map[string]map[string]dbus.Variant {
+		return map[string]map[string]dbus.Variant{
+			bluezDevice: {
+				"Address": dbus.MakeVariant("4C:87:5D:28:86:DD"),
+				"Adapter": dbus.MakeVariant(dbus.ObjectPath(adapter)),
+			},
+		}
+	}
+	adapter := func(address string) map[string]map[string]dbus.Variant {
+		return map[string]map[string]dbus.Variant{
+			bluezAdapter: {"Address": dbus.MakeVariant(address)},
+		}
+	}
+	return managedObjects{
+		"/org/bluez/hci0":                       adapter("00:1A:7D:DA:71:13"),
+		"/org/bluez/hci1":                       adapter("00:1A:7D:DA:71:14"),
+		"/org/bluez/hci0/dev_4C_87_5D_28_86_DD": device("/org/bluez/hci0"),
+		"/org/bluez/hci1/dev_4C_87_5D_28_86_DD": device("/org/bluez/hci1"),
+	}
+}
+
+func TestDevicePath(t *testing.T) {
+	objects := bluezObjects()
+
+	path, err := devicePath(objects, "4c:87:5d:28:86:dd", "00:1A:7D:DA:71:14")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if path != "/org/bluez/hci1/dev_4C_87_5D_28_86_DD" {
+		t.Errorf("Expected the device on the configured controller, got %s", path)
+	}
+
+	if path, err := devicePath(objects, "4C:87:5D:28:86:DD", ""); err != nil || !strings.HasSuffix(string(path), "dev_4C_87_5D_28_86_DD") {
+		t.Errorf("Expected the device on any controller, got %s (%v)", path, err)
+	}
+}
+
+func TestDevicePathByControllerName(t *testing.T) {
+	objects := bluezObjects()
+
+	for _, adapter := range []string{"hci0", "hci1"} {
+		path, err := devicePath(objects, "4C:87:5D:28:86:DD", adapter)
+		if err != nil {
+			t.Fatalf("adapter = %q: unexpected error: %v", adapter, err)
+		}
+		if expected := dbus.ObjectPath("/org/bluez/" + adapter + "/dev_4C_87_5D_28_86_DD"); path != expected {
+			t.Errorf("adapter = %q: expected %s, got %s", adapter, expected, path)
+		}
+	}
+	if _, err := devicePath(objects, "4C:87:5D:28:86:DD", "hci2"); err == nil {
+		t.Error("Expected an error for a controller name that does not exist")
+	}
+}
+
+func TestDevicePathNotFound(t *testing.T) {
+	objects := bluezObjects()
+
+	if _, err := devicePath(objects, "AA:BB:CC:DD:EE:FF", ""); err == nil {
+		t.Error("Expected an error for an unknown device")
+	}
+	if _, err := devicePath(objects, "4C:87:5D:28:86:DD", "11:22:33:44:55:66"); err == nil {
+		t.Error("Expected an error when the device is not on the configured controller")
+	}
+}
@@ -0,0 +1,48 @@
+package bluetooth
+
+import (
+	"btui/internal/config"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ParseBatteryPercentage reads a Battery1 "Percentage" value the way
+// bluetoothctl prints it: "0x5a (90)" from `info` and property changes, or a
+// bare "0x5a", "90" or "90%". It reports false for anything that is not a
+// percentage.
+func ParseBatteryPercentage(value string) (int, bool) {
+	value = strings.TrimSpace(value)
+
+	// Both forms are given: the decimal one in brackets is the easier read
+	if open := strings.Index(value, "("); open >= 0 {
+		if end := strings.Index(value[open:], ")"); end > 0 {
+			value = value[open+1 : open+end]
+		}
+	}
+	value = strings.TrimSuffix(strings.TrimSpace(value), "%")
+
+	var percent int64
+	var err error
+	if hex, ok := strings.CutPrefix(strings.ToLower(value), "0x"); ok {
+		percent, err = strconv.ParseInt(hex, 16, 64)
+	} else {
+		percent, err = strconv.ParseInt(value, 10, 64)
+	}
+	if err != nil || percent < 0 || percent > 100 {
+		return 0, false
+	}
+	return int(percent), true
+}
+
+// BatteryTickMsg is sent periodically to read the battery of connected devices
+type BatteryTickMsg time.Time
+
+// BatteryTickCmd returns a command that sends the next BatteryTickMsg
+func BatteryTickCmd() tea.Cmd {
+	return tea.Tick(config.Get().Battery.Interval, func(t time.Time) tea.Msg {
+		return BatteryTickMsg(t)
+	})
+}
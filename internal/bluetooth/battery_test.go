@@ -0,0 +1,30 @@
+package bluetooth
+
+import "testing"
+
+func TestParseBatteryPercentage(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int
+		ok       bool
+	}{
+		{"0x5a (90)", 90, true},
+		{"0x64 (100)", 100, true},
+		{"0x05 (5)", 5, true},
+		{"0x5A", 90, true},
+		{"90", 90, true},
+		{" 42% ", 42, true},
+		{"0x00 (0)", 0, true},
+		{"0x7f (127)", 0, false},
+		{"-1", 0, false},
+		{"full", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		percent, ok := ParseBatteryPercentage(tt.value)
+		if percent != tt.expected || ok != tt.ok {
+			t.Errorf("ParseBatteryPercentage(%q) = %d, %v, expected %d, %v", tt.value, percent, ok, tt.expected, tt.ok)
+		}
+	}
+}
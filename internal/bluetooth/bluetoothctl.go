@@ -0,0 +1,45 @@
+package bluetooth
+
+import (
+	"btui/internal/config"
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// Bluetoothctl returns a bluetoothctl command on the configured controller.
+// bluetoothctl takes no option naming the controller, so when one is set the
+// command is fed on stdin after selecting it; bluetoothctl runs such a script
+// one command at a time, waiting for each to finish.
+func Bluetoothctl(ctx context.Context, args ...string) *exec.Cmd {
+	line := selectController()
+	if line == "" {
+		return exec.CommandContext(ctx, "bluetoothctl", args...)
+	}
+	cmd := exec.CommandContext(ctx, "bluetoothctl")
+	cmd.Stdin = strings.NewReader(line + "\n" + scriptLine(args) + "\n")
+	return cmd
+}
+
+// selectController returns the bluetoothctl command selecting the
+// configured controller, or "" to use the default one
+func selectController() string {
+	adapter := config.Get().Adapter
+	if adapter == "" {
+		return ""
+	}
+	return "select " + adapter
+}
+
+// scriptLine joins arguments into one bluetoothctl command line, quoting
+// those that are empty or contain spaces
+func scriptLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"") {
+			arg = `"` + strings.ReplaceAll(arg, `"`, `\"`) + `"`
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,56 @@
+package bluetooth
+
+import (
+	"btui/internal/config"
+	"context"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestBluetoothctlDefaultController(t *testing.T) {
+	config.Set(config.Default())
+
+	cmd := Bluetoothctl(context.Background(), "connect", "4C:87:5D:28:86:DD")
+	if !slices.Equal(cmd.Args, []string{"bluetoothctl", "connect", "4C:87:5D:28:86:DD"}) || cmd.Stdin != nil {
+		t.Errorf("Expected the command as arguments, got %v", cmd.Args)
+	}
+}
+
+func TestBluetoothctlSelectsController(t *testing.T) {
+	cfg := config.Default()
+	cfg.Adapter = "hci1"
+	config.Set(cfg)
+	t.Cleanup(func() { config.Set(config.Default()) })
+
+	cmd := Bluetoothctl(context.Background(), "devices", "Connected")
+	if !slices.Equal(cmd.Args, []string{"bluetoothctl"}) {
+		t.Fatalf("Expected the command on stdin, got arguments %v", cmd.Args)
+	}
+	script, _ := io.ReadAll(cmd.Stdin)
+	if expected := "select hci1\ndevices Connected\n"; string(script) != expected {
+		t.Errorf("Expected script %q, got %q", expected, script)
+	}
+}
+
+func TestScriptLineQuotes(t *testing.T) {
+	if got := scriptLine([]string{"set-alias", ""}); got != `set-alias ""` {
+		t.Errorf("Expected an empty argument quoted, got %s", got)
+	}
+	if got := scriptLine([]string{"set-alias", `Desk "big" speaker`}); got != `set-alias "Desk \"big\" speaker"` {
+		t.Errorf("Expected spaces and quotes kept, got %s", got)
+	}
+}
+
+func TestDeviceLinesSkipsScriptNoise(t *testing.T) {
+	output := "\x1b[0;94m[bluetooth]\x1b[0m# select hci1\n" +
+		"Device 4C:87:5D:28:86:DD Bose NC 700 Headphones\n" +
+		"[CHG] Controller 00:1A:7D:DA:71:14 Discovering: no\n" +
+		"\n" +
+		"Device DC:2C:26:09:D0:0C Keychron K4\r\n"
+	lines := DeviceLines(output)
+	expected := []string{"Device 4C:87:5D:28:86:DD Bose NC 700 Headphones", "Device DC:2C:26:09:D0:0C Keychron K4"}
+	if !slices.Equal(lines, expected) {
+		t.Errorf("Expected %q, got %q", expected, lines)
+	}
+}
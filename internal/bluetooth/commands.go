@@ -1,8 +1,10 @@
 package bluetooth
 
 import (
+	"btui/internal/config"
 	"context"
-	"os/exec"
+	"errors"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,8 +15,11 @@ import (
 type ConnectResult struct {
 	Device  BluetoothDevice
 	Success bool
-	Output  string
-	Err     error
+	// Cancelled is set when the connect was called off before it finished,
+	// which is not a failure of the device
+	Cancelled bool
+	Output    string
+	Err       error
 }
 
 // ConnectMsg is sent when a connect operation completes
@@ -22,12 +27,19 @@ type ConnectMsg ConnectResult
 
 // ConnectCmd returns a command that connects to a Bluetooth device
 func ConnectCmd(device BluetoothDevice) tea.Cmd {
+	return ConnectCmdContext(context.Background(), device)
+}
+
+// ConnectCmdContext returns a command that connects to a Bluetooth device
+// until ctx is cancelled. A cancelled connect is aborted with bluetoothctl
+// rather than left to finish in the background.
+func ConnectCmdContext(parent context.Context, device BluetoothDevice) tea.Cmd {
 	return func() tea.Msg {
 		// Create a context with timeout to prevent hanging
-		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		ctx, cancel := context.WithTimeout(parent, config.Get().Timeouts.Connect)
 		defer cancel()
 
-		cmd := exec.CommandContext(ctx, "bluetoothctl", "connect", device.MacAddress)
+		cmd := Bluetoothctl(ctx, "connect", device.MacAddress)
 		output, err := cmd.CombinedOutput()
 
 		result := ConnectResult{
@@ -35,32 +47,47 @@ func ConnectCmd(device BluetoothDevice) tea.Cmd {
 			Output: strings.TrimSpace(string(output)),
 			Err:    err,
 		}
-
-		// Check if connection was successful
-		// bluetoothctl can output various success messages:
-		// - "Connection successful"
-		// - "Device XX:XX:XX:XX:XX:XX connected"
-		// - Or just exit with code 0
-		lowerOutput := strings.ToLower(result.Output)
-		if err == nil && (strings.Contains(lowerOutput, "successful") || 
-		                  strings.Contains(lowerOutput, "connected") ||
-		                  result.Output == "") {
-			// Also ensure it's not an error message containing "connected"
-			if !strings.Contains(lowerOutput, "failed") && !strings.Contains(lowerOutput, "error") {
-				result.Success = true
-			}
+		if cancelled(parent) {
+			abortConnect(device)
+			result.Cancelled = true
+			return ConnectMsg(result)
 		}
 
+		result.Success = commandSucceeded(result.Output, err, connectSuccess...)
+		if !result.Success {
+			result.Err = NewCommandError(result.Output, err)
+		}
 		return ConnectMsg(result)
 	}
 }
 
+// cancelled reports whether ctx was called off by its owner, as opposed to
+// running out of time
+func cancelled(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.Canceled)
+}
+
+// abortConnect stops a connect that was cancelled halfway. Disconnecting
+// drops a pending connection, and cancel-pairing stops the pairing a connect
+// to an unpaired device may have started.
+func abortConnect(device BluetoothDevice) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.Disconnect)
+	defer cancel()
+
+	if !device.Paired {
+		Bluetoothctl(ctx, "cancel-pairing", device.MacAddress).Run()
+	}
+	Bluetoothctl(ctx, "disconnect", device.MacAddress).Run()
+}
+
 // DisconnectResult represents the result of a disconnect operation
 type DisconnectResult struct {
 	Device  BluetoothDevice
 	Success bool
-	Output  string
-	Err     error
+	// Cancelled is set when the disconnect was called off before it finished
+	Cancelled bool
+	Output    string
+	Err       error
 }
 
 // DisconnectMsg is sent when a disconnect operation completes
@@ -68,12 +95,19 @@ type DisconnectMsg DisconnectResult
 
 // DisconnectCmd returns a command that disconnects from a Bluetooth device
 func DisconnectCmd(device BluetoothDevice) tea.Cmd {
+	return DisconnectCmdContext(context.Background(), device)
+}
+
+// DisconnectCmdContext returns a command that disconnects from a Bluetooth
+// device until ctx is cancelled. There is nothing to undo then: the device
+// may still finish disconnecting, but bluetoothctl is no longer waited for.
+func DisconnectCmdContext(parent context.Context, device BluetoothDevice) tea.Cmd {
 	return func() tea.Msg {
 		// Create a context with timeout to prevent hanging
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(parent, config.Get().Timeouts.Disconnect)
 		defer cancel()
 
-		cmd := exec.CommandContext(ctx, "bluetoothctl", "disconnect", device.MacAddress)
+		cmd := Bluetoothctl(ctx, "disconnect", device.MacAddress)
 		output, err := cmd.CombinedOutput()
 
 		result := DisconnectResult{
@@ -81,26 +115,105 @@ func DisconnectCmd(device BluetoothDevice) tea.Cmd {
 			Output: strings.TrimSpace(string(output)),
 			Err:    err,
 		}
-
-		// Check if disconnection was successful
-		// bluetoothctl can output various success messages:
-		// - "Successful disconnected"
-		// - "Device XX:XX:XX:XX:XX:XX disconnected"
-		// - Or just exit with code 0
-		lowerOutput := strings.ToLower(result.Output)
-		if err == nil && (strings.Contains(lowerOutput, "successful") || 
-		                  strings.Contains(lowerOutput, "disconnected") ||
-		                  result.Output == "") {
-			// Also ensure it's not an error message containing "disconnected"
-			if !strings.Contains(lowerOutput, "failed") && !strings.Contains(lowerOutput, "error") {
-				result.Success = true
-			}
+		if cancelled(parent) {
+			result.Cancelled = true
+			return DisconnectMsg(result)
 		}
 
+		result.Success = commandSucceeded(result.Output, err, disconnectSuccess...)
+		if !result.Success {
+			result.Err = NewCommandError(result.Output, err)
+		}
 		return DisconnectMsg(result)
 	}
 }
 
+// TrustResult represents the result of a trust operation
+type TrustResult struct {
+	Device  BluetoothDevice
+	Success bool
+	Output  string
+	Err     error
+}
+
+// TrustMsg is sent when a trust operation completes
+type TrustMsg TrustResult
+
+// TrustCmd returns a command that marks a device as trusted, so it may
+// reconnect on its own
+func TrustCmd(device BluetoothDevice) tea.Cmd {
+	return func() tea.Msg {
+		// Trusting is as quick as disconnecting, so it shares that timeout
+		ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.Disconnect)
+		defer cancel()
+
+		output, err := Bluetoothctl(ctx, "trust", device.MacAddress).CombinedOutput()
+		result := TrustResult{
+			Device: device,
+			Output: strings.TrimSpace(string(output)),
+			Err:    err,
+		}
+		// bluetoothctl prints "Changing XX:XX:XX:XX:XX:XX trust succeeded"
+		result.Success = commandSucceeded(result.Output, err, "succeeded")
+		if !result.Success {
+			result.Err = NewCommandError(result.Output, err)
+		}
+		return TrustMsg(result)
+	}
+}
+
+// RemoveResult represents the result of a remove operation
+type RemoveResult struct {
+	Device  BluetoothDevice
+	Success bool
+	Output  string
+	Err     error
+}
+
+// RemoveMsg is sent when a remove operation completes
+type RemoveMsg RemoveResult
+
+// RemoveCmd returns a command that unpairs a device and forgets it
+func RemoveCmd(device BluetoothDevice) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.Disconnect)
+		defer cancel()
+
+		output, err := Bluetoothctl(ctx, "remove", device.MacAddress).CombinedOutput()
+		result := RemoveResult{
+			Device: device,
+			Output: strings.TrimSpace(string(output)),
+			Err:    err,
+		}
+		// bluetoothctl prints "Device has been removed"
+		result.Success = commandSucceeded(result.Output, err, "removed")
+		if !result.Success {
+			result.Err = NewCommandError(result.Output, err)
+		}
+		return RemoveMsg(result)
+	}
+}
+
+// Words bluetoothctl prints on success: "Connection successful" or "Device
+// XX:XX:XX:XX:XX:XX connected", and "Successful disconnected" or "Device
+// XX:XX:XX:XX:XX:XX disconnected"
+var (
+	connectSuccess    = []string{"successful", "connected"}
+	disconnectSuccess = []string{"successful", "disconnected"}
+)
+
+// commandSucceeded reports whether bluetoothctl exited cleanly and printed
+// one of the success words, or nothing at all, without reporting a failure
+func commandSucceeded(output string, err error, words ...string) bool {
+	if err != nil || Classify(output) != 0 {
+		return false
+	}
+	lower := strings.ToLower(output)
+	return output == "" || slices.ContainsFunc(words, func(word string) bool {
+		return strings.Contains(lower, word)
+	})
+}
+
 // ScanResult represents the result of a scan operation
 type ScanResult struct {
 	Success bool
@@ -118,10 +231,10 @@ type ScanStopMsg ScanResult
 func StartScanCmd() tea.Cmd {
 	return func() tea.Msg {
 		// Create a context with timeout to prevent hanging
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.Scan)
 		defer cancel()
 
-		cmd := exec.CommandContext(ctx, "bluetoothctl", "scan", "on")
+		cmd := Bluetoothctl(ctx, "scan", "on")
 		output, err := cmd.CombinedOutput()
 
 		result := ScanResult{
@@ -142,10 +255,10 @@ func StartScanCmd() tea.Cmd {
 func StopScanCmd() tea.Cmd {
 	return func() tea.Msg {
 		// Create a context with timeout to prevent hanging
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.Scan)
 		defer cancel()
 
-		cmd := exec.CommandContext(ctx, "bluetoothctl", "scan", "off")
+		cmd := Bluetoothctl(ctx, "scan", "off")
 		output, err := cmd.CombinedOutput()
 
 		result := ScanResult{
@@ -162,13 +275,13 @@ func StopScanCmd() tea.Cmd {
 	}
 }
 
-// TickMsg is sent periodically during scanning to update device list
-type TickMsg time.Time
+// DevicesTickMsg is sent periodically to relist the paired devices
+type DevicesTickMsg time.Time
 
-// TickCmd returns a command that sends periodic ticks for updating device list
-func TickCmd() tea.Cmd {
-	return tea.Tick(time.Millisecond*500, func(t time.Time) tea.Msg {
-		return TickMsg(t)
+// DevicesTickCmd returns a command that sends the next DevicesTickMsg
+func DevicesTickCmd() tea.Cmd {
+	return tea.Tick(config.Get().Intervals.Devices, func(t time.Time) tea.Msg {
+		return DevicesTickMsg(t)
 	})
 }
 
@@ -177,7 +290,7 @@ type UIUpdateMsg struct{}
 
 // UIUpdateCmd returns a command that sends periodic UI updates during operations
 func UIUpdateCmd() tea.Cmd {
-	return tea.Tick(time.Millisecond*200, func(t time.Time) tea.Msg {
+	return tea.Tick(config.Get().Intervals.UIUpdate, func(t time.Time) tea.Msg {
 		return UIUpdateMsg{}
 	})
 }
@@ -1,99 +1,195 @@
 package bluetooth
 
 import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
-func TestDisconnectSuccessDetection(t *testing.T) {
-	testCases := []struct {
-		name     string
-		output   string
-		hasError bool
-		expected bool
-	}{
-		{"Successful disconnected", "Successful disconnected", false, true},
-		{"Device disconnected", "Device AA:BB:CC:DD:EE:FF disconnected", false, true},
-		{"Empty output success", "", false, true},
-		{"Failed to disconnect", "Failed to disconnect", false, false},
-		{"Error with disconnected", "Error: Device not connected", false, false},
-		{"Command error", "Successful disconnected", true, false},
-		{"Mixed case success", "DEVICE AA:BB:CC:DD:EE:FF DISCONNECTED", false, true},
+// outputCorpus lists recorded bluetoothctl output in testdata/bluetoothctl,
+// with the class of failure each reports and whether the command it came
+// from succeeded
+var outputCorpus = []struct {
+	file    string
+	class   ErrorClass
+	words   []string
+	success bool
+}{
+	{"connect_successful", 0, connectSuccess, true},
+	{"connect_already_connected", 0, connectSuccess, true},
+	{"connect_page_timeout", ErrPageTimeout, connectSuccess, false},
+	{"connect_in_progress", ErrInProgress, connectSuccess, false},
+	{"connect_operation_in_progress", ErrInProgress, connectSuccess, false},
+	{"connect_profile_unavailable", ErrProfileUnavailable, connectSuccess, false},
+	{"connect_not_ready", ErrNotReady, connectSuccess, false},
+	{"connect_adapter_not_powered", ErrNotReady, connectSuccess, false},
+	{"connect_authentication_failed", ErrAuthenticationFailed, connectSuccess, false},
+	{"connect_not_available", ErrNotAvailable, connectSuccess, false},
+	{"connect_unknown_device", ErrDeviceNotAvailable, connectSuccess, false},
+	{"connect_failed", ErrFailed, connectSuccess, false},
+	{"disconnect_successful", 0, disconnectSuccess, true},
+	{"disconnect_not_connected", ErrFailed, disconnectSuccess, false},
+	{"trust_succeeded", 0, []string{"succeeded"}, true},
+	{"remove_removed", 0, []string{"removed"}, true},
+	{"remove_does_not_exist", ErrFailed, []string{"removed"}, false},
+	{"no_daemon", 0, []string{"succeeded"}, false},
+}
+
+func readOutput(t *testing.T, file string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "bluetoothctl", file+".txt"))
+	if err != nil {
+		t.Fatal(err)
 	}
+	// Commands trim the output the same way
+	return strings.TrimSpace(string(data))
+}
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			result := DisconnectResult{
-				Output: tc.output,
-				Err:    nil,
-			}
-			
-			if tc.hasError {
-				result.Err = &testError{}
+func TestClassify(t *testing.T) {
+	for _, tc := range outputCorpus {
+		t.Run(tc.file, func(t *testing.T) {
+			if got := Classify(readOutput(t, tc.file)); got != tc.class {
+				t.Errorf("Classify() = %q, expected %q", got, tc.class)
 			}
+		})
+	}
+}
 
-			// Apply the same logic as in DisconnectCmd
-			lowerOutput := strings.ToLower(result.Output)
-			if result.Err == nil && (strings.Contains(lowerOutput, "successful") || 
-			                         strings.Contains(lowerOutput, "disconnected") ||
-			                         result.Output == "") {
-				if !strings.Contains(lowerOutput, "failed") && !strings.Contains(lowerOutput, "error") {
-					result.Success = true
-				}
+func TestSuccessDetection(t *testing.T) {
+	for _, tc := range outputCorpus {
+		t.Run(tc.file, func(t *testing.T) {
+			output := readOutput(t, tc.file)
+			if got := commandSucceeded(output, nil, tc.words...); got != tc.success {
+				t.Errorf("commandSucceeded() = %v, expected %v", got, tc.success)
 			}
-
-			if result.Success != tc.expected {
-				t.Errorf("Expected success=%v, got success=%v for output: %q", 
-					tc.expected, result.Success, tc.output)
+			// A non-zero exit is a failure whatever was printed
+			if commandSucceeded(output, &testError{}, tc.words...) {
+				t.Error("Expected a command error to fail")
 			}
 		})
 	}
 }
 
-func TestConnectSuccessDetection(t *testing.T) {
+func TestCommandError(t *testing.T) {
+	err := error(NewCommandError(readOutput(t, "connect_not_ready"), &testError{}))
+	if !errors.Is(err, ErrNotReady) || errors.Is(err, ErrPageTimeout) {
+		t.Errorf("Expected the error to match its class only, got %v", err)
+	}
+	var exit *testError
+	if !errors.As(err, &exit) {
+		t.Error("Expected the command error kept")
+	}
+	if err.Error() != "adapter not ready: test error" {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+	if hint := Hint(err); !strings.HasPrefix(hint, "adapter is off") {
+		t.Errorf("Unexpected hint %q", hint)
+	}
+
+	// Output that says nothing useful still fails with the generic class
+	err = NewCommandError("", &testError{})
+	if !errors.Is(err, ErrFailed) || Hint(err) != "" {
+		t.Errorf("Expected a generic failure without a hint, got %v", err)
+	}
+}
+
+func TestHints(t *testing.T) {
+	for class := ErrFailed; class <= ErrDeviceNotAvailable; class++ {
+		if class.Error() == "no error" {
+			t.Errorf("Expected class %d named", int(class))
+		}
+		if class != ErrFailed && class.Hint() == "" {
+			t.Errorf("Expected a hint for %q", class)
+		}
+	}
+}
+
+type testError struct{}
+func (e *testError) Error() string { return "test error" }
+func TestCommandSucceeded(t *testing.T) {
 	testCases := []struct {
 		name     string
 		output   string
-		hasError bool
+		err      error
+		word     string
 		expected bool
 	}{
-		{"Connection successful", "Connection successful", false, true},
-		{"Device connected", "Device AA:BB:CC:DD:EE:FF connected", false, true},
-		{"Empty output success", "", false, true},
-		{"Failed to connect", "Failed to connect", false, false},
-		{"Error with connected", "Error: Device not found", false, false},
-		{"Command error", "Connection successful", true, false},
-		{"Mixed case success", "DEVICE AA:BB:CC:DD:EE:FF CONNECTED", false, true},
+		{"Trust succeeded", "Changing DC:2C:26:09:D0:0C trust succeeded", nil, "succeeded", true},
+		{"Device removed", "[DEL] Device DC:2C:26:09:D0:0C Keychron K4\nDevice has been removed", nil, "removed", true},
+		{"Empty output success", "", nil, "removed", true},
+		{"Unknown device", "Device DC:2C:26:09:D0:0C not available", nil, "removed", false},
+		{"Failed to remove", "Failed to remove device: org.bluez.Error.Failed", nil, "removed", false},
+		{"Command error", "Device has been removed", &testError{}, "removed", false},
+		{"Other output", "Waiting to connect to bluetoothd...", nil, "succeeded", false},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := ConnectResult{
-				Output: tc.output,
-				Err:    nil,
-			}
-			
-			if tc.hasError {
-				result.Err = &testError{}
+			if got := commandSucceeded(tc.output, tc.err, tc.word); got != tc.expected {
+				t.Errorf("commandSucceeded(%q) = %v, expected %v", tc.output, got, tc.expected)
 			}
+		})
+	}
+}
 
-			// Apply the same logic as in ConnectCmd
-			lowerOutput := strings.ToLower(result.Output)
-			if result.Err == nil && (strings.Contains(lowerOutput, "successful") || 
-			                         strings.Contains(lowerOutput, "connected") ||
-			                         result.Output == "") {
-				if !strings.Contains(lowerOutput, "failed") && !strings.Contains(lowerOutput, "error") {
-					result.Success = true
-				}
-			}
+func TestCancelledCommands(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	device := BluetoothDevice{MacAddress: "AA:BB:CC:DD:EE:FF", Name: "Test Device", Paired: true}
 
-			if result.Success != tc.expected {
-				t.Errorf("Expected success=%v, got success=%v for output: %q", 
-					tc.expected, result.Success, tc.output)
-			}
-		})
+	// A cancelled operation is reported as such, never as a failure
+	if result := ConnectCmdContext(ctx, device)().(ConnectMsg); !result.Cancelled || result.Success {
+		t.Errorf("Expected a cancelled connect, got %+v", result)
+	}
+	if result := DisconnectCmdContext(ctx, device)().(DisconnectMsg); !result.Cancelled || result.Success {
+		t.Errorf("Expected a cancelled disconnect, got %+v", result)
 	}
 }
 
-type testError struct{}
-func (e *testError) Error() string { return "test error" }
\ No newline at end of file
+func TestFailureDetails(t *testing.T) {
+	output := readOutput(t, "connect_authentication_failed")
+	op := Operation{
+		Action:   OpConnect,
+		Device:   BluetoothDevice{MacAddress: "4C:87:5D:28:86:DD", Name: "Headphones"},
+		Output:   output,
+		Err:      NewCommandError(output, nil),
+		Started:  time.Unix(100, 0),
+		Finished: time.Unix(103, 0),
+	}
+	details := op.Details()
+	if details.Device != "Headphones (4C:87:5D:28:86:DD)" || details.Duration != 3*time.Second {
+		t.Errorf("Unexpected details %+v", details)
+	}
+	if details.ExitStatus != "0" || details.Cause != "authentication failed" || details.Fix != ErrAuthenticationFailed.Hint() {
+		t.Errorf("Unexpected diagnosis %+v", details)
+	}
+
+	// An operation that never ran has no duration
+	op.Started = time.Time{}
+	if details := op.Details(); details.Duration != 0 {
+		t.Errorf("Expected no duration, got %v", details.Duration)
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	exit := exec.Command("sh", "-c", "exit 2").Run()
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{nil, ""},
+		{NewCommandError("Failed to connect", nil), "0"},
+		{NewCommandError("Failed to connect", exit), "2"},
+		{&testError{}, "test error"},
+	}
+	for _, tc := range tests {
+		if got := ExitStatus(tc.err); got != tc.expected {
+			t.Errorf("ExitStatus(%v) = %q, expected %q", tc.err, got, tc.expected)
+		}
+	}
+}
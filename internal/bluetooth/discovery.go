@@ -1,6 +1,7 @@
 package bluetooth
 
 import (
+	"btui/internal/config"
 	"bufio"
 	"context"
 	"fmt"
@@ -26,14 +27,19 @@ type DiscoveryScanner struct {
 	ctx            context.Context
 	cancel         context.CancelFunc
 	discoveredDevs map[string]DiscoveredDevice
-	mutex          sync.RWMutex
-	isScanning     bool
+	// batteries holds battery levels reported since they were last taken
+	batteries  map[string]int
+	mutex      sync.RWMutex
+	isScanning bool
 }
 
 // DiscoveryUpdateMsg contains discovered devices
 type DiscoveryUpdateMsg struct {
 	Devices []DiscoveredDevice
-	Err     error
+	// Batteries holds the battery levels, in percent by MAC address, that
+	// devices reported since the previous update
+	Batteries map[string]int
+	Err       error
 }
 
 // NewDiscoveryScanner creates a new discovery scanner
@@ -68,6 +74,11 @@ func (ds *DiscoveryScanner) StartDiscovery() error {
 
 	ds.isScanning = true
 
+	// Select the configured controller before scanning, if any
+	if line := selectController(); line != "" {
+		fmt.Fprintln(stdin, line)
+	}
+
 	// Start scanning
 	fmt.Fprintln(stdin, "scan on")
 
@@ -76,79 +87,99 @@ func (ds *DiscoveryScanner) StartDiscovery() error {
 		defer stdin.Close()
 		scanner := bufio.NewScanner(stdout)
 
-		// Regex patterns for parsing bluetoothctl output
-		// Handle ANSI escape codes that bluetoothctl may output
-		deviceRegex := regexp.MustCompile(`\[(?:NEW|CHG)\] Device ([A-Fa-f0-9:]{17}) (.+)`)
-		delDeviceRegex := regexp.MustCompile(`\[DEL\] Device ([A-Fa-f0-9:]{17})`)
-		rssiRegex := regexp.MustCompile(`RSSI: (?:0x[a-fA-F0-9]+ )?\((-?\d+)\)`)
-		nameRegex := regexp.MustCompile(`^([^R]+?)(?:\s+RSSI:|$)`)
-		// Strip ANSI color codes and control characters
-		ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*[mK]|\r`)
-
 		for scanner.Scan() {
-			line := scanner.Text()
-			// Clean line of ANSI escape codes and control characters
-			cleanLine := ansiRegex.ReplaceAllString(line, "")
-			cleanLine = strings.TrimSpace(cleanLine)
-
-			// Handle device deletion
-			if matches := delDeviceRegex.FindStringSubmatch(cleanLine); len(matches) >= 2 {
-				macAddress := matches[1]
-				ds.mutex.Lock()
-				delete(ds.discoveredDevs, macAddress)
-				ds.mutex.Unlock()
-				continue
-			}
-
-			// Parse device discovery/change lines
-			if matches := deviceRegex.FindStringSubmatch(cleanLine); len(matches) >= 3 {
-				macAddress := matches[1]
-				deviceInfo := matches[2]
-
-				// Extract RSSI if present (handle both hex and decimal formats)
-				rssi := 0
-				if rssiMatches := rssiRegex.FindStringSubmatch(deviceInfo); len(rssiMatches) >= 2 {
-					fmt.Sscanf(rssiMatches[1], "%d", &rssi)
-				}
-
-				// Extract device name using regex (everything before RSSI info)
-				name := "Unknown Device"
-				if nameMatches := nameRegex.FindStringSubmatch(deviceInfo); len(nameMatches) >= 2 {
-					name = strings.TrimSpace(nameMatches[1])
-				}
-				if name == "" {
-					name = "Unknown Device"
-				}
-
-				ds.mutex.Lock()
-				// Update existing device or create new one
-				if existing, exists := ds.discoveredDevs[macAddress]; exists {
-					// Update RSSI and timestamp, keep other info
-					existing.RSSI = rssi
-					existing.Timestamp = time.Now()
-					ds.discoveredDevs[macAddress] = existing
-				} else {
-					// Create new device
-					ds.discoveredDevs[macAddress] = DiscoveredDevice{
-						BluetoothDevice: BluetoothDevice{
-							MacAddress: macAddress,
-							Name:       name,
-							RawLine:    cleanLine,
-							Connected:  false,
-							Paired:     false, // These are newly discovered
-						},
-						RSSI:      rssi,
-						Timestamp: time.Now(),
-					}
-				}
-				ds.mutex.Unlock()
-			}
+			ds.handleLine(scanner.Text(), time.Now())
 		}
 	}()
 
 	return nil
 }
 
+// Patterns for parsing bluetoothctl output
+var (
+	deviceRegex    = regexp.MustCompile(`\[(?:NEW|CHG)\] Device ([A-Fa-f0-9:]{17}) (.+)`)
+	delDeviceRegex = regexp.MustCompile(`\[DEL\] Device ([A-Fa-f0-9:]{17})`)
+	batteryRegex   = regexp.MustCompile(`\[CHG\] Device ([A-Fa-f0-9:]{17}) Battery Percentage: (.+)`)
+	rssiRegex      = regexp.MustCompile(`RSSI: (?:0x[a-fA-F0-9]+ )?\((-?\d+)\)`)
+	nameRegex      = regexp.MustCompile(`^([^R]+?)(?:\s+RSSI:|$)`)
+	// Strip ANSI color codes and control characters
+	ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*[mK]|\r`)
+)
+
+// handleLine applies one line of bluetoothctl output to the discovered devices
+func (ds *DiscoveryScanner) handleLine(line string, now time.Time) {
+	// Clean line of ANSI escape codes and control characters
+	cleanLine := ansiRegex.ReplaceAllString(line, "")
+	cleanLine = strings.TrimSpace(cleanLine)
+
+	// Handle device deletion
+	if matches := delDeviceRegex.FindStringSubmatch(cleanLine); len(matches) >= 2 {
+		macAddress := matches[1]
+		ds.mutex.Lock()
+		delete(ds.discoveredDevs, macAddress)
+		ds.mutex.Unlock()
+		return
+	}
+
+	// Battery changes come from connected devices, which need not be
+	// advertising, so they are kept apart from the discovered devices
+	if matches := batteryRegex.FindStringSubmatch(cleanLine); len(matches) >= 3 {
+		if percent, ok := ParseBatteryPercentage(matches[2]); ok {
+			ds.mutex.Lock()
+			if ds.batteries == nil {
+				ds.batteries = make(map[string]int)
+			}
+			ds.batteries[matches[1]] = percent
+			ds.mutex.Unlock()
+		}
+		return
+	}
+
+	// Parse device discovery/change lines
+	if matches := deviceRegex.FindStringSubmatch(cleanLine); len(matches) >= 3 {
+		macAddress := matches[1]
+		deviceInfo := matches[2]
+
+		// Extract RSSI if present (handle both hex and decimal formats)
+		rssi := 0
+		if rssiMatches := rssiRegex.FindStringSubmatch(deviceInfo); len(rssiMatches) >= 2 {
+			fmt.Sscanf(rssiMatches[1], "%d", &rssi)
+		}
+
+		// Extract device name using regex (everything before RSSI info)
+		name := "Unknown Device"
+		if nameMatches := nameRegex.FindStringSubmatch(deviceInfo); len(nameMatches) >= 2 {
+			name = strings.TrimSpace(nameMatches[1])
+		}
+		if name == "" {
+			name = "Unknown Device"
+		}
+
+		ds.mutex.Lock()
+		// Update existing device or create new one
+		if existing, exists := ds.discoveredDevs[macAddress]; exists {
+			// Update RSSI and timestamp, keep other info
+			existing.RSSI = rssi
+			existing.Timestamp = now
+			ds.discoveredDevs[macAddress] = existing
+		} else {
+			// Create new device
+			ds.discoveredDevs[macAddress] = DiscoveredDevice{
+				BluetoothDevice: BluetoothDevice{
+					MacAddress: macAddress,
+					Name:       name,
+					RawLine:    cleanLine,
+					Connected:  false,
+					Paired:     false, // These are newly discovered
+				},
+				RSSI:      rssi,
+				Timestamp: now,
+			}
+		}
+		ds.mutex.Unlock()
+	}
+}
+
 // StopDiscovery stops the scanning process
 func (ds *DiscoveryScanner) StopDiscovery() error {
 	if !ds.isScanning {
@@ -180,6 +211,16 @@ func (ds *DiscoveryScanner) GetDiscoveredDevices() []DiscoveredDevice {
 	return devices
 }
 
+// TakeBatteryChanges returns the battery levels, in percent by MAC address,
+// reported since the previous call
+func (ds *DiscoveryScanner) TakeBatteryChanges() map[string]int {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	changes := ds.batteries
+	ds.batteries = nil
+	return changes
+}
+
 // IsScanning returns whether discovery is currently active
 func (ds *DiscoveryScanner) IsScanning() bool {
 	return ds.isScanning
@@ -192,14 +233,15 @@ func (ds *DiscoveryScanner) ClearDiscoveredDevices() {
 	ds.discoveredDevs = make(map[string]DiscoveredDevice)
 }
 
-// DiscoveryTickCmd returns a command that sends periodic discovery updates
+// DiscoveryTickCmd returns a command that sends the next discovery update
+// after the discovery interval
 func DiscoveryTickCmd(scanner *DiscoveryScanner) tea.Cmd {
-	return func() tea.Msg {
+	return tea.Tick(config.Get().Intervals.Discovery, func(time.Time) tea.Msg {
 		if !scanner.IsScanning() {
 			return nil
 		}
 
 		devices := scanner.GetDiscoveredDevices()
-		return DiscoveryUpdateMsg{Devices: devices}
-	}
+		return DiscoveryUpdateMsg{Devices: devices, Batteries: scanner.TakeBatteryChanges()}
+	})
 }
@@ -0,0 +1,217 @@
+package bluetooth
+
+import (
+	"btui/internal/ui"
+	"errors"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorClass is a kind of failure bluetoothctl reports. Each class is an
+// error itself, so errors.Is(result.Err, ErrPageTimeout) tells a failed
+// result apart by what went wrong. The zero class means no failure.
+type ErrorClass int
+
+const (
+	// ErrFailed is a failure no more specific class describes
+	ErrFailed ErrorClass = iota + 1
+	// ErrNotReady means the adapter is powered off or still starting
+	ErrNotReady
+	// ErrInProgress means the adapter or device is busy with another operation
+	ErrInProgress
+	// ErrAuthenticationFailed means pairing was refused, cancelled or timed out
+	ErrAuthenticationFailed
+	// ErrProfileUnavailable means no profile this computer offers suits the device
+	ErrProfileUnavailable
+	// ErrPageTimeout means the device did not answer the connection request
+	ErrPageTimeout
+	// ErrNotAvailable means BlueZ cannot do the operation right now
+	ErrNotAvailable
+	// ErrDeviceNotAvailable means BlueZ does not know the device at all
+	ErrDeviceNotAvailable
+)
+
+// errorClassInfo describes a class for users
+var errorClassInfo = map[ErrorClass]struct {
+	name string
+	hint string
+}{
+	ErrFailed:               {"command failed", ""},
+	ErrNotReady:             {"adapter not ready", "adapter is off — power it on with bluetoothctl power on"},
+	ErrInProgress:           {"operation in progress", "the adapter is busy with another operation — try again in a moment"},
+	ErrAuthenticationFailed: {"authentication failed", "pairing was refused — remove the device and pair it again"},
+	ErrProfileUnavailable:   {"profile unavailable", "nothing accepted the device's audio or input profile — check PipeWire or PulseAudio is running"},
+	ErrPageTimeout:          {"page timeout", "the device did not answer — check it is on, in range and not connected elsewhere"},
+	ErrNotAvailable:         {"not available", "BlueZ cannot do this right now — check the device supports it"},
+	ErrDeviceNotAvailable:   {"device not available", "BlueZ does not know the device — scan for it or pair it first"},
+}
+
+// Error implements error with a short name for the class
+func (c ErrorClass) Error() string {
+	if info, ok := errorClassInfo[c]; ok {
+		return info.name
+	}
+	return "no error"
+}
+
+// Hint tells users what to do about a failure of the class, or "" when
+// there is no better advice than the output itself
+func (c ErrorClass) Hint() string {
+	return errorClassInfo[c].hint
+}
+
+// bluezErrorClasses maps the org.bluez.Error names BlueZ returns to classes.
+// org.bluez.Error.Failed carries its reason as text, which reasonMarkers
+// classify instead.
+var bluezErrorClasses = map[string]ErrorClass{
+	"NotReady":               ErrNotReady,
+	"InProgress":             ErrInProgress,
+	"AuthenticationFailed":   ErrAuthenticationFailed,
+	"AuthenticationRejected": ErrAuthenticationFailed,
+	"AuthenticationCanceled": ErrAuthenticationFailed,
+	"AuthenticationTimeout":  ErrAuthenticationFailed,
+	"NotAvailable":           ErrNotAvailable,
+}
+
+// reasonMarkers are lower-case fragments of failure reasons, checked before
+// the error name since they are more specific
+var reasonMarkers = []struct {
+	marker string
+	class  ErrorClass
+}{
+	{"page-timeout", ErrPageTimeout},
+	{"profile-unavailable", ErrProfileUnavailable},
+	{"br-connection-adapter-not-powered", ErrNotReady},
+	{"operation already in progress", ErrInProgress},
+	{"resource not ready", ErrNotReady},
+}
+
+var (
+	bluezErrorName = regexp.MustCompile(`org\.bluez\.Error\.(\w+)`)
+	// bluetoothctl prints "Device XX:XX:XX:XX:XX:XX not available" for a
+	// device it does not know
+	deviceNotAvailable = regexp.MustCompile(`(?i)device ([0-9a-f]{2}:){5}[0-9a-f]{2} not available`)
+)
+
+// Classify returns the class of failure bluetoothctl output reports, or 0
+// when it reports none
+func Classify(output string) ErrorClass {
+	lower := strings.ToLower(output)
+	for _, reason := range reasonMarkers {
+		if strings.Contains(lower, reason.marker) {
+			return reason.class
+		}
+	}
+	if match := bluezErrorName.FindStringSubmatch(output); match != nil {
+		if class, ok := bluezErrorClasses[match[1]]; ok {
+			return class
+		}
+		return ErrFailed
+	}
+	switch {
+	case deviceNotAvailable.MatchString(output):
+		return ErrDeviceNotAvailable
+	case strings.Contains(lower, "not available"):
+		return ErrNotAvailable
+	case strings.Contains(lower, "failed") || strings.Contains(lower, "error"):
+		return ErrFailed
+	}
+	return 0
+}
+
+// CommandError is a failed bluetoothctl command, classified by its output.
+// errors.Is matches both its class and the error running the command.
+type CommandError struct {
+	Class  ErrorClass
+	Output string
+	// Err is what running bluetoothctl returned, such as a non-zero exit
+	Err error
+}
+
+// NewCommandError classifies a failed command's output, falling back to
+// ErrFailed when the output does not say what went wrong
+func NewCommandError(output string, err error) *CommandError {
+	class := Classify(output)
+	if class == 0 {
+		class = ErrFailed
+	}
+	return &CommandError{Class: class, Output: output, Err: err}
+}
+
+// Error implements error
+func (e *CommandError) Error() string {
+	if e.Err != nil {
+		return e.Class.Error() + ": " + e.Err.Error()
+	}
+	return e.Class.Error()
+}
+
+// Unwrap exposes the class and the command error to errors.Is and errors.As
+func (e *CommandError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Class, e.Err}
+	}
+	return []error{e.Class}
+}
+
+// Hint returns the advice for the class of err, or "" when it has none
+func Hint(err error) string {
+	var class ErrorClass
+	if errors.As(err, &class) {
+		return class.Hint()
+	}
+	return ""
+}
+
+// ExitStatus describes how bluetoothctl ended for a failed command: its exit
+// code, the signal that stopped it, or 0 when it exited cleanly but printed
+// a failure
+func ExitStatus(err error) string {
+	var exit *exec.ExitError
+	var command *CommandError
+	switch {
+	case errors.As(err, &exit):
+		if exit.Exited() {
+			return strconv.Itoa(exit.ExitCode())
+		}
+		return exit.String()
+	case errors.As(err, &command) && command.Err == nil:
+		return "0"
+	case err != nil:
+		return err.Error()
+	}
+	return ""
+}
+
+// FailureDetails describes a failed operation for the error details modal
+func FailureDetails(action string, device BluetoothDevice, duration time.Duration, output string, err error) ui.ErrorDetails {
+	class := Classify(output)
+	errors.As(err, &class)
+	details := ui.ErrorDetails{
+		Operation:  action,
+		Device:     device.MacAddress,
+		Duration:   duration,
+		ExitStatus: ExitStatus(err),
+		Fix:        class.Hint(),
+		Output:     output,
+	}
+	if device.Name != "" {
+		details.Device = device.Name + " (" + device.MacAddress + ")"
+	}
+	if class != 0 {
+		details.Cause = class.Error()
+	}
+	return details
+}
+
+// Details describes a failed operation for the error details modal
+func (op Operation) Details() ui.ErrorDetails {
+	var duration time.Duration
+	if !op.Started.IsZero() && !op.Finished.IsZero() {
+		duration = op.Finished.Sub(op.Started)
+	}
+	return FailureDetails(op.Action, op.Device, duration, op.Output, op.Err)
+}
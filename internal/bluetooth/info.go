@@ -0,0 +1,122 @@
+package bluetooth
+
+import (
+	"btui/internal/config"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DeviceInfo holds the properties reported by `bluetoothctl info`
+type DeviceInfo struct {
+	MacAddress string
+	Name       string
+	Alias      string
+	Icon       string
+	Paired     bool
+	Trusted    bool
+	Connected  bool
+	// Manufacturer is the company identifier from the advertised
+	// manufacturer data, such as 0x004C, or empty when none is advertised
+	Manufacturer string
+	// Battery is the charge left in percent from the Battery1 interface when
+	// HasBattery is set; a device that does not report one leaves it unset
+	Battery    int
+	HasBattery bool
+	// RSSI is the signal strength in dBm while the device is in range, or 0
+	RSSI int
+}
+
+// DeviceInfoMsg is sent when a device info query completes
+type DeviceInfoMsg struct {
+	Info DeviceInfo
+	Err  error
+}
+
+// DeviceInfoCmd returns a command that fetches the properties of a device
+func DeviceInfoCmd(macAddress string) tea.Cmd {
+	return func() tea.Msg {
+		info, err := FetchDeviceInfo(macAddress)
+		return DeviceInfoMsg{Info: info, Err: err}
+	}
+}
+
+// FetchDeviceInfo runs `bluetoothctl info` for a device and parses the result
+func FetchDeviceInfo(macAddress string) (DeviceInfo, error) {
+	// Create a context with timeout to prevent hanging
+	ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.Fetch)
+	defer cancel()
+
+	cmd := Bluetoothctl(ctx, "info", macAddress)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return DeviceInfo{MacAddress: macAddress}, err
+	}
+
+	info := ParseDeviceInfo(string(output))
+	if info.MacAddress == "" {
+		info.MacAddress = macAddress
+	}
+	return info, nil
+}
+
+// ParseDeviceInfo parses the output of `bluetoothctl info <mac>`
+func ParseDeviceInfo(output string) DeviceInfo {
+	var info DeviceInfo
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		// Header line: "Device AA:BB:CC:DD:EE:FF (public)"
+		if strings.HasPrefix(line, "Device ") {
+			if parts := strings.Fields(line); len(parts) >= 2 {
+				info.MacAddress = parts[1]
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Name":
+			info.Name = value
+		case "Alias":
+			info.Alias = value
+		case "Icon":
+			info.Icon = value
+		case "Paired":
+			info.Paired = value == "yes"
+		case "Trusted":
+			info.Trusted = value == "yes"
+		case "Connected":
+			info.Connected = value == "yes"
+		case "RSSI":
+			// "0xffffffc4 (-60)" or a bare "-60"
+			if open := strings.Index(value, "("); open >= 0 {
+				value = strings.TrimSuffix(value[open+1:], ")")
+			}
+			if rssi, err := strconv.Atoi(value); err == nil {
+				info.RSSI = rssi
+			}
+		case "Battery Percentage":
+			if percent, ok := ParseBatteryPercentage(value); ok {
+				info.Battery, info.HasBattery = percent, true
+			}
+		case "ManufacturerData Key", "ManufacturerData.Key":
+			// "0x004c (76)": keep the first company advertised
+			var id uint16
+			if _, err := fmt.Sscanf(value, "0x%x", &id); err == nil && info.Manufacturer == "" {
+				info.Manufacturer = fmt.Sprintf("0x%04X", id)
+			}
+		}
+	}
+
+	return info
+}
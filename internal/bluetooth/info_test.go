@@ -0,0 +1,98 @@
+package bluetooth
+
+import "testing"
+
+func TestParseDeviceInfo(t *testing.T) {
+	output := `Device 4C:87:5D:28:86:DD (public)
+	Name: Bose NC 700 HP
+	Alias: Bose NC 700 Headphones
+	Class: 0x00240418
+	Icon: audio-headphones
+	Paired: yes
+	Trusted: yes
+	Blocked: no
+	Connected: no
+	UUID: Audio Sink                (0000110b-0000-1000-8000-00805f9b34fb)
+`
+
+	info := ParseDeviceInfo(output)
+
+	if info.MacAddress != "4C:87:5D:28:86:DD" {
+		t.Errorf("Expected MAC '4C:87:5D:28:86:DD', got %q", info.MacAddress)
+	}
+	if info.Name != "Bose NC 700 HP" {
+		t.Errorf("Expected name 'Bose NC 700 HP', got %q", info.Name)
+	}
+	if info.Alias != "Bose NC 700 Headphones" {
+		t.Errorf("Expected alias 'Bose NC 700 Headphones', got %q", info.Alias)
+	}
+	if info.Icon != "audio-headphones" {
+		t.Errorf("Expected icon 'audio-headphones', got %q", info.Icon)
+	}
+	if !info.Paired || !info.Trusted || info.Connected {
+		t.Errorf("Expected paired and trusted but not connected, got %+v", info)
+	}
+}
+
+func TestParseDeviceInfoNotAvailable(t *testing.T) {
+	info := ParseDeviceInfo("Device AA:BB:CC:DD:EE:FF not available\n")
+
+	if info.MacAddress != "AA:BB:CC:DD:EE:FF" {
+		t.Errorf("Expected MAC 'AA:BB:CC:DD:EE:FF', got %q", info.MacAddress)
+	}
+	if info.Name != "" || info.Icon != "" || info.Paired {
+		t.Errorf("Expected no properties, got %+v", info)
+	}
+}
+
+func TestParseDeviceInfoManufacturer(t *testing.T) {
+	output := `Device 5A:11:22:33:44:55 (random)
+	Name: Beacon
+	ManufacturerData.Key: 0x004c (76)
+	ManufacturerData.Value:
+  10 05 1c 18 9a 6f 2d                             .....o-
+	ManufacturerData.Key: 0x0006 (6)
+`
+
+	info := ParseDeviceInfo(output)
+	if info.Manufacturer != "0x004C" {
+		t.Errorf("Expected the first manufacturer 0x004C, got %q", info.Manufacturer)
+	}
+	if got := ManufacturerName(info.Manufacturer); got != "Apple (0x004C)" {
+		t.Errorf("Expected a named manufacturer, got %q", got)
+	}
+	if got := ManufacturerName("0xFFFE"); got != "0xFFFE" {
+		t.Errorf("Expected unknown identifiers unchanged, got %q", got)
+	}
+}
+
+func TestParseDeviceInfoBattery(t *testing.T) {
+	output := `Device 4C:87:5D:28:86:DD (public)
+	Name: Bose NC 700 HP
+	Connected: yes
+	Battery Percentage: 0x5a (90)
+`
+
+	if info := ParseDeviceInfo(output); info.Battery != 90 || !info.HasBattery {
+		t.Errorf("Expected battery 90%%, got %d", info.Battery)
+	}
+	if info := ParseDeviceInfo("Device 4C:87:5D:28:86:DD (public)\n\tConnected: no\n"); info.HasBattery {
+		t.Errorf("Expected no battery without Battery1, got %d", info.Battery)
+	}
+	// An empty battery is a charge like any other
+	if info := ParseDeviceInfo("Device 4C:87:5D:28:86:DD (public)\n\tBattery Percentage: 0x00 (0)\n"); info.Battery != 0 || !info.HasBattery {
+		t.Errorf("Expected an empty battery, got %+v", info)
+	}
+}
+
+func TestParseDeviceInfoRSSI(t *testing.T) {
+	if info := ParseDeviceInfo("Device 4C:87:5D:28:86:DD (public)\n\tRSSI: 0xffffffc4 (-60)\n"); info.RSSI != -60 {
+		t.Errorf("Expected -60 dBm, got %d", info.RSSI)
+	}
+	if info := ParseDeviceInfo("Device 4C:87:5D:28:86:DD (public)\n\tRSSI: -71\n"); info.RSSI != -71 {
+		t.Errorf("Expected -71 dBm, got %d", info.RSSI)
+	}
+	if info := ParseDeviceInfo("Device 4C:87:5D:28:86:DD (public)\n\tConnected: no\n"); info.RSSI != 0 {
+		t.Errorf("Expected no signal out of range, got %d", info.RSSI)
+	}
+}
@@ -0,0 +1,34 @@
+package bluetooth
+
+// manufacturers names the companies most often seen in advertisements, keyed
+// by Bluetooth SIG company identifier
+var manufacturers = map[string]string{
+	"0x0006": "Microsoft",
+	"0x000F": "Broadcom",
+	"0x004C": "Apple",
+	"0x0059": "Nordic Semiconductor",
+	"0x0075": "Samsung",
+	"0x0087": "Garmin",
+	"0x009E": "Bose",
+	"0x00E0": "Google",
+	"0x010F": "Huawei",
+	"0x012D": "Sony",
+	"0x0131": "Cypress Semiconductor",
+	"0x0157": "Huami",
+	"0x0171": "Amazon",
+	"0x01DA": "Logitech",
+	"0x02E5": "Espressif",
+	"0x038F": "Xiaomi",
+	"0x0499": "Ruuvi Innovations",
+	"0x05A7": "Sonos",
+	"0x067C": "Tile",
+}
+
+// ManufacturerName describes a company identifier such as 0x004C, naming the
+// company when it is well known: "Apple (0x004C)"
+func ManufacturerName(id string) string {
+	if name, ok := manufacturers[id]; ok {
+		return name + " (" + id + ")"
+	}
+	return id
+}
@@ -0,0 +1,358 @@
+package bluetooth
+
+import (
+	"btui/internal/config"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Operations the manager can run on a device
+const (
+	OpConnect    = "connect"
+	OpDisconnect = "disconnect"
+	OpTrust      = "trust"
+	OpRemove     = "remove"
+)
+
+// OpState is how far an operation has got
+type OpState int
+
+const (
+	OpQueued OpState = iota
+	OpRunning
+	OpSucceeded
+	OpFailed
+	OpCancelled
+)
+
+// String returns a string representation of the operation state
+func (s OpState) String() string {
+	switch s {
+	case OpQueued:
+		return "Queued"
+	case OpRunning:
+		return "Running"
+	case OpSucceeded:
+		return "Succeeded"
+	case OpFailed:
+		return "Failed"
+	case OpCancelled:
+		return "Cancelled"
+	default:
+		return "Unknown"
+	}
+}
+
+// Finished reports whether the operation has stopped for good
+func (s OpState) Finished() bool {
+	return s >= OpSucceeded
+}
+
+// Operation is one connect, disconnect, trust or remove request on a device
+type Operation struct {
+	ID     int
+	Action string
+	Device BluetoothDevice
+	State  OpState
+	Output string
+	// Err says why a failed operation failed, usually a *CommandError
+	Err error
+	// Attempt counts the tries of a running operation, out of Attempts
+	// allowed by the retry policy
+	Attempt  int
+	Attempts int
+	Queued   time.Time
+	Started  time.Time
+	Finished time.Time
+}
+
+// OperationMsg is sent whenever an operation changes state
+type OperationMsg struct {
+	Op Operation
+}
+
+// Runner carries out an operation until ctx is cancelled, reporting whether
+// it worked, the bluetoothctl output and, on failure, the error
+type Runner func(ctx context.Context, action string, device BluetoothDevice) (bool, string, error)
+
+// RunOperation runs an operation with bluetoothctl through the command of the
+// same name, such as ConnectCmdContext, and its success detection
+func RunOperation(ctx context.Context, action string, device BluetoothDevice) (bool, string, error) {
+	switch action {
+	case OpConnect:
+		result := ConnectCmdContext(ctx, device)().(ConnectMsg)
+		return result.Success, result.Output, result.Err
+	case OpDisconnect:
+		result := DisconnectCmdContext(ctx, device)().(DisconnectMsg)
+		return result.Success, result.Output, result.Err
+	case OpTrust:
+		result := TrustCmd(device)().(TrustMsg)
+		return result.Success, result.Output, result.Err
+	case OpRemove:
+		result := RemoveCmd(device)().(RemoveMsg)
+		return result.Success, result.Output, result.Err
+	}
+	return false, "unknown operation " + action, errors.New("unknown operation " + action)
+}
+
+// historySize is how many finished operations the manager remembers
+const historySize = 20
+
+// Manager queues operations on devices and runs them in order, one at a time
+// per device and at most a fixed number at once overall. Every state change
+// is published as an OperationMsg.
+type Manager struct {
+	mu        sync.Mutex
+	limit     int
+	run       Runner
+	nextID    int
+	ops       []*Operation
+	pending   []Operation
+	notify    chan struct{}
+	listening bool
+	waiters   map[int]chan Operation
+	cancels   map[int]context.CancelFunc
+}
+
+// NewManager returns a manager running at most limit operations at once
+func NewManager(limit int, run Runner) *Manager {
+	return &Manager{
+		limit:   max(1, limit),
+		run:     run,
+		notify:  make(chan struct{}, 1),
+		waiters: make(map[int]chan Operation),
+		cancels: make(map[int]context.CancelFunc),
+	}
+}
+
+// Submit queues an operation and starts it right away when its device is
+// idle and a slot is free. It returns the operation as it stands.
+func (m *Manager) Submit(action string, device BluetoothDevice) Operation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return *m.submit(action, device)
+}
+
+// Do queues an operation and waits for it to finish, so steps run elsewhere,
+// such as those of a scene, take their turn with everything else. It has the
+// shape of a scene executor.
+func (m *Manager) Do(action string, device BluetoothDevice) (bool, string) {
+	done := make(chan Operation, 1)
+	m.mu.Lock()
+	op := m.submit(action, device)
+	if op.State.Finished() {
+		done <- *op
+	} else {
+		m.waiters[op.ID] = done
+	}
+	m.mu.Unlock()
+
+	finished := <-done
+	if finished.State == OpCancelled {
+		return false, "cancelled"
+	}
+	return finished.State == OpSucceeded, finished.Output
+}
+
+// submit adds an operation to the queue and dispatches it. The caller holds
+// the lock.
+func (m *Manager) submit(action string, device BluetoothDevice) *Operation {
+	m.nextID++
+	op := &Operation{ID: m.nextID, Action: action, Device: device, State: OpQueued, Queued: time.Now()}
+	m.ops = append(m.ops, op)
+	m.publish(*op)
+	m.dispatch()
+	return op
+}
+
+// Cancellable reports whether an operation can still be called off: any
+// queued one, and connects and disconnects while they run. Trusting and
+// removing are over too quickly to stop halfway.
+func (op Operation) Cancellable() bool {
+	switch op.State {
+	case OpQueued:
+		return true
+	case OpRunning:
+		return op.Action == OpConnect || op.Action == OpDisconnect
+	default:
+		return false
+	}
+}
+
+// Cancel calls off an operation and reports whether it could be. A queued
+// operation is dropped at once; a running one is stopped and reported as
+// cancelled once bluetoothctl has been dealt with.
+func (m *Manager) Cancel(id int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, op := range m.ops {
+		if op.ID != id || !op.Cancellable() {
+			continue
+		}
+		if op.State == OpRunning {
+			m.cancels[op.ID]()
+			return true
+		}
+		op.State = OpCancelled
+		op.Finished = time.Now()
+		m.publish(*op)
+		m.trim()
+		return true
+	}
+	return false
+}
+
+// Operations returns the queued and running operations in queue order,
+// followed by recently finished ones, newest first
+func (m *Manager) Operations() []Operation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var active, finished []Operation
+	for _, op := range m.ops {
+		if op.State.Finished() {
+			finished = append(finished, *op)
+		} else {
+			active = append(active, *op)
+		}
+	}
+	slices.Reverse(finished)
+	return append(active, finished...)
+}
+
+// dispatch starts every queued operation whose device is idle while slots
+// are free. The caller holds the lock.
+func (m *Manager) dispatch() {
+	running := 0
+	busy := make(map[string]bool)
+	for _, op := range m.ops {
+		if op.State == OpRunning {
+			running++
+			busy[op.Device.MacAddress] = true
+		}
+	}
+
+	for _, op := range m.ops {
+		if running >= m.limit {
+			return
+		}
+		if op.State.Finished() {
+			continue
+		}
+		if op.State != OpQueued || busy[op.Device.MacAddress] {
+			// Later operations on a device wait for earlier ones
+			busy[op.Device.MacAddress] = true
+			continue
+		}
+		op.State = OpRunning
+		op.Started = time.Now()
+		op.Attempt, op.Attempts = 1, 1
+		if op.Action == OpConnect {
+			op.Attempts = config.Get().Retry.Attempts
+		}
+		busy[op.Device.MacAddress] = true
+		running++
+		m.publish(*op)
+		ctx, cancel := context.WithCancel(context.Background())
+		m.cancels[op.ID] = cancel
+		go m.execute(ctx, op)
+	}
+}
+
+// execute runs an operation, retrying a failed connect as configured, and
+// starts whatever it was holding up
+func (m *Manager) execute(ctx context.Context, op *Operation) {
+	ok, output, err := RunWithRetry(ctx, config.Get().Retry, m.run, op.Action, op.Device, func(attempt int) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		op.Attempt = attempt
+		m.publish(*op)
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case cancelled(ctx):
+		op.State = OpCancelled
+	case ok:
+		op.State = OpSucceeded
+	default:
+		op.State = OpFailed
+	}
+	op.Output, op.Err = output, err
+	op.Finished = time.Now()
+	// Release the context now the operation can no longer be cancelled
+	m.cancels[op.ID]()
+	delete(m.cancels, op.ID)
+	m.publish(*op)
+	m.dispatch()
+	m.trim()
+}
+
+// trim forgets the oldest finished operations beyond the history size. The
+// caller holds the lock.
+func (m *Manager) trim() {
+	finished := 0
+	for _, op := range m.ops {
+		if op.State.Finished() {
+			finished++
+		}
+	}
+	m.ops = slices.DeleteFunc(m.ops, func(op *Operation) bool {
+		if finished > historySize && op.State.Finished() {
+			finished--
+			return true
+		}
+		return false
+	})
+}
+
+// publish queues a state change for Next and hands finished operations to
+// whoever is waiting on them in Do. The caller holds the lock.
+func (m *Manager) publish(op Operation) {
+	if done, ok := m.waiters[op.ID]; ok && op.State.Finished() {
+		done <- op
+		delete(m.waiters, op.ID)
+	}
+	m.pending = append(m.pending, op)
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Next returns a command waiting for the next state change
+func (m *Manager) Next() tea.Cmd {
+	return func() tea.Msg {
+		for {
+			m.mu.Lock()
+			if len(m.pending) > 0 {
+				op := m.pending[0]
+				m.pending = m.pending[1:]
+				m.listening = false
+				m.mu.Unlock()
+				return OperationMsg{Op: op}
+			}
+			m.mu.Unlock()
+			<-m.notify
+		}
+	}
+}
+
+// Listen returns Next unless a command returned by Listen is still waiting,
+// so a program has one listener at a time
+func (m *Manager) Listen() tea.Cmd {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.listening {
+		return nil
+	}
+	m.listening = true
+	return m.Next()
+}
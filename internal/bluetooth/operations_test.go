@@ -0,0 +1,192 @@
+package bluetooth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// blockingRunner runs operations until the test releases them, recording
+// the order they started in
+type blockingRunner struct {
+	started chan string
+	release chan bool
+}
+
+func newBlockingRunner() *blockingRunner {
+	return &blockingRunner{started: make(chan string, 10), release: make(chan bool)}
+}
+
+func (r *blockingRunner) run(ctx context.Context, action string, device BluetoothDevice) (bool, string, error) {
+	r.started <- action + " " + device.Name
+	select {
+	case ok := <-r.release:
+		if !ok {
+			return false, "Failed to " + action, NewCommandError("Failed to "+action, nil)
+		}
+		return true, "", nil
+	case <-ctx.Done():
+		return false, "", nil
+	}
+}
+
+// nextState waits for the next change of an operation to the given state
+func nextState(t *testing.T, m *Manager, state OpState) Operation {
+	t.Helper()
+	for {
+		msg := make(chan OperationMsg, 1)
+		go func() { msg <- m.Next()().(OperationMsg) }()
+		select {
+		case got := <-msg:
+			if got.Op.State == state {
+				return got.Op
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for a %s operation", state)
+		}
+	}
+}
+
+func TestManagerSerialisesPerDevice(t *testing.T) {
+	runner := newBlockingRunner()
+	m := NewManager(4, runner.run)
+	headphones := BluetoothDevice{MacAddress: "4C:87:5D:28:86:DD", Name: "Headphones"}
+	keyboard := BluetoothDevice{MacAddress: "DC:2C:26:09:D0:0C", Name: "Keyboard"}
+
+	if op := m.Submit(OpDisconnect, headphones); op.State != OpRunning {
+		t.Errorf("Expected an idle device to start at once, got %s", op.State)
+	}
+	if op := m.Submit(OpConnect, headphones); op.State != OpQueued {
+		t.Errorf("Expected a second operation on the device to queue, got %s", op.State)
+	}
+	if op := m.Submit(OpConnect, keyboard); op.State != OpRunning {
+		t.Errorf("Expected another device to run alongside, got %s", op.State)
+	}
+
+	first, second := <-runner.started, <-runner.started
+	if first+second != "disconnect Headphonesconnect Keyboard" && first+second != "connect Keyboarddisconnect Headphones" {
+		t.Errorf("Unexpected operations started: %q, %q", first, second)
+	}
+
+	runner.release <- true
+	runner.release <- true
+	if got := <-runner.started; got != "connect Headphones" {
+		t.Errorf("Expected the queued connect to follow the disconnect, got %q", got)
+	}
+	runner.release <- false
+	op := nextState(t, m, OpFailed)
+	if op.Action != OpConnect || op.Output != "Failed to connect" {
+		t.Errorf("Expected the connect to fail, got %+v", op)
+	}
+}
+
+func TestManagerLimitsConcurrency(t *testing.T) {
+	runner := newBlockingRunner()
+	m := NewManager(1, runner.run)
+	m.Submit(OpConnect, BluetoothDevice{MacAddress: "AA:AA:AA:AA:AA:AA", Name: "A"})
+	queued := m.Submit(OpConnect, BluetoothDevice{MacAddress: "BB:BB:BB:BB:BB:BB", Name: "B"})
+	third := m.Submit(OpConnect, BluetoothDevice{MacAddress: "CC:CC:CC:CC:CC:CC", Name: "C"})
+	if queued.State != OpQueued || third.State != OpQueued {
+		t.Fatal("Expected operations beyond the limit to queue")
+	}
+
+	// Cancelling a queued operation takes it out of the queue
+	if !m.Cancel(third.ID) || m.Cancel(third.ID) {
+		t.Error("Expected a queued operation to be cancelled once")
+	}
+
+	<-runner.started
+	runner.release <- true
+	if got := <-runner.started; got != "connect B" {
+		t.Errorf("Expected B to start once A finished, got %q", got)
+	}
+
+	ops := m.Operations()
+	if len(ops) != 3 || ops[0].State != OpRunning || ops[1].State != OpCancelled || ops[2].State != OpSucceeded {
+		t.Errorf("Expected running first, then finished newest first, got %+v", ops)
+	}
+	runner.release <- true
+}
+
+func TestManagerHistory(t *testing.T) {
+	m := NewManager(1, func(context.Context, string, BluetoothDevice) (bool, string, error) { return true, "", nil })
+	device := BluetoothDevice{MacAddress: "AA:AA:AA:AA:AA:AA"}
+	for range historySize + 5 {
+		m.Submit(OpTrust, device)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(m.Operations()) > historySize || m.Operations()[0].State != OpSucceeded {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d finished operations to be kept, got %d", historySize, len(m.Operations()))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestListenOnce(t *testing.T) {
+	m := NewManager(1, func(context.Context, string, BluetoothDevice) (bool, string, error) { return true, "", nil })
+	if m.Listen() == nil || m.Listen() != nil {
+		t.Error("Expected a single listener at a time")
+	}
+}
+
+func TestManagerDo(t *testing.T) {
+	runner := newBlockingRunner()
+	m := NewManager(2, runner.run)
+	headphones := BluetoothDevice{MacAddress: "4C:87:5D:28:86:DD", Name: "Headphones"}
+
+	m.Submit(OpDisconnect, headphones)
+	result := make(chan bool, 1)
+	go func() {
+		ok, _ := m.Do(OpConnect, headphones)
+		result <- ok
+	}()
+
+	// The step waits for the operation already running on the device
+	if got := <-runner.started; got != "disconnect Headphones" {
+		t.Fatalf("Expected the disconnect first, got %q", got)
+	}
+	runner.release <- true
+	if got := <-runner.started; got != "connect Headphones" {
+		t.Fatalf("Expected the step to run next, got %q", got)
+	}
+	runner.release <- false
+	select {
+	case ok := <-result:
+		if ok {
+			t.Error("Expected the failed step to report failure")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for Do")
+	}
+}
+
+func TestManagerCancel(t *testing.T) {
+	runner := newBlockingRunner()
+	m := NewManager(2, runner.run)
+	headphones := BluetoothDevice{MacAddress: "4C:87:5D:28:86:DD", Name: "Headphones"}
+	keyboard := BluetoothDevice{MacAddress: "DC:2C:26:09:D0:0C", Name: "Keyboard"}
+
+	connect := m.Submit(OpConnect, headphones)
+	trust := m.Submit(OpTrust, keyboard)
+	<-runner.started
+	<-runner.started
+
+	if m.Cancel(trust.ID) {
+		t.Error("Expected a running trust to be left to finish")
+	}
+	if !m.Cancel(connect.ID) {
+		t.Fatal("Expected a running connect to be cancelled")
+	}
+	if op := nextState(t, m, OpCancelled); op.ID != connect.ID {
+		t.Errorf("Expected the connect cancelled, got %+v", op)
+	}
+	if m.Cancel(connect.ID) {
+		t.Error("Expected a finished operation not to be cancelled again")
+	}
+
+	runner.release <- true
+	if op := nextState(t, m, OpSucceeded); op.ID != trust.ID {
+		t.Errorf("Expected the trust to finish, got %+v", op)
+	}
+}
@@ -1,7 +1,11 @@
 package bluetooth
 
 import (
+	"btui/internal/config"
+	"btui/internal/state"
 	"btui/internal/ui"
+	"slices"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -16,6 +20,7 @@ type PickerModel struct {
 	Err      error
 	Width    int
 	Height   int
+	Clicks   ui.ClickTracker
 }
 
 // NewPickerModel creates a new device picker model
@@ -27,17 +32,25 @@ func NewPickerModel() PickerModel {
 
 // DeviceToListItem converts a BluetoothDevice to a generic list item
 func DeviceToListItem(d BluetoothDevice) list.Item {
-	title := d.Name
-	if title == "" {
-		title = "Unknown Device"
+	name := state.Get().DisplayName(d.MacAddress, d.Name)
+	if name == "" {
+		name = "Unknown Device"
 	}
+	title := name
 
-	// Add connection status indicator to title
+	// Add connection status and favorite indicators to title
 	if d.Connected {
-		title = "🔗 " + title
+		title = ui.ConnectedMarker() + title
+	}
+	if state.Get().IsFavorite(d.MacAddress) {
+		title = ui.FavoriteMarker() + title
 	}
 
 	description := d.MacAddress
+	if name != d.Name && d.Name != "" {
+		// A nickname replaces the name, so keep the Bluetooth name visible
+		description = d.Name + ui.Separator() + description
+	}
 	if d.Connected {
 		description += " (Connected)"
 	}
@@ -49,10 +62,24 @@ func DeviceToListItem(d BluetoothDevice) list.Item {
 	}
 }
 
-// DevicesToListItems converts a slice of BluetoothDevice to list items
+// DevicesToListItems converts a slice of BluetoothDevice to list items,
+// offering favorites first
 func DevicesToListItems(devices []BluetoothDevice) []list.Item {
-	items := make([]list.Item, len(devices))
-	for i, device := range devices {
+	store := state.Get()
+	sorted := slices.Clone(devices)
+	slices.SortStableFunc(sorted, func(a, b BluetoothDevice) int {
+		favA, favB := store.IsFavorite(a.MacAddress), store.IsFavorite(b.MacAddress)
+		switch {
+		case favA && !favB:
+			return -1
+		case favB && !favA:
+			return 1
+		}
+		return 0
+	})
+
+	items := make([]list.Item, len(sorted))
+	for i, device := range sorted {
 		items[i] = DeviceToListItem(device)
 	}
 	return items
@@ -60,7 +87,7 @@ func DevicesToListItems(devices []BluetoothDevice) []list.Item {
 
 // Init implements tea.Model
 func (m PickerModel) Init() tea.Cmd {
-	return FetchDevicesCmd()
+	return tea.Batch(FetchDevicesCmd(), ui.MouseCmd(config.Get().Mouse))
 }
 
 // Update implements tea.Model
@@ -81,16 +108,28 @@ func (m PickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 
 		case "enter":
-			if !m.Loading && len(m.List.Items()) > 0 {
-				selectedItem := m.List.SelectedItem()
-				if genericItem, ok := selectedItem.(ui.GenericItem); ok {
-					if device, ok := genericItem.Value.(BluetoothDevice); ok {
-						m.Choice = &device
-					}
+			return m.choose()
+		}
+
+	case tea.MouseMsg:
+		if m.Loading || m.List.Items() == nil || m.List.SettingFilter() {
+			return m, nil
+		}
+		switch {
+		case msg.Button == tea.MouseButtonWheelUp:
+			m.List.CursorUp()
+		case msg.Button == tea.MouseButtonWheelDown:
+			m.List.CursorDown()
+		case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+			// The picker list is drawn without padding, so screen rows map directly
+			if index, ok := ui.ListItemAt(m.List, msg.Y); ok {
+				m.List.Select(index)
+				if m.Clicks.Click(index, time.Now()) {
+					return m.choose()
 				}
 			}
-			return m, tea.Quit
 		}
+		return m, nil
 
 	case DevicesMsg:
 		m.Loading = false
@@ -107,10 +146,10 @@ func (m PickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		width := m.Width
 		height := m.Height
 		if width == 0 {
-			width = 80
+			width = config.Get().Window.Width
 		}
 		if height == 0 {
-			height = 14
+			height = config.Get().Window.Height
 		}
 
 		m.List = ui.NewList(items, "Select Bluetooth Device", width, height)
@@ -127,6 +166,19 @@ func (m PickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// choose picks the selected device and ends the picker
+func (m PickerModel) choose() (tea.Model, tea.Cmd) {
+	if !m.Loading && len(m.List.Items()) > 0 {
+		selectedItem := m.List.SelectedItem()
+		if genericItem, ok := selectedItem.(ui.GenericItem); ok {
+			if device, ok := genericItem.Value.(BluetoothDevice); ok {
+				m.Choice = &device
+			}
+		}
+	}
+	return m, tea.Quit
+}
+
 // View implements tea.Model
 func (m PickerModel) View() string {
 	if m.Quitting {
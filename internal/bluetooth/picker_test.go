@@ -0,0 +1,69 @@
+package bluetooth
+
+import (
+	"btui/internal/state"
+	"btui/internal/ui"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func pickerFixture() PickerModel {
+	updated, _ := NewPickerModel().Update(DevicesMsg{
+		Devices: []string{
+			"Device 4C:87:5D:28:86:DD Bose NC 700 Headphones",
+			"Device DC:2C:26:09:D0:0C Keychron K4",
+		},
+	})
+	return updated.(PickerModel)
+}
+
+func TestPickerMouseClick(t *testing.T) {
+	m := pickerFixture()
+	press := tea.MouseMsg{Y: 5, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+
+	// Title bar (2 rows), first item (2 rows), gap, then the second item
+	updated, cmd := m.Update(press)
+	m = updated.(PickerModel)
+	if m.List.Index() != 1 {
+		t.Errorf("Expected click to select item 1, got %d", m.List.Index())
+	}
+	if cmd != nil || m.Choice != nil {
+		t.Error("Expected a single click not to choose")
+	}
+
+	updated, cmd = m.Update(press)
+	m = updated.(PickerModel)
+	if m.Choice == nil || m.Choice.Name != "Keychron K4" {
+		t.Fatal("Expected a double click to choose Keychron K4")
+	}
+	if cmd == nil {
+		t.Error("Expected the picker to quit after choosing")
+	}
+}
+
+func TestPickerMouseWheel(t *testing.T) {
+	m := pickerFixture()
+
+	updated, _ := m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+	m = updated.(PickerModel)
+	if m.List.Index() != 1 {
+		t.Errorf("Expected wheel down to select item 1, got %d", m.List.Index())
+	}
+}
+
+func TestDevicesToListItemsFavoritesFirst(t *testing.T) {
+	store := state.New("")
+	store.SetFavorite("DC:2C:26:09:D0:0C", true)
+	state.Set(store)
+	t.Cleanup(func() { state.Set(state.New("")) })
+
+	items := DevicesToListItems([]BluetoothDevice{
+		{MacAddress: "4C:87:5D:28:86:DD", Name: "Bose NC 700 Headphones"},
+		{MacAddress: "DC:2C:26:09:D0:0C", Name: "Keychron K4"},
+	})
+	first := items[0].(ui.GenericItem)
+	if first.Title != "★ Keychron K4" {
+		t.Errorf("Expected the starred favorite first, got %q", first.Title)
+	}
+}
@@ -0,0 +1,62 @@
+package bluetooth
+
+import (
+	"btui/internal/config"
+	"context"
+	"slices"
+	"time"
+)
+
+// retryClasses names the failure classes a retry policy may list
+var retryClasses = map[ErrorClass]string{
+	ErrPageTimeout:        config.RetryPageTimeout,
+	ErrInProgress:         config.RetryInProgress,
+	ErrProfileUnavailable: config.RetryProfileUnavailable,
+}
+
+// RetryClass returns the retryable failure class of a failed connect's
+// output, as named in the retry policy, or "" when the failure is not one
+// worth trying again
+func RetryClass(output string) string {
+	return retryClasses[Classify(output)]
+}
+
+// ShouldRetry reports whether a connect that failed on the given attempt,
+// counting from 1, should be tried again under the policy
+func ShouldRetry(policy config.Retry, attempt int, output string) bool {
+	if attempt >= policy.Attempts {
+		return false
+	}
+	class := RetryClass(output)
+	return class != "" && slices.Contains(policy.Errors, class)
+}
+
+// Backoff returns how long to wait before the given attempt: nothing before
+// the first, the policy's backoff before the second, doubling after that
+func Backoff(policy config.Retry, attempt int) time.Duration {
+	if attempt < 2 {
+		return 0
+	}
+	return policy.Backoff << (attempt - 2)
+}
+
+// RunWithRetry runs an operation, trying a failed connect again while the
+// policy allows. retrying, when set, is called before each further attempt.
+// Waiting between attempts stops as soon as ctx is cancelled.
+func RunWithRetry(ctx context.Context, policy config.Retry, run Runner, action string, device BluetoothDevice, retrying func(attempt int)) (bool, string, error) {
+	for attempt := 1; ; attempt++ {
+		ok, output, err := run(ctx, action, device)
+		if ok || action != OpConnect || !ShouldRetry(policy, attempt, output) {
+			return ok, output, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, output, err
+		case <-time.After(Backoff(policy, attempt+1)):
+		}
+		if retrying != nil {
+			retrying(attempt + 1)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package bluetooth
+
+import (
+	"btui/internal/config"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryClass(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected string
+	}{
+		{readOutput(t, "connect_page_timeout"), config.RetryPageTimeout},
+		{readOutput(t, "connect_in_progress"), config.RetryInProgress},
+		{readOutput(t, "connect_operation_in_progress"), config.RetryInProgress},
+		{readOutput(t, "connect_profile_unavailable"), config.RetryProfileUnavailable},
+		{readOutput(t, "connect_unknown_device"), ""},
+		{readOutput(t, "connect_not_ready"), ""},
+		{readOutput(t, "connect_authentication_failed"), ""},
+		{"connect timed out after 30s", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := RetryClass(test.output); got != test.expected {
+			t.Errorf("RetryClass(%q) = %q, expected %q", test.output, got, test.expected)
+		}
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	policy := config.Default().Retry
+	tests := []struct {
+		name     string
+		policy   config.Retry
+		attempt  int
+		output   string
+		expected bool
+	}{
+		{"page timeout", policy, 1, readOutput(t, "connect_page_timeout"), true},
+		{"in progress", policy, 2, readOutput(t, "connect_in_progress"), true},
+		{"last attempt", policy, 3, readOutput(t, "connect_page_timeout"), false},
+		{"profile unavailable off by default", policy, 1, readOutput(t, "connect_profile_unavailable"), false},
+		{"profile unavailable enabled", config.Retry{Attempts: 3, Errors: []string{config.RetryProfileUnavailable}}, 1, readOutput(t, "connect_profile_unavailable"), true},
+		{"device not available", policy, 1, readOutput(t, "connect_unknown_device"), false},
+		{"adapter not ready", policy, 1, readOutput(t, "connect_not_ready"), false},
+		{"authentication failed", policy, 1, readOutput(t, "connect_authentication_failed"), false},
+		{"retries disabled", config.Retry{Attempts: 1, Errors: policy.Errors}, 1, readOutput(t, "connect_page_timeout"), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ShouldRetry(test.policy, test.attempt, test.output); got != test.expected {
+				t.Errorf("ShouldRetry(attempt %d) = %v, expected %v", test.attempt, got, test.expected)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := config.Retry{Attempts: 4, Backoff: time.Second}
+	expected := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second}
+	for attempt, wait := range expected {
+		if got := Backoff(policy, attempt); got != wait {
+			t.Errorf("Backoff before attempt %d = %v, expected %v", attempt, got, wait)
+		}
+	}
+}
+
+// flakyRunner fails with the recorded outputs in turn, then succeeds
+func flakyRunner(outputs ...string) (Runner, *int) {
+	calls := 0
+	return func(_ context.Context, _ string, _ BluetoothDevice) (bool, string, error) {
+		calls++
+		if calls <= len(outputs) {
+			return false, outputs[calls-1], NewCommandError(outputs[calls-1], nil)
+		}
+		return true, "Connection successful", nil
+	}, &calls
+}
+
+func TestRunWithRetry(t *testing.T) {
+	policy := config.Retry{Attempts: 3, Errors: []string{config.RetryPageTimeout, config.RetryInProgress}}
+	device := BluetoothDevice{MacAddress: "4C:87:5D:28:86:DD", Name: "Headphones"}
+	pageTimeout := readOutput(t, "connect_page_timeout")
+
+	run, calls := flakyRunner(pageTimeout, readOutput(t, "connect_in_progress"))
+	var retries []int
+	ok, _, _ := RunWithRetry(context.Background(), policy, run, OpConnect, device, func(attempt int) {
+		retries = append(retries, attempt)
+	})
+	if !ok || *calls != 3 || len(retries) != 2 || retries[1] != 3 {
+		t.Errorf("Expected success on the third attempt, got %v after %d calls, retries %v", ok, *calls, retries)
+	}
+
+	run, calls = flakyRunner(pageTimeout, pageTimeout, pageTimeout)
+	if ok, output, err := RunWithRetry(context.Background(), policy, run, OpConnect, device, nil); ok || *calls != 3 || output != pageTimeout || !errors.Is(err, ErrPageTimeout) {
+		t.Errorf("Expected to give up after 3 attempts, got %v after %d calls", ok, *calls)
+	}
+
+	run, calls = flakyRunner(readOutput(t, "connect_authentication_failed"))
+	if ok, _, _ := RunWithRetry(context.Background(), policy, run, OpConnect, device, nil); ok || *calls != 1 {
+		t.Errorf("Expected no retry of an authentication failure, got %d calls", *calls)
+	}
+
+	run, calls = flakyRunner(pageTimeout)
+	if ok, _, _ := RunWithRetry(context.Background(), policy, run, OpDisconnect, device, nil); ok || *calls != 1 {
+		t.Errorf("Expected only connects retried, got %d calls", *calls)
+	}
+
+	// Cancelling stops the wait before the next attempt
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	run, calls = flakyRunner(pageTimeout)
+	policy.Backoff = time.Hour
+	if ok, _, _ := RunWithRetry(ctx, policy, run, OpConnect, device, nil); ok || *calls != 1 {
+		t.Errorf("Expected a cancelled wait to give up, got %d calls", *calls)
+	}
+}
+
+func TestManagerRetries(t *testing.T) {
+	cfg := config.Default()
+	cfg.Retry.Backoff = 0
+	config.Set(cfg)
+	t.Cleanup(func() { config.Set(config.Default()) })
+
+	run, _ := flakyRunner(readOutput(t, "connect_page_timeout"))
+	m := NewManager(1, run)
+	if op := m.Submit(OpConnect, BluetoothDevice{MacAddress: "4C:87:5D:28:86:DD"}); op.Attempt != 1 || op.Attempts != 3 {
+		t.Errorf("Expected attempt 1/3, got %d/%d", op.Attempt, op.Attempts)
+	}
+	// The start of the first attempt may be published too
+	op := nextState(t, m, OpRunning)
+	if op.Attempt == 1 {
+		op = nextState(t, m, OpRunning)
+	}
+	if op.Attempt != 2 || op.Attempts != 3 {
+		t.Errorf("Expected the retry published as attempt 2/3, got %d/%d", op.Attempt, op.Attempts)
+	}
+	if op := nextState(t, m, OpSucceeded); op.Attempt != 2 {
+		t.Errorf("Expected success on attempt 2, got %d", op.Attempt)
+	}
+}
@@ -1,11 +1,11 @@
 package bluetooth
 
 import (
+	"btui/internal/config"
+	"btui/internal/state"
 	"context"
 	"fmt"
-	"os/exec"
 	"strings"
-	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -14,28 +14,21 @@ import (
 func FetchDevicesCmd() tea.Cmd {
 	return func() tea.Msg {
 		// Create a context with timeout to prevent hanging
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), config.Get().Timeouts.Fetch)
 		defer cancel()
 
 		// Fetch all devices
-		allDevicesCmd := exec.CommandContext(ctx, "bluetoothctl", "devices")
+		allDevicesCmd := Bluetoothctl(ctx, "devices")
 		allDevicesOutput, err := allDevicesCmd.CombinedOutput()
 		if err != nil {
 			// Include the command output in the error for better debugging
 			return DevicesMsg{Err: fmt.Errorf("issue with bluetoothctl devices: %w (output: %s)", err, string(allDevicesOutput))}
 		}
 
-		// Split output into lines and filter empty ones
-		allDevicesLines := strings.Split(strings.TrimSpace(string(allDevicesOutput)), "\n")
-		var devices []string
-		for _, line := range allDevicesLines {
-			if strings.TrimSpace(line) != "" {
-				devices = append(devices, line)
-			}
-		}
+		devices := DeviceLines(string(allDevicesOutput))
 
 		// Fetch connected devices - if this fails, we'll continue with empty connected list
-		connectedDevicesCmd := exec.CommandContext(ctx, "bluetoothctl", "devices", "Connected")
+		connectedDevicesCmd := Bluetoothctl(ctx, "devices", "Connected")
 		connectedDevicesOutput, err := connectedDevicesCmd.CombinedOutput()
 		var connectedDevices []string
 		if err != nil {
@@ -43,19 +36,27 @@ func FetchDevicesCmd() tea.Cmd {
 			// Some systems might not support the "Connected" filter
 			connectedDevices = []string{}
 		} else {
-			// Split output into lines and filter empty ones
-			connectedDevicesLines := strings.Split(strings.TrimSpace(string(connectedDevicesOutput)), "\n")
-			for _, line := range connectedDevicesLines {
-				if strings.TrimSpace(line) != "" {
-					connectedDevices = append(connectedDevices, line)
-				}
-			}
+			connectedDevices = DeviceLines(string(connectedDevicesOutput))
 		}
 
 		return DevicesMsg{Devices: devices, ConnectedDevices: connectedDevices}
 	}
 }
 
+// DeviceLines returns the "Device <MAC> <name>" lines of bluetoothctl
+// output, leaving out prompts and events printed when commands are fed on
+// stdin
+func DeviceLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(ansiRegex.ReplaceAllString(line, ""))
+		if strings.HasPrefix(line, "Device ") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
 // ParseDeviceLine parses a bluetoothctl device line into a BluetoothDevice
 func ParseDeviceLine(line string, connectedMacs map[string]bool) BluetoothDevice {
 	// bluetoothctl output format: "Device MAC_ADDRESS NAME"
@@ -96,3 +97,33 @@ func ParseDevices(deviceLines []string, connectedDeviceLines []string) []Bluetoo
 	}
 	return devices
 }
+
+// MatchDevice picks the device a user named on the command line: by MAC
+// address, or by a name or btui nickname, ignoring case. Several devices
+// sharing the name is an error.
+func MatchDevice(devices []BluetoothDevice, nameOrMAC string) (BluetoothDevice, error) {
+	var matches []BluetoothDevice
+	for _, device := range devices {
+		if strings.EqualFold(device.MacAddress, nameOrMAC) {
+			return device, nil
+		}
+		if strings.EqualFold(device.Name, nameOrMAC) ||
+			strings.EqualFold(state.Get().Meta(device.MacAddress).Nickname, nameOrMAC) {
+			matches = append(matches, device)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return BluetoothDevice{}, fmt.Errorf("no device named %q", nameOrMAC)
+	case 1:
+		return matches[0], nil
+	default:
+		macs := make([]string, len(matches))
+		for i, device := range matches {
+			macs[i] = device.MacAddress
+		}
+		return BluetoothDevice{}, fmt.Errorf("%d devices are named %q, use a MAC address: %s",
+			len(matches), nameOrMAC, strings.Join(macs, ", "))
+	}
+}
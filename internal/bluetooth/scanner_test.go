@@ -1,6 +1,9 @@
 package bluetooth
 
 import (
+	"btui/internal/config"
+	"btui/internal/state"
+	"strings"
 	"testing"
 	"time"
 )
@@ -132,3 +135,86 @@ func TestDiscoveryScanner(t *testing.T) {
 		t.Error("Scanner should not be scanning after stop")
 	}
 }
+
+func TestDiscoveryScannerBatteryChanges(t *testing.T) {
+	scanner := NewDiscoveryScanner()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	scanner.handleLine("[NEW] Device 4C:87:5D:28:86:DD Bose NC 700 HP", now)
+	scanner.handleLine("\x1b[0;93m[CHG]\x1b[0m Device 4C:87:5D:28:86:DD Battery Percentage: 0x5a (90)", now)
+	scanner.handleLine("[CHG] Device DC:2C:26:09:D0:0C Battery Percentage: 0x0f (15)", now)
+	scanner.handleLine("[CHG] Device 4C:87:5D:28:86:DD Battery Percentage: 0x59 (89)", now)
+
+	changes := scanner.TakeBatteryChanges()
+	if len(changes) != 2 || changes["4C:87:5D:28:86:DD"] != 89 || changes["DC:2C:26:09:D0:0C"] != 15 {
+		t.Errorf("Expected the latest level of both devices, got %v", changes)
+	}
+	if changes := scanner.TakeBatteryChanges(); len(changes) != 0 {
+		t.Errorf("Expected changes to be taken only once, got %v", changes)
+	}
+
+	// A battery change is not a sighting of the device
+	devices := scanner.GetDiscoveredDevices()
+	if len(devices) != 1 || devices[0].Name != "Bose NC 700 HP" {
+		t.Errorf("Expected only the advertised device, got %+v", devices)
+	}
+}
+
+func TestDiscoveryTickWaitsForInterval(t *testing.T) {
+	cfg := config.Default()
+	cfg.Intervals.Discovery = 30 * time.Millisecond
+	config.Set(cfg)
+	t.Cleanup(func() { config.Set(config.Default()) })
+
+	scanner := NewDiscoveryScanner()
+	scanner.isScanning = true
+	scanner.handleLine("[NEW] Device F0:99:B6:12:34:56 Living Room TV RSSI: -67", time.Now())
+
+	started := time.Now()
+	msg, ok := DiscoveryTickCmd(scanner)().(DiscoveryUpdateMsg)
+	if elapsed := time.Since(started); elapsed < cfg.Intervals.Discovery {
+		t.Errorf("Expected the update after the %s interval, got it after %s", cfg.Intervals.Discovery, elapsed)
+	}
+	if !ok || len(msg.Devices) != 1 {
+		t.Errorf("Expected an update with the discovered device, got %+v", msg)
+	}
+}
+
+func TestMatchDevice(t *testing.T) {
+	store := state.New("")
+	store.SetMeta("DC:2C:26:09:D0:0C", state.Meta{Nickname: "Work keyboard"})
+	state.Set(store)
+	t.Cleanup(func() { state.Set(state.New("")) })
+
+	devices := []BluetoothDevice{
+		{MacAddress: "4C:87:5D:28:86:DD", Name: "Bose NC 700 Headphones"},
+		{MacAddress: "DC:2C:26:09:D0:0C", Name: "Keychron K4"},
+		{MacAddress: "F0:99:B6:12:34:56", Name: "Speaker"},
+		{MacAddress: "F0:99:B6:12:34:57", Name: "Speaker"},
+	}
+
+	tests := []struct {
+		query    string
+		expected string
+		errPart  string
+	}{
+		{"4c:87:5d:28:86:dd", "4C:87:5D:28:86:DD", ""},
+		{"keychron k4", "DC:2C:26:09:D0:0C", ""},
+		{"work keyboard", "DC:2C:26:09:D0:0C", ""},
+		{"Speaker", "", "2 devices are named"},
+		{"Toaster", "", "no device named"},
+	}
+
+	for _, tt := range tests {
+		device, err := MatchDevice(devices, tt.query)
+		if tt.errPart != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.errPart) {
+				t.Errorf("MatchDevice(%q) error = %v, expected %q", tt.query, err, tt.errPart)
+			}
+			continue
+		}
+		if err != nil || device.MacAddress != tt.expected {
+			t.Errorf("MatchDevice(%q) = %s, %v; expected %s", tt.query, device.MacAddress, err, tt.expected)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package bluetooth
 
+import (
+	"btui/internal/query"
+	"btui/internal/state"
+)
+
 // BluetoothDevice represents a Bluetooth device
 type BluetoothDevice struct {
 	MacAddress string
@@ -8,6 +13,27 @@ type BluetoothDevice struct {
 	Connected  bool
 	Paired     bool
 	RSSI       string
+	// Battery is the charge left in percent when HasBattery is set; a device
+	// that does not report one leaves HasBattery unset
+	Battery    int
+	HasBattery bool
+}
+
+// QueryDevice returns the view of a device that filter queries, hook device
+// queries and presence rules match against: its btui-side metadata, its
+// bluetoothctl icon name and its signal strength in dBm (0 when unknown)
+func QueryDevice(d BluetoothDevice, meta state.Meta, icon string, rssi int) query.Device {
+	return query.Device{
+		Name:      d.Name,
+		Nickname:  meta.Nickname,
+		Note:      meta.Note,
+		Tags:      meta.Tags,
+		MAC:       d.MacAddress,
+		Type:      icon,
+		RSSI:      rssi,
+		Connected: d.Connected,
+		Paired:    d.Paired,
+	}
 }
 
 // DevicesMsg represents the result of scanning for devices
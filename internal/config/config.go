@@ -0,0 +1,823 @@
+// Package config loads and validates the btui configuration file
+package config
+
+import (
+	"btui/internal/query"
+	"btui/internal/ui"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Supported startup views
+const (
+	ViewScan        = "scan"
+	ViewMenu        = "menu"
+	ViewListDevices = "list-devices"
+	ViewConnect     = "connect"
+	ViewDisconnect  = "disconnect"
+)
+
+// Supported sort orders for the device list
+const (
+	SortName = "name"
+	SortRSSI = "rssi"
+	SortMAC  = "mac"
+)
+
+// Where favorite devices are placed in the device list
+const (
+	FavoritesTop  = "top"  // above all status tiers
+	FavoritesTier = "tier" // first within their own status tier
+)
+
+// Supported device list layouts
+const (
+	LayoutList  = "list"
+	LayoutTable = "table"
+)
+
+// Table view columns, in their default order
+const (
+	ColumnName     = "name"
+	ColumnMAC      = "mac"
+	ColumnStatus   = "status"
+	ColumnRSSI     = "rssi"
+	ColumnType     = "type"
+	ColumnBattery  = "battery"
+	ColumnLastSeen = "last_seen"
+)
+
+// AllColumns lists every table column in display order
+var AllColumns = []string{
+	ColumnName, ColumnMAC, ColumnStatus, ColumnRSSI, ColumnType, ColumnBattery, ColumnLastSeen,
+}
+
+// What a scene step does with its device
+const (
+	SceneConnect    = "connect"
+	SceneDisconnect = "disconnect"
+)
+
+// Connect failures that can be retried, named in the [retry] errors setting
+const (
+	RetryPageTimeout        = "page-timeout"        // the device did not answer in time
+	RetryInProgress         = "in-progress"         // another connect was still under way
+	RetryProfileUnavailable = "profile-unavailable" // no audio or input profile was ready yet
+)
+
+// Device events that can run a hook command
+const (
+	HookConnect    = "connect"     // a paired device connected
+	HookDisconnect = "disconnect"  // a paired device disconnected
+	HookDiscover   = "discover"    // a device came into range during discovery
+	HookBattery    = "battery"     // a connected device reported a new charge
+	HookLowBattery = "low_battery" // a charge fell to a [battery] threshold
+	HookPresent    = "present"     // a device matching a presence rule arrived
+	HookAbsent     = "absent"      // a device matching a presence rule left
+)
+
+// HookEvents lists every event a hook can run on
+var HookEvents = []string{HookConnect, HookDisconnect, HookDiscover, HookBattery, HookLowBattery, HookPresent, HookAbsent}
+
+// RetryErrors lists every retryable failure class
+var RetryErrors = []string{RetryPageTimeout, RetryInProgress, RetryProfileUnavailable}
+
+// Config holds all user-configurable settings
+type Config struct {
+	StartupView   string              `toml:"startup_view"`
+	AutoScan      bool                `toml:"auto_scan"`
+	Adapter       string              `toml:"adapter"`
+	Accessible    bool                `toml:"accessible"`
+	Mouse         bool                `toml:"mouse"`
+	Timeouts      Timeouts            `toml:"timeouts"`
+	Intervals     Intervals           `toml:"intervals"`
+	Operations    Operations          `toml:"operations"`
+	Retry         Retry               `toml:"retry"`
+	Notifications Notifications       `toml:"notifications"`
+	Battery       Battery             `toml:"battery"`
+	Hooks         Hooks               `toml:"hooks"`
+	Presence      Presence            `toml:"presence"`
+	Window        Window              `toml:"window"`
+	List          List                `toml:"list"`
+	Table         Table               `toml:"table"`
+	Keys          map[string][]string `toml:"keys"`
+	Theme         Theme               `toml:"theme"`
+	Scenes        map[string]Scene    `toml:"scenes"`
+}
+
+// Timeouts bounds how long each bluetoothctl invocation may run
+type Timeouts struct {
+	Connect    time.Duration `toml:"connect"`
+	Disconnect time.Duration `toml:"disconnect"`
+	Scan       time.Duration `toml:"scan"`
+	Fetch      time.Duration `toml:"fetch"`
+}
+
+// Intervals controls how often the UI polls for changes
+type Intervals struct {
+	Discovery time.Duration `toml:"discovery"`
+	UIUpdate  time.Duration `toml:"ui_update"`
+	// Devices is how often the scan view relists paired devices, to notice
+	// connections made outside btui
+	Devices time.Duration `toml:"devices"`
+}
+
+// Operations limits how many connects, disconnects and other device
+// operations run at once; operations on one device always run in turn
+type Operations struct {
+	Concurrency int `toml:"concurrency"`
+}
+
+// Retry controls how failed connects are tried again: up to Attempts tries
+// in all, waiting Backoff before the second and twice as long before each
+// one after that, for failures of the listed classes only
+type Retry struct {
+	Attempts int           `toml:"attempts"`
+	Backoff  time.Duration `toml:"backoff"`
+	Errors   []string      `toml:"errors"`
+}
+
+// Notifications picks the events that raise desktop notifications, and
+// keeps them from piling up: one kind of event about one device is shown at
+// most once per Interval
+type Notifications struct {
+	Enabled    bool          `toml:"enabled"`
+	Connect    bool          `toml:"connect"`
+	Disconnect bool          `toml:"disconnect"`
+	LowBattery bool          `toml:"low_battery"`
+	Interval   time.Duration `toml:"interval"`
+}
+
+// Battery controls how often connected devices are asked for their charge
+// and when a low one is warned about: once each time it falls to or below
+// one of the Thresholds, in percent. Command, if set, is run with each
+// warning like a low_battery hook.
+type Battery struct {
+	Interval   time.Duration `toml:"interval"`
+	Thresholds []int         `toml:"thresholds"`
+	Command    string        `toml:"command"`
+}
+
+// Hooks run commands when device events happen. Each command runs through
+// the shell for at most Timeout, with no more than Concurrency at once.
+type Hooks struct {
+	Timeout     time.Duration `toml:"timeout"`
+	Concurrency int           `toml:"concurrency"`
+	Run         []Hook        `toml:"run"`
+}
+
+// Hook is a command run on an event for the devices matching Device, a filter
+// query such as "tag:headset"; an empty one matches every device
+type Hook struct {
+	Event   string `toml:"event"`
+	Device  string `toml:"device"`
+	Command string `toml:"command"`
+}
+
+// Presence decides when devices count as present, from their signal
+// strength during discovery. A device arrives once heard at Enter dBm or
+// stronger, and leaves once it has not been heard at Exit dBm or stronger
+// for Timeout. Enter, Exit and Timeout are the defaults for every rule.
+type Presence struct {
+	Enter   int            `toml:"enter"`
+	Exit    int            `toml:"exit"`
+	Timeout time.Duration  `toml:"timeout"`
+	Rules   []PresenceRule `toml:"rule"`
+}
+
+// PresenceRule follows the devices matching Device, a filter query such as
+// "tag:phone". Zero thresholds and timeout fall back to the [presence] ones.
+type PresenceRule struct {
+	Name    string        `toml:"name"`
+	Device  string        `toml:"device"`
+	Enter   int           `toml:"enter"`
+	Exit    int           `toml:"exit"`
+	Timeout time.Duration `toml:"timeout"`
+}
+
+// Resolved returns the rules with the [presence] defaults filled in
+func (p Presence) Resolved() []PresenceRule {
+	rules := make([]PresenceRule, len(p.Rules))
+	for i, r := range p.Rules {
+		if r.Enter == 0 {
+			r.Enter = p.Enter
+		}
+		if r.Exit == 0 {
+			r.Exit = p.Exit
+		}
+		if r.Timeout == 0 {
+			r.Timeout = p.Timeout
+		}
+		rules[i] = r
+	}
+	return rules
+}
+
+// Window holds the dimensions used before the terminal reports its size and
+// the width from which the scan view shows a detail pane beside the list
+type Window struct {
+	Width      int `toml:"width"`
+	Height     int `toml:"height"`
+	SplitWidth int `toml:"split_width"`
+}
+
+// List controls how the device list is presented
+type List struct {
+	Sort      string `toml:"sort"`
+	View      string `toml:"view"`
+	Favorites string `toml:"favorites"`
+}
+
+// Table controls the column-based device view
+type Table struct {
+	Columns    []string `toml:"columns"`
+	Sort       string   `toml:"sort"`
+	Descending bool     `toml:"descending"`
+}
+
+// Theme selects the colour palette. Name is "auto", a built-in preset or one
+// of the user palettes defined under [theme.palettes.<name>].
+type Theme struct {
+	Name     string                `toml:"name"`
+	Palettes map[string]ui.Palette `toml:"palettes"`
+}
+
+// Scene is a named group of devices to connect or disconnect in one go.
+// Steps start in order with at most Concurrency running at once; 0 means one
+// at a time.
+type Scene struct {
+	Steps       []SceneStep `toml:"steps" json:"steps"`
+	Concurrency int         `toml:"concurrency" json:"concurrency,omitempty"`
+}
+
+// SceneStep names a device, by MAC address, name or nickname, and whether to
+// connect or disconnect it
+type SceneStep struct {
+	Device string `toml:"device" json:"device"`
+	Action string `toml:"action" json:"action"`
+}
+
+// Scan view actions that can be rebound in the [keys] section
+const (
+	ActionUp         = "up"
+	ActionDown       = "down"
+	ActionViUp       = "vi_up"
+	ActionViDown     = "vi_down"
+	ActionEnter      = "enter"
+	ActionScan       = "scan"
+	ActionConnect    = "connect"
+	ActionDisconnect = "disconnect"
+	ActionRefresh    = "refresh"
+	ActionQuit       = "quit"
+	ActionToggleView = "toggle_view"
+	ActionSortColumn = "sort_column"
+	ActionSortOrder  = "sort_order"
+	ActionFavorite   = "favorite"
+	ActionFavorites  = "favorites_only"
+	ActionHide       = "hide"
+	ActionHidden     = "hidden"
+	ActionEdit       = "edit"
+	ActionAlias      = "alias"
+	ActionMark       = "mark"
+	ActionTrust      = "trust"
+	ActionRemove     = "remove"
+	ActionScenes     = "scenes"
+	ActionQueue      = "queue"
+	ActionCancel     = "cancel"
+	ActionDetails    = "details"
+	ActionLog        = "log"
+)
+
+// DefaultKeys returns the built-in key bindings for every scan view action
+func DefaultKeys() map[string][]string {
+	return map[string][]string{
+		ActionUp:         {"up"},
+		ActionDown:       {"down"},
+		ActionViUp:       {"k"},
+		ActionViDown:     {"j"},
+		ActionEnter:      {"enter"},
+		ActionScan:       {"s"},
+		ActionConnect:    {"c"},
+		ActionDisconnect: {"d"},
+		ActionRefresh:    {"r"},
+		ActionQuit:       {"q", "ctrl+c"},
+		ActionToggleView: {"t"},
+		ActionSortColumn: {"o"},
+		ActionSortOrder:  {"O"},
+		ActionFavorite:   {"f"},
+		ActionFavorites:  {"F"},
+		ActionHide:       {"i"},
+		ActionHidden:     {"I"},
+		ActionEdit:       {"e"},
+		ActionAlias:      {"a"},
+		ActionMark:       {"space"},
+		ActionTrust:      {"T"},
+		ActionRemove:     {"X"},
+		ActionScenes:     {"S"},
+		ActionQueue:      {"Q"},
+		ActionCancel:     {"esc"},
+		ActionDetails:    {"E"},
+		ActionLog:        {"L"},
+	}
+}
+
+// ScanKeys returns the effective scan view key bindings: the defaults with
+// any actions listed in the [keys] section replaced
+func (c Config) ScanKeys() map[string][]string {
+	keys := DefaultKeys()
+	for action, bound := range c.Keys {
+		keys[action] = bound
+	}
+	return keys
+}
+
+// Default returns the configuration used when no file is present
+func Default() Config {
+	return Config{
+		StartupView: ViewScan,
+		Mouse:       true,
+		Timeouts: Timeouts{
+			Connect:    15 * time.Second,
+			Disconnect: 10 * time.Second,
+			Scan:       10 * time.Second,
+			Fetch:      10 * time.Second,
+		},
+		Intervals: Intervals{
+			Discovery: 500 * time.Millisecond,
+			UIUpdate:  200 * time.Millisecond,
+			Devices:   5 * time.Second,
+		},
+		Operations: Operations{
+			Concurrency: 2,
+		},
+		Retry: Retry{
+			Attempts: 3,
+			Backoff:  time.Second,
+			Errors:   []string{RetryPageTimeout, RetryInProgress},
+		},
+		Notifications: Notifications{
+			Connect:    true,
+			Disconnect: true,
+			LowBattery: true,
+			Interval:   time.Minute,
+		},
+		Battery: Battery{
+			Interval:   time.Minute,
+			Thresholds: []int{20, 10, 5},
+		},
+		Hooks: Hooks{
+			Timeout:     10 * time.Second,
+			Concurrency: 2,
+		},
+		Presence: Presence{
+			Enter:   -70,
+			Exit:    -80,
+			Timeout: 2 * time.Minute,
+		},
+		Window: Window{
+			Width:      80,
+			Height:     14,
+			SplitWidth: 100,
+		},
+		List: List{
+			Sort:      SortName,
+			View:      LayoutList,
+			Favorites: FavoritesTop,
+		},
+		Table: Table{
+			Columns: append([]string(nil), AllColumns...),
+			Sort:    ColumnStatus,
+		},
+		Theme: Theme{
+			Name: ui.ThemeAuto,
+		},
+	}
+}
+
+var current atomic.Pointer[Config]
+
+// Get returns the active configuration, falling back to defaults if none was set
+func Get() Config {
+	if c := current.Load(); c != nil {
+		return *c
+	}
+	return Default()
+}
+
+// Set replaces the active configuration
+func Set(c Config) {
+	current.Store(&c)
+}
+
+// DefaultPath returns the config file location under XDG_CONFIG_HOME
+func DefaultPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot determine config directory: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "btui", "config.toml"), nil
+}
+
+// Load reads the config file at path. An empty path means the default
+// location, which is allowed not to exist; an explicit path must exist.
+func Load(path string) (Config, error) {
+	explicit := path != ""
+	if !explicit {
+		p, err := DefaultPath()
+		if err != nil {
+			return Default(), nil
+		}
+		path = p
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			return Default(), nil
+		}
+		return Config{}, fmt.Errorf("read config %s: %w", path, err)
+	}
+
+	cfg, err := Parse(string(data))
+	if err != nil {
+		return Config{}, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse decodes TOML config data on top of the defaults and validates it
+func Parse(data string) (Config, error) {
+	cfg := Default()
+	md, err := toml.Decode(data, &cfg)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, k := range undecoded {
+			keys[i] = k.String()
+		}
+		return Config{}, fmt.Errorf("unknown setting(s): %s", strings.Join(keys, ", "))
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Validate checks that every setting holds a usable value
+func (c Config) Validate() error {
+	var errs []error
+
+	switch c.StartupView {
+	case ViewScan, ViewMenu, ViewListDevices, ViewConnect, ViewDisconnect:
+	default:
+		errs = append(errs, fmt.Errorf("startup_view must be one of %s, %s, %s, %s or %s, got %q",
+			ViewScan, ViewMenu, ViewListDevices, ViewConnect, ViewDisconnect, c.StartupView))
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"timeouts.connect", c.Timeouts.Connect},
+		{"timeouts.disconnect", c.Timeouts.Disconnect},
+		{"timeouts.scan", c.Timeouts.Scan},
+		{"timeouts.fetch", c.Timeouts.Fetch},
+		{"intervals.discovery", c.Intervals.Discovery},
+		{"intervals.ui_update", c.Intervals.UIUpdate},
+		{"intervals.devices", c.Intervals.Devices},
+		{"battery.interval", c.Battery.Interval},
+		{"hooks.timeout", c.Hooks.Timeout},
+		{"presence.timeout", c.Presence.Timeout},
+	}
+	for _, d := range durations {
+		if d.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be a positive duration such as \"10s\", got %q", d.name, d.value))
+		}
+	}
+
+	if c.Operations.Concurrency < 1 {
+		errs = append(errs, fmt.Errorf("operations.concurrency must be at least 1, got %d", c.Operations.Concurrency))
+	}
+	errs = append(errs, validateRetry(c.Retry)...)
+	if c.Notifications.Interval < 0 {
+		errs = append(errs, fmt.Errorf("notifications.interval must be 0 or a duration such as \"1m\", got %q", c.Notifications.Interval))
+	}
+	errs = append(errs, validateBattery(c.Battery)...)
+	errs = append(errs, validateHooks(c.Hooks)...)
+	errs = append(errs, validatePresence(c.Presence)...)
+	if c.Window.Width < 20 {
+		errs = append(errs, fmt.Errorf("window.width must be at least 20, got %d", c.Window.Width))
+	}
+	if c.Window.Height < 5 {
+		errs = append(errs, fmt.Errorf("window.height must be at least 5, got %d", c.Window.Height))
+	}
+	if c.Window.SplitWidth < 0 {
+		errs = append(errs, fmt.Errorf("window.split_width must be 0 (never split) or a width in columns, got %d", c.Window.SplitWidth))
+	}
+
+	switch c.List.Sort {
+	case SortName, SortRSSI, SortMAC:
+	default:
+		errs = append(errs, fmt.Errorf("list.sort must be one of %s, %s or %s, got %q",
+			SortName, SortRSSI, SortMAC, c.List.Sort))
+	}
+
+	switch c.List.Favorites {
+	case FavoritesTop, FavoritesTier:
+	default:
+		errs = append(errs, fmt.Errorf("list.favorites must be %s or %s, got %q", FavoritesTop, FavoritesTier, c.List.Favorites))
+	}
+
+	switch c.List.View {
+	case LayoutList, LayoutTable:
+	default:
+		errs = append(errs, fmt.Errorf("list.view must be %s or %s, got %q", LayoutList, LayoutTable, c.List.View))
+	}
+
+	errs = append(errs, validateTable(c.Table)...)
+
+	if c.Adapter != "" && strings.ContainsAny(c.Adapter, " \t\n") {
+		errs = append(errs, fmt.Errorf("adapter must be a controller address or name without whitespace, got %q", c.Adapter))
+	}
+
+	errs = append(errs, validateKeys(c.Keys, c.ScanKeys())...)
+	errs = append(errs, validateTheme(c.Theme)...)
+	for _, name := range sortedScenes(c.Scenes) {
+		if err := ValidateScene(name, c.Scenes[name]); err != nil {
+			errs = append(errs, fmt.Errorf("scenes.%w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateRetry checks the [retry] settings
+func validateRetry(r Retry) []error {
+	var errs []error
+	if r.Attempts < 1 {
+		errs = append(errs, fmt.Errorf("retry.attempts must be at least 1 (no retries), got %d", r.Attempts))
+	}
+	if r.Backoff < 0 {
+		errs = append(errs, fmt.Errorf("retry.backoff must be 0 or a duration such as \"1s\", got %q", r.Backoff))
+	}
+	for _, e := range r.Errors {
+		if !slices.Contains(RetryErrors, e) {
+			errs = append(errs, fmt.Errorf("retry.errors: unknown failure %q, expected one of %s", e, strings.Join(RetryErrors, ", ")))
+		}
+	}
+	return errs
+}
+
+// validateBattery checks the low battery thresholds are usable percentages
+func validateBattery(b Battery) []error {
+	var errs []error
+	seen := make(map[int]bool, len(b.Thresholds))
+	for _, threshold := range b.Thresholds {
+		switch {
+		case threshold < 1 || threshold > 100:
+			errs = append(errs, fmt.Errorf("battery.thresholds must be percentages from 1 to 100, got %d", threshold))
+		case seen[threshold]:
+			errs = append(errs, fmt.Errorf("battery.thresholds: %d is listed more than once", threshold))
+		}
+		seen[threshold] = true
+	}
+	return errs
+}
+
+// validateHooks checks every hook names a known event, a usable device query
+// and a command
+func validateHooks(h Hooks) []error {
+	var errs []error
+	if h.Concurrency < 1 {
+		errs = append(errs, fmt.Errorf("hooks.concurrency must be at least 1, got %d", h.Concurrency))
+	}
+	for i, hook := range h.Run {
+		if !slices.Contains(HookEvents, hook.Event) {
+			errs = append(errs, fmt.Errorf("hooks.run %d: event must be one of %s, got %q",
+				i+1, strings.Join(HookEvents, ", "), hook.Event))
+		}
+		if _, err := query.Parse(hook.Device); err != nil {
+			errs = append(errs, fmt.Errorf("hooks.run %d: device: %w", i+1, err))
+		}
+		if strings.TrimSpace(hook.Command) == "" {
+			errs = append(errs, fmt.Errorf("hooks.run %d needs a command", i+1))
+		}
+	}
+	return errs
+}
+
+// validatePresence checks the signal thresholds leave room for hysteresis
+// and every rule has a unique name and a usable device query
+func validatePresence(p Presence) []error {
+	var errs []error
+	thresholds := func(where string, enter, exit int) {
+		for _, t := range []struct {
+			name  string
+			value int
+		}{{"enter", enter}, {"exit", exit}} {
+			if t.value < -127 || t.value >= 0 {
+				errs = append(errs, fmt.Errorf("%s %s must be between -127 and -1 dBm, got %d", where, t.name, t.value))
+			}
+		}
+		if exit > enter {
+			errs = append(errs, fmt.Errorf("%s exit (%d dBm) must not be above enter (%d dBm)", where, exit, enter))
+		}
+	}
+	thresholds("presence", p.Enter, p.Exit)
+
+	names := make(map[string]bool, len(p.Rules))
+	for i, r := range p.Resolved() {
+		where := fmt.Sprintf("presence.rule %d", i+1)
+		if r.Name == "" {
+			errs = append(errs, fmt.Errorf("%s needs a name", where))
+		} else if names[r.Name] {
+			errs = append(errs, fmt.Errorf("%s: name %q is already used", where, r.Name))
+		}
+		names[r.Name] = true
+		if strings.TrimSpace(r.Device) == "" {
+			errs = append(errs, fmt.Errorf("%s needs a device query", where))
+		} else if _, err := query.Parse(r.Device); err != nil {
+			errs = append(errs, fmt.Errorf("%s: device: %w", where, err))
+		}
+		if p.Rules[i].Enter != 0 || p.Rules[i].Exit != 0 {
+			thresholds(where, r.Enter, r.Exit)
+		}
+		if r.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("%s: timeout must be a positive duration, got %s", where, r.Timeout))
+		}
+	}
+	return errs
+}
+
+// reservedKeys are handled by the device list itself and cannot be rebound.
+// The list's paging and jump keys are not reserved: the scan view takes any an
+// action claims away from the list.
+var reservedKeys = map[string]string{
+	"/":   "filtering",
+	"?":   "help",
+	"esc": "clearing the filter",
+}
+
+// validateKeys checks the [keys] overrides and reports keys that are bound
+// to more than one action in the effective key map
+func validateKeys(overrides, effective map[string][]string) []error {
+	var errs []error
+
+	known := DefaultKeys()
+	for _, action := range sortedActions(overrides) {
+		if _, ok := known[action]; !ok {
+			errs = append(errs, fmt.Errorf("keys.%s is not a known action", action))
+			continue
+		}
+		if len(overrides[action]) == 0 {
+			errs = append(errs, fmt.Errorf("keys.%s must list at least one key", action))
+		}
+		for _, k := range overrides[action] {
+			if strings.TrimSpace(k) == "" {
+				errs = append(errs, fmt.Errorf("keys.%s contains an empty key", action))
+			} else if purpose, ok := reservedKeys[k]; ok && !(action == ActionCancel && k == "esc") {
+				// Cancelling gives way to clearing the filter, so it may share esc
+				errs = append(errs, fmt.Errorf("keys.%s: %q is reserved for %s", action, k, purpose))
+			}
+		}
+	}
+
+	owner := make(map[string]string)
+	for _, action := range sortedActions(effective) {
+		if _, ok := known[action]; !ok {
+			continue
+		}
+		for _, k := range effective[action] {
+			if other, taken := owner[k]; taken && other != action {
+				errs = append(errs, fmt.Errorf("keys: %q is bound to both %s and %s", k, other, action))
+				continue
+			}
+			owner[k] = action
+		}
+	}
+
+	return errs
+}
+
+// sortedActions returns the actions of a key map in a stable order
+func sortedActions(keys map[string][]string) []string {
+	actions := make([]string, 0, len(keys))
+	for action := range keys {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// validateTheme checks the selected theme exists and user palettes are usable
+func validateTheme(t Theme) []error {
+	var errs []error
+
+	names := make([]string, 0, len(t.Palettes))
+	for name := range t.Palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := ui.Presets[name]; ok || name == ui.ThemeAuto {
+			errs = append(errs, fmt.Errorf("theme.palettes.%s shadows a built-in theme, choose another name", name))
+			continue
+		}
+		if err := t.Palettes[name].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("theme.palettes.%s: %w", name, err))
+		}
+	}
+
+	_, preset := ui.Presets[t.Name]
+	_, custom := t.Palettes[t.Name]
+	if t.Name != ui.ThemeAuto && !preset && !custom {
+		errs = append(errs, fmt.Errorf("theme.name must be %s, one of %s or a palette under [theme.palettes], got %q",
+			ui.ThemeAuto, strings.Join(ui.PresetNames(), ", "), t.Name))
+	}
+
+	return errs
+}
+
+// ValidateScene checks a scene can be run; errors start with the scene name
+func ValidateScene(name string, s Scene) error {
+	var errs []error
+	if strings.TrimSpace(name) == "" {
+		return errors.New("scene names cannot be empty")
+	}
+	if len(s.Steps) == 0 {
+		errs = append(errs, fmt.Errorf("%s must have at least one step", name))
+	}
+	for i, step := range s.Steps {
+		if strings.TrimSpace(step.Device) == "" {
+			errs = append(errs, fmt.Errorf("%s step %d needs a device", name, i+1))
+		}
+		switch step.Action {
+		case SceneConnect, SceneDisconnect:
+		default:
+			errs = append(errs, fmt.Errorf("%s step %d: action must be %s or %s, got %q",
+				name, i+1, SceneConnect, SceneDisconnect, step.Action))
+		}
+	}
+	if s.Concurrency < 0 {
+		errs = append(errs, fmt.Errorf("%s: concurrency must be 0 or more, got %d", name, s.Concurrency))
+	}
+	return errors.Join(errs...)
+}
+
+// sortedScenes returns scene names in a stable order
+func sortedScenes(scenes map[string]Scene) []string {
+	names := make([]string, 0, len(scenes))
+	for name := range scenes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateTable checks table columns and the sort column are known
+func validateTable(t Table) []error {
+	var errs []error
+
+	known := make(map[string]bool, len(AllColumns))
+	for _, column := range AllColumns {
+		known[column] = true
+	}
+	columnList := strings.Join(AllColumns, ", ")
+
+	if len(t.Columns) == 0 {
+		errs = append(errs, fmt.Errorf("table.columns must list at least one of %s", columnList))
+	}
+	seen := make(map[string]bool, len(t.Columns))
+	for _, column := range t.Columns {
+		switch {
+		case !known[column]:
+			errs = append(errs, fmt.Errorf("table.columns: unknown column %q, expected one of %s", column, columnList))
+		case seen[column]:
+			errs = append(errs, fmt.Errorf("table.columns: %q is listed more than once", column))
+		}
+		seen[column] = true
+	}
+
+	if !known[t.Sort] {
+		errs = append(errs, fmt.Errorf("table.sort must be one of %s, got %q", columnList, t.Sort))
+	}
+
+	return errs
+}
@@ -0,0 +1,424 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultIsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Errorf("Expected default config to be valid, got: %v", err)
+	}
+}
+
+func TestDefaultValues(t *testing.T) {
+	cfg := Default()
+
+	if cfg.Timeouts.Connect != 15*time.Second {
+		t.Errorf("Expected connect timeout 15s, got %v", cfg.Timeouts.Connect)
+	}
+	if cfg.Timeouts.Disconnect != 10*time.Second {
+		t.Errorf("Expected disconnect timeout 10s, got %v", cfg.Timeouts.Disconnect)
+	}
+	if cfg.Intervals.Discovery != 500*time.Millisecond {
+		t.Errorf("Expected discovery interval 500ms, got %v", cfg.Intervals.Discovery)
+	}
+	if cfg.Intervals.UIUpdate != 200*time.Millisecond {
+		t.Errorf("Expected UI update interval 200ms, got %v", cfg.Intervals.UIUpdate)
+	}
+	if cfg.Intervals.Devices != 5*time.Second {
+		t.Errorf("Expected devices interval 5s, got %v", cfg.Intervals.Devices)
+	}
+	if cfg.Window.Width != 80 || cfg.Window.Height != 14 {
+		t.Errorf("Expected window 80x14, got %dx%d", cfg.Window.Width, cfg.Window.Height)
+	}
+	if !cfg.Mouse {
+		t.Error("Expected mouse support to be on by default")
+	}
+	if cfg.Operations.Concurrency != 2 {
+		t.Errorf("Expected 2 operations at once, got %d", cfg.Operations.Concurrency)
+	}
+	if cfg.Notifications.Enabled || !cfg.Notifications.Disconnect || cfg.Notifications.Interval != time.Minute {
+		t.Errorf("Expected desktop notifications off, once a minute when on, got %+v", cfg.Notifications)
+	}
+	if cfg.Retry.Attempts != 3 || cfg.Retry.Backoff != time.Second || len(cfg.Retry.Errors) != 2 {
+		t.Errorf("Expected 3 connect attempts 1s apart on two failures, got %+v", cfg.Retry)
+	}
+	if cfg.Battery.Interval != time.Minute || len(cfg.Battery.Thresholds) != 3 || cfg.Battery.Command != "" {
+		t.Errorf("Expected batteries read once a minute with three thresholds and no command, got %+v", cfg.Battery)
+	}
+	if cfg.Window.SplitWidth != 100 {
+		t.Errorf("Expected split width 100, got %d", cfg.Window.SplitWidth)
+	}
+	if cfg.StartupView != ViewScan {
+		t.Errorf("Expected startup view %q, got %q", ViewScan, cfg.StartupView)
+	}
+}
+
+func TestParseOverridesDefaults(t *testing.T) {
+	cfg, err := Parse(`
+startup_view = "menu"
+auto_scan = true
+adapter = "00:1A:7D:DA:71:13"
+
+[timeouts]
+connect = "30s"
+
+[list]
+sort = "rssi"
+
+[keys]
+connect = ["C"]
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cfg.StartupView != ViewMenu {
+		t.Errorf("Expected startup view %q, got %q", ViewMenu, cfg.StartupView)
+	}
+	if !cfg.AutoScan {
+		t.Error("Expected auto_scan to be true")
+	}
+	if cfg.Adapter != "00:1A:7D:DA:71:13" {
+		t.Errorf("Expected adapter to be set, got %q", cfg.Adapter)
+	}
+	if cfg.Timeouts.Connect != 30*time.Second {
+		t.Errorf("Expected connect timeout 30s, got %v", cfg.Timeouts.Connect)
+	}
+	// Unset values keep their defaults
+	if cfg.Timeouts.Disconnect != 10*time.Second {
+		t.Errorf("Expected disconnect timeout to keep default 10s, got %v", cfg.Timeouts.Disconnect)
+	}
+	if cfg.List.Sort != SortRSSI {
+		t.Errorf("Expected sort %q, got %q", SortRSSI, cfg.List.Sort)
+	}
+
+	keys := cfg.ScanKeys()
+	if len(keys[ActionConnect]) != 1 || keys[ActionConnect][0] != "C" {
+		t.Errorf("Expected connect to be rebound to C, got %v", keys[ActionConnect])
+	}
+	if len(keys[ActionDisconnect]) != 1 || keys[ActionDisconnect][0] != "d" {
+		t.Errorf("Expected disconnect to keep default d, got %v", keys[ActionDisconnect])
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"syntax error", "startup_view = ", "line 1"},
+		{"unknown setting", "colour = \"red\"", "unknown setting(s): colour"},
+		{"bad startup view", "startup_view = \"tree\"", "startup_view must be one of"},
+		{"negative timeout", "[timeouts]\nconnect = \"-1s\"", "timeouts.connect must be a positive duration"},
+		{"zero interval", "[intervals]\nui_update = \"0s\"", "intervals.ui_update must be a positive duration"},
+		{"bad duration", "[timeouts]\nfetch = \"soon\"", "fetch"},
+		{"tiny window", "[window]\nwidth = 5", "window.width must be at least 20"},
+		{"no operations at once", "[operations]\nconcurrency = 0", "operations.concurrency must be at least 1"},
+		{"no attempts", "[retry]\nattempts = 0", "retry.attempts must be at least 1"},
+		{"negative backoff", "[retry]\nbackoff = \"-1s\"", "retry.backoff must be 0 or a duration"},
+		{"negative notification interval", "[notifications]\ninterval = \"-1s\"", "notifications.interval must be 0 or a duration"},
+		{"unknown retry error", "[retry]\nerrors = [\"timeout\"]", `retry.errors: unknown failure "timeout"`},
+		{"zero battery interval", "[battery]\ninterval = \"0s\"", "battery.interval must be a positive duration"},
+		{"battery threshold out of range", "[battery]\nthresholds = [20, 120]", "battery.thresholds must be percentages from 1 to 100, got 120"},
+		{"duplicate battery threshold", "[battery]\nthresholds = [10, 10]", "battery.thresholds: 10 is listed more than once"},
+		{"zero hook timeout", "[hooks]\ntimeout = \"0s\"", "hooks.timeout must be a positive duration"},
+		{"no hooks at once", "[hooks]\nconcurrency = 0", "hooks.concurrency must be at least 1"},
+		{"unknown hook event", "[[hooks.run]]\nevent = \"sneeze\"\ncommand = \"true\"", `hooks.run 1: event must be one of connect, disconnect, discover, battery, low_battery, present, absent, got "sneeze"`},
+		{"presence exit above enter", "[presence]\nenter = -80\nexit = -70", "presence exit (-70 dBm) must not be above enter (-80 dBm)"},
+		{"positive presence threshold", "[presence]\nenter = 10", "presence enter must be between -127 and -1 dBm, got 10"},
+		{"zero presence timeout", "[presence]\ntimeout = \"0s\"", "presence.timeout must be a positive duration"},
+		{"presence rule without name", "[[presence.rule]]\ndevice = \"tag:phone\"", "presence.rule 1 needs a name"},
+		{"presence rule without device", "[[presence.rule]]\nname = \"phone\"", "presence.rule 1 needs a device query"},
+		{"duplicate presence rule", "[[presence.rule]]\nname = \"phone\"\ndevice = \"tag:phone\"\n[[presence.rule]]\nname = \"phone\"\ndevice = \"name:pixel\"", `presence.rule 2: name "phone" is already used`},
+		{"presence rule exit above enter", "[[presence.rule]]\nname = \"phone\"\ndevice = \"tag:phone\"\nexit = -60", "presence.rule 1 exit (-60 dBm) must not be above enter (-70 dBm)"},
+		{"bad hook device", "[[hooks.run]]\nevent = \"connect\"\ndevice = \"colour:red\"\ncommand = \"true\"", `hooks.run 1: device: column 1: unknown field "colour"`},
+		{"hook without command", "[[hooks.run]]\nevent = \"connect\"", "hooks.run 1 needs a command"},
+		{"negative split width", "[window]\nsplit_width = -1", "window.split_width must be 0"},
+		{"bad sort", "[list]\nsort = \"age\"", "list.sort must be one of"},
+		{"unknown action", "[keys]\nexplode = [\"x\"]", "keys.explode is not a known action"},
+		{"empty binding", "[keys]\nquit = []", "keys.quit must list at least one key"},
+		{"blank key", "[keys]\nquit = [\" \"]", "keys.quit contains an empty key"},
+		{"bad favorites placement", "[list]\nfavorites = \"bottom\"", "list.favorites must be top or tier"},
+		{"bad view", "[list]\nview = \"grid\"", "list.view must be list or table"},
+		{"unknown column", "[table]\ncolumns = [\"name\", \"colour\"]", "table.columns: unknown column \"colour\""},
+		{"duplicate column", "[table]\ncolumns = [\"name\", \"name\"]", "\"name\" is listed more than once"},
+		{"no columns", "[table]\ncolumns = []", "table.columns must list at least one"},
+		{"bad table sort", "[table]\nsort = \"colour\"", "table.sort must be one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.data)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestLoadMissingDefaultFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Expected missing default config to be ignored, got: %v", err)
+	}
+	if cfg.Timeouts.Connect != Default().Timeouts.Connect {
+		t.Error("Expected defaults when no config file exists")
+	}
+}
+
+func TestLoadMissingExplicitFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.toml"))
+	if err == nil {
+		t.Error("Expected an error for a missing --config file")
+	}
+}
+
+func TestLoadFromXDGConfigHome(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "btui"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "btui", "config.toml")
+	if err := os.WriteFile(path, []byte("[window]\nheight = 40\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Window.Height != 40 {
+		t.Errorf("Expected window height 40, got %d", cfg.Window.Height)
+	}
+}
+
+func TestLoadReportsPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.toml")
+	if err := os.WriteFile(path, []byte("[list]\nsort = \"age\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("Expected error mentioning %s, got %v", path, err)
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	t.Cleanup(func() { Set(Default()) })
+
+	cfg := Default()
+	cfg.Window.Width = 120
+	Set(cfg)
+
+	if Get().Window.Width != 120 {
+		t.Errorf("Expected Get to return the config passed to Set, got width %d", Get().Window.Width)
+	}
+}
+
+func TestKeyConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"override collides with default", "[keys]\nconnect = [\"d\"]", `"d" is bound to both connect and disconnect`},
+		{"two overrides collide", "[keys]\nscan = [\"x\"]\nrefresh = [\"x\"]", `"x" is bound to both refresh and scan`},
+		{"reserved key", "[keys]\nrefresh = [\"/\"]", `keys.refresh: "/" is reserved for filtering`},
+		{"esc outside cancel", "[keys]\nrefresh = [\"esc\"]", `keys.refresh: "esc" is reserved for clearing the filter`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.data)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestKeySwap(t *testing.T) {
+	// Swapping two defaults is not a conflict once both are rebound
+	cfg, err := Parse("[keys]\nconnect = [\"d\"]\ndisconnect = [\"c\"]")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.ScanKeys()[ActionConnect][0] != "d" {
+		t.Errorf("Expected connect bound to d, got %v", cfg.ScanKeys()[ActionConnect])
+	}
+}
+
+func TestCancelSharesEsc(t *testing.T) {
+	// Cancelling gives way to clearing the filter, so it may keep esc
+	cfg, err := Parse("[keys]\ncancel = [\"esc\", \"ctrl+x\"]")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if keys := cfg.ScanKeys()[ActionCancel]; len(keys) != 2 || keys[0] != "esc" {
+		t.Errorf("Expected cancel bound to esc and ctrl+x, got %v", keys)
+	}
+}
+
+func TestThemeSettings(t *testing.T) {
+	cfg, err := Parse(`
+[theme]
+name = "office"
+
+[theme.palettes.office]
+base = "light"
+connected = "#008800"
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Theme.Name != "office" {
+		t.Errorf("Expected theme office, got %q", cfg.Theme.Name)
+	}
+	if cfg.Theme.Palettes["office"].Connected != "#008800" {
+		t.Errorf("Expected custom palette to be decoded, got %+v", cfg.Theme.Palettes["office"])
+	}
+}
+
+func TestThemeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"unknown theme", "[theme]\nname = \"solarized\"", "theme.name must be auto"},
+		{"shadowed preset", "[theme.palettes.dark]\nconnected = \"2\"", "theme.palettes.dark shadows a built-in theme"},
+		{"bad colour", "[theme.palettes.mine]\nerror = \"red\"", "theme.palettes.mine: error must be an ANSI colour number"},
+		{"unknown colour setting", "[theme.palettes.mine]\nbackground = \"1\"", "unknown setting(s): theme.palettes.mine.background"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.data)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestHookSettings(t *testing.T) {
+	cfg, err := Parse(`
+[hooks]
+timeout = "30s"
+
+[[hooks.run]]
+event = "connect"
+device = "tag:headset"
+command = "pactl set-default-sink bluez_output"
+
+[[hooks.run]]
+event = "discover"
+command = "logger found $BTUI_NAME"
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Hooks.Timeout != 30*time.Second || cfg.Hooks.Concurrency != 2 {
+		t.Errorf("Expected a 30s timeout and the default concurrency, got %+v", cfg.Hooks)
+	}
+	if len(cfg.Hooks.Run) != 2 || cfg.Hooks.Run[0].Device != "tag:headset" || cfg.Hooks.Run[1].Event != HookDiscover {
+		t.Errorf("Unexpected hooks %+v", cfg.Hooks.Run)
+	}
+}
+
+func TestPresenceRulesResolved(t *testing.T) {
+	cfg, err := Parse(`
+[presence]
+timeout = "1m"
+
+[[presence.rule]]
+name = "phone"
+device = "tag:phone"
+
+[[presence.rule]]
+name = "car"
+device = "name:carplay"
+enter = -60
+exit = -75
+timeout = "10s"
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rules := cfg.Presence.Resolved()
+	expected := []PresenceRule{
+		{Name: "phone", Device: "tag:phone", Enter: -70, Exit: -80, Timeout: time.Minute},
+		{Name: "car", Device: "name:carplay", Enter: -60, Exit: -75, Timeout: 10 * time.Second},
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, rules)
+	}
+}
+
+func TestSceneSettings(t *testing.T) {
+	cfg, err := Parse(`
+[scenes.desk]
+concurrency = 2
+steps = [
+  { device = "Keychron K4", action = "connect" },
+  { device = "4C:87:5D:28:86:DD", action = "disconnect" },
+]
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	desk := cfg.Scenes["desk"]
+	if desk.Concurrency != 2 || len(desk.Steps) != 2 {
+		t.Fatalf("Expected the desk scene to be decoded, got %+v", desk)
+	}
+	if desk.Steps[1] != (SceneStep{Device: "4C:87:5D:28:86:DD", Action: SceneDisconnect}) {
+		t.Errorf("Expected steps in file order, got %+v", desk.Steps)
+	}
+}
+
+func TestSceneErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"no steps", "[scenes.desk]\nconcurrency = 1", "scenes.desk must have at least one step"},
+		{"bad action", "[[scenes.desk.steps]]\ndevice = \"K4\"\naction = \"pair\"", "scenes.desk step 1: action must be connect or disconnect"},
+		{"no device", "[[scenes.desk.steps]]\naction = \"connect\"", "scenes.desk step 1 needs a device"},
+		{"negative concurrency", "[scenes.desk]\nconcurrency = -1\nsteps = [{ device = \"K4\", action = \"connect\" }]", "concurrency must be 0 or more"},
+		{"unknown step setting", "[[scenes.desk.steps]]\ndevice = \"K4\"\naction = \"connect\"\ndelay = 1", "unknown setting(s): scenes.desk.steps.delay"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.data)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
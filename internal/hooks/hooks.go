@@ -0,0 +1,188 @@
+// Package hooks runs the commands configured for device events, such as
+// switching the audio sink when a headset connects
+package hooks
+
+import (
+	"btui/internal/config"
+	"btui/internal/query"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event is something that happened to a device
+type Event struct {
+	Kind   string // one of config.HookEvents
+	Device query.Device
+	// Battery and Threshold are set for battery and low_battery events
+	Battery   int
+	Threshold int
+	// Rule names the presence rule of present and absent events
+	Rule string
+}
+
+// Name returns the name the event's device is shown by
+func (e Event) Name() string {
+	switch {
+	case e.Device.Nickname != "":
+		return e.Device.Nickname
+	case e.Device.Name != "":
+		return e.Device.Name
+	default:
+		return e.Device.MAC
+	}
+}
+
+// Env describes the event in BTUI_* environment variables. BTUI_RSSI is
+// left out when the device has no signal reading.
+func (e Event) Env() []string {
+	env := []string{
+		"BTUI_EVENT=" + e.Kind,
+		"BTUI_MAC=" + e.Device.MAC,
+		"BTUI_NAME=" + e.Name(),
+	}
+	if e.Device.RSSI != 0 {
+		env = append(env, "BTUI_RSSI="+strconv.Itoa(e.Device.RSSI))
+	}
+	if e.Kind == config.HookBattery || e.Kind == config.HookLowBattery {
+		env = append(env, "BTUI_BATTERY="+strconv.Itoa(e.Battery))
+	}
+	if e.Threshold > 0 {
+		env = append(env, "BTUI_THRESHOLD="+strconv.Itoa(e.Threshold))
+	}
+	if e.Rule != "" {
+		env = append(env, "BTUI_RULE="+e.Rule)
+	}
+	return env
+}
+
+// Result is the outcome of running a hook
+type Result struct {
+	Hook   config.Hook
+	Event  Event
+	Output string
+	Err    error
+}
+
+// hook is a configured hook with its device query parsed
+type hook struct {
+	config.Hook
+	query query.Query
+}
+
+// execFunc runs a shell command with extra environment variables and returns
+// what it printed
+type execFunc func(ctx context.Context, command string, env []string) ([]byte, error)
+
+// shell runs a command through sh
+func shell(ctx context.Context, command string, env []string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(), env...)
+	return cmd.CombinedOutput()
+}
+
+// Runner matches events against the configured hooks and runs their
+// commands, each bounded by the hook timeout and no more than the hook
+// concurrency at once
+type Runner struct {
+	hooks   []hook
+	timeout time.Duration
+	slots   chan struct{}
+	exec    execFunc
+	wg      sync.WaitGroup
+}
+
+// NewRunner returns a runner for the hooks in cfg. The [battery] command is
+// run as one more low_battery hook.
+func NewRunner(cfg config.Config) *Runner {
+	hooks := append([]config.Hook(nil), cfg.Hooks.Run...)
+	if cfg.Battery.Command != "" {
+		hooks = append(hooks, config.Hook{Event: config.HookLowBattery, Command: cfg.Battery.Command})
+	}
+	r := &Runner{
+		timeout: cfg.Hooks.Timeout,
+		slots:   make(chan struct{}, max(cfg.Hooks.Concurrency, 1)),
+		exec:    shell,
+	}
+	for _, h := range hooks {
+		// Config validation has already rejected queries that do not parse
+		q, _ := query.Parse(h.Device)
+		r.hooks = append(r.hooks, hook{Hook: h, query: q})
+	}
+	return r
+}
+
+// Empty reports whether there are no hooks at all
+func (r *Runner) Empty() bool {
+	return r == nil || len(r.hooks) == 0
+}
+
+// Has reports whether any hook runs on the given kind of event
+func (r *Runner) Has(kind string) bool {
+	if r == nil {
+		return false
+	}
+	for _, h := range r.hooks {
+		if h.Event == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Matching returns the hooks that run on an event, in configuration order
+func (r *Runner) Matching(e Event) []config.Hook {
+	if r == nil {
+		return nil
+	}
+	var matching []config.Hook
+	for _, h := range r.hooks {
+		if h.Event == e.Kind && h.query.Match(e.Device) {
+			matching = append(matching, h.Hook)
+		}
+	}
+	return matching
+}
+
+// Run runs one hook's command for an event, waiting for a free slot first
+func (r *Runner) Run(h config.Hook, e Event) Result {
+	r.slots <- struct{}{}
+	defer func() { <-r.slots }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	result := Result{Hook: h, Event: e}
+	output, err := r.exec(ctx, h.Command, e.Env())
+	result.Output = string(output)
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.Err = fmt.Errorf("%s hook timed out after %s", e.Kind, r.timeout)
+	case err != nil:
+		result.Err = fmt.Errorf("%s hook: %w", e.Kind, err)
+	}
+	return result
+}
+
+// Dispatch runs every hook matching an event in the background and calls
+// done with each result
+func (r *Runner) Dispatch(e Event, done func(Result)) {
+	for _, h := range r.Matching(e) {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			done(r.Run(h, e))
+		}()
+	}
+}
+
+// Wait blocks until every dispatched hook has finished
+func (r *Runner) Wait() {
+	if r != nil {
+		r.wg.Wait()
+	}
+}
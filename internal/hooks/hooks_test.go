@@ -0,0 +1,174 @@
+package hooks
+
+import (
+	"btui/internal/config"
+	"btui/internal/query"
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var headset = query.Device{
+	Name:      "Bose NC 700",
+	Nickname:  "Headphones",
+	Tags:      []string{"headset"},
+	MAC:       "4C:87:5D:28:86:DD",
+	RSSI:      -52,
+	Connected: true,
+	Paired:    true,
+}
+
+var keyboard = query.Device{Name: "Keychron K2", MAC: "DC:2C:26:09:D0:0C", Paired: true}
+
+func testConfig(hooks ...config.Hook) config.Config {
+	cfg := config.Default()
+	cfg.Hooks.Run = hooks
+	return cfg
+}
+
+func TestMatching(t *testing.T) {
+	r := NewRunner(testConfig(
+		config.Hook{Event: config.HookConnect, Device: "tag:headset", Command: "sink"},
+		config.Hook{Event: config.HookConnect, Command: "log"},
+		config.Hook{Event: config.HookDisconnect, Device: "tag:headset", Command: "pause"},
+	))
+
+	names := func(hooks []config.Hook) string {
+		var commands []string
+		for _, h := range hooks {
+			commands = append(commands, h.Command)
+		}
+		return strings.Join(commands, ",")
+	}
+
+	tests := []struct {
+		event    Event
+		expected string
+	}{
+		{Event{Kind: config.HookConnect, Device: headset}, "sink,log"},
+		{Event{Kind: config.HookConnect, Device: keyboard}, "log"},
+		{Event{Kind: config.HookDisconnect, Device: headset}, "pause"},
+		{Event{Kind: config.HookDisconnect, Device: keyboard}, ""},
+		{Event{Kind: config.HookDiscover, Device: headset}, ""},
+	}
+	for _, tt := range tests {
+		if got := names(r.Matching(tt.event)); got != tt.expected {
+			t.Errorf("%s %s: expected hooks %q, got %q", tt.event.Kind, tt.event.Device.Name, tt.expected, got)
+		}
+	}
+
+	if !r.Has(config.HookDisconnect) || r.Has(config.HookBattery) {
+		t.Error("Expected a disconnect hook and no battery hook")
+	}
+}
+
+func TestBatteryCommandIsLowBatteryHook(t *testing.T) {
+	cfg := testConfig()
+	if !NewRunner(cfg).Empty() {
+		t.Fatal("Expected no hooks without configuration")
+	}
+	cfg.Battery.Command = "notify-send low"
+	hooks := NewRunner(cfg).Matching(Event{Kind: config.HookLowBattery, Device: keyboard, Battery: 9})
+	if len(hooks) != 1 || hooks[0].Command != "notify-send low" {
+		t.Errorf("Expected the battery command to run on low_battery, got %+v", hooks)
+	}
+}
+
+func TestRunEnvironment(t *testing.T) {
+	r := NewRunner(testConfig())
+	h := config.Hook{Event: config.HookLowBattery, Command: `echo "$BTUI_EVENT $BTUI_MAC $BTUI_NAME $BTUI_RSSI $BTUI_BATTERY $BTUI_THRESHOLD"`}
+
+	result := r.Run(h, Event{Kind: config.HookLowBattery, Device: headset, Battery: 9, Threshold: 10})
+	if result.Err != nil {
+		t.Fatalf("Unexpected error: %v", result.Err)
+	}
+	expected := "low_battery 4C:87:5D:28:86:DD Headphones -52 9 10"
+	if strings.TrimSpace(result.Output) != expected {
+		t.Errorf("Expected %q, got %q", expected, result.Output)
+	}
+
+	result = r.Run(config.Hook{Event: config.HookConnect, Command: "echo oops; exit 3"}, Event{Kind: config.HookConnect, Device: keyboard})
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "connect hook: exit status 3") {
+		t.Errorf("Expected the exit status in the error, got %v", result.Err)
+	}
+	if strings.TrimSpace(result.Output) != "oops" {
+		t.Errorf("Expected the output of a failed hook, got %q", result.Output)
+	}
+}
+
+func TestEmptyBatteryEnvironment(t *testing.T) {
+	env := strings.Join(Event{Kind: config.HookLowBattery, Device: keyboard, Battery: 0, Threshold: 10}.Env(), " ")
+	if !strings.Contains(env, "BTUI_BATTERY=0 BTUI_THRESHOLD=10") {
+		t.Errorf("Expected an empty battery in the environment, got %q", env)
+	}
+}
+
+func TestPresenceEnvironment(t *testing.T) {
+	env := strings.Join(Event{Kind: config.HookAbsent, Device: keyboard, Rule: "desk"}.Env(), " ")
+	// The keyboard has no signal reading, so BTUI_RSSI is left out
+	expected := "BTUI_EVENT=absent BTUI_MAC=DC:2C:26:09:D0:0C BTUI_NAME=Keychron K2 BTUI_RULE=desk"
+	if env != expected {
+		t.Errorf("Expected %q, got %q", expected, env)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	cfg := testConfig()
+	cfg.Hooks.Timeout = 10 * time.Millisecond
+	r := NewRunner(cfg)
+	r.exec = func(ctx context.Context, command string, env []string) ([]byte, error) {
+		<-ctx.Done()
+		return []byte("partial"), ctx.Err()
+	}
+
+	result := r.Run(config.Hook{Event: config.HookConnect, Command: "sleep 60"}, Event{Kind: config.HookConnect, Device: headset})
+	if result.Err == nil || result.Err.Error() != "connect hook timed out after 10ms" {
+		t.Errorf("Expected a timeout error, got %v", result.Err)
+	}
+	if result.Output != "partial" {
+		t.Errorf("Expected the output so far, got %q", result.Output)
+	}
+}
+
+func TestDispatchLimitsConcurrency(t *testing.T) {
+	var hooks []config.Hook
+	for range 6 {
+		hooks = append(hooks, config.Hook{Event: config.HookConnect, Command: "work"})
+	}
+	cfg := testConfig(hooks...)
+	cfg.Hooks.Concurrency = 2
+	r := NewRunner(cfg)
+
+	var running, peak atomic.Int32
+	r.exec = func(ctx context.Context, command string, env []string) ([]byte, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil, nil
+	}
+
+	var mu sync.Mutex
+	var results []Result
+	r.Dispatch(Event{Kind: config.HookConnect, Device: headset}, func(result Result) {
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, result)
+	})
+	r.Wait()
+
+	if len(results) != 6 {
+		t.Errorf("Expected 6 results, got %d", len(results))
+	}
+	if peak.Load() > 2 {
+		t.Errorf("Expected at most 2 hooks at once, got %d", peak.Load())
+	}
+}
@@ -1,6 +1,7 @@
 package menu
 
 import (
+	"btui/internal/config"
 	"btui/internal/ui"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -48,7 +49,8 @@ func NewModel() Model {
 		},
 	}
 
+	cfg := config.Get()
 	return Model{
-		List: ui.NewList(actions, "btui - Bluetooth Manager", 80, 14),
+		List: ui.NewList(actions, "btui - Bluetooth Manager", cfg.Window.Width, cfg.Window.Height),
 	}
 }
@@ -95,7 +95,7 @@ func (m Model) updateSubMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.String() == "esc" {
 			m.InSubMenu = false
 			m.SubProgram = nil
-			return m, nil
+			return m, tea.DisableMouse
 		}
 	}
 
@@ -105,9 +105,10 @@ func (m Model) updateSubMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// Check if sub-program wants to quit (return to main menu)
 	if shouldReturnToMenu(m.SubProgram) {
+		// Sub-views turn on mouse reporting for themselves; the menu doesn't use it
 		m.InSubMenu = false
 		m.SubProgram = nil
-		return m, nil
+		return m, tea.DisableMouse
 	}
 
 	return m, cmd
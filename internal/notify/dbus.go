@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// The freedesktop notification service
+const (
+	notificationsService = "org.freedesktop.Notifications"
+	notificationsPath    = "/org/freedesktop/Notifications"
+	notifyMethod         = "org.freedesktop.Notifications.Notify"
+)
+
+// callTimeout bounds how long the notification service may take to answer
+const callTimeout = 5 * time.Second
+
+// DBus sends notifications to org.freedesktop.Notifications, connecting to
+// the bus the first time one is sent
+type DBus struct {
+	connect func() (*dbus.Conn, error)
+
+	mu   sync.Mutex
+	conn *dbus.Conn
+}
+
+// NewDBus returns a notifier using an open bus connection
+func NewDBus(conn *dbus.Conn) *DBus {
+	return &DBus{conn: conn}
+}
+
+// Session returns a notifier on the session bus. Nothing is connected until
+// the first notification, so it costs nothing while notifications are off.
+func Session() *DBus {
+	return &DBus{connect: func() (*dbus.Conn, error) { return dbus.ConnectSessionBus() }}
+}
+
+// bus returns the connection, opening it if need be
+func (d *DBus) bus() (*dbus.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn != nil {
+		return d.conn, nil
+	}
+	if d.connect == nil {
+		return nil, fmt.Errorf("notifier closed")
+	}
+	conn, err := d.connect()
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach the session bus: %w", err)
+	}
+	d.conn = conn
+	return conn, nil
+}
+
+// Notify implements Notifier
+func (d *DBus) Notify(n Notification) error {
+	conn, err := d.bus()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	hints := map[string]dbus.Variant{"urgency": dbus.MakeVariant(byte(n.Urgency))}
+	// app_name, replaces_id, app_icon, summary, body, actions, hints and
+	// expire_timeout, where -1 leaves the timeout to the server
+	call := conn.Object(notificationsService, notificationsPath).CallWithContext(ctx, notifyMethod, 0,
+		"btui", uint32(0), "bluetooth", n.Summary, n.Body, []string{}, hints, int32(-1))
+	return call.Err
+}
+
+// Close closes the bus connection, if one was opened
+func (d *DBus) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn == nil {
+		return nil
+	}
+	err := d.conn.Close()
+	d.conn = nil
+	return err
+}
@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// busConfig is a bus daemon config letting anyone own names and send and
+// receive messages, listening on the socket at SOCKET
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=SOCKET</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*"/>
+    <allow receive_sender="*"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>`
+
+// privateBus starts a bus daemon of its own for one test and returns its
+// address, skipping the test when dbus-daemon is not installed
+func privateBus(t *testing.T) string {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "bus")
+	conf := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(conf, []byte(strings.Replace(busConfig, "SOCKET", socket, 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+conf, "--nofork")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	// The daemon is ready once its socket exists
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(socket); err == nil {
+			return "unix:path=" + socket
+		}
+	}
+	t.Fatal("The bus daemon did not start")
+	return ""
+}
+
+// connect opens a connection to the bus at address
+func connect(t *testing.T, address string) *dbus.Conn {
+	t.Helper()
+	conn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("Could not connect to the private bus: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// notificationService stands in for a desktop's notification daemon,
+// keeping the notifications it is sent
+type notificationService struct {
+	received chan received
+}
+
+type received struct {
+	appName, icon, summary, body string
+	urgency                      byte
+	timeout                      int32
+}
+
+// Notify implements org.freedesktop.Notifications.Notify
+func (s *notificationService) Notify(appName string, replacesID uint32, icon, summary, body string,
+	actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	urgency, _ := hints["urgency"].Value().(byte)
+	s.received <- received{appName, icon, summary, body, urgency, timeout}
+	return 1, nil
+}
+
+func TestDBusNotify(t *testing.T) {
+	address := privateBus(t)
+
+	service := &notificationService{received: make(chan received, 1)}
+	server := connect(t, address)
+	if err := server.Export(service, notificationsPath, notificationsService); err != nil {
+		t.Fatal(err)
+	}
+	if reply, err := server.RequestName(notificationsService, dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("Could not own the notification service name: %v (%v)", reply, err)
+	}
+
+	notifier := NewDBus(connect(t, address))
+	err := notifier.Notify(notification(Event{Kind: EventLowBattery, MAC: "4C:87:5D:28:86:DD", Name: "Headphones", Battery: 9}))
+	if err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	got := <-service.received
+	expected := received{"btui", "bluetooth", "Headphones battery low", "9% left", byte(UrgencyCritical), -1}
+	if got != expected {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestDBusNoService(t *testing.T) {
+	notifier := NewDBus(connect(t, privateBus(t)))
+	if err := notifier.Notify(Notification{Summary: "nobody listening"}); err == nil {
+		t.Error("Expected an error without a notification service")
+	}
+
+	notifier.Close()
+	if err := notifier.Notify(Notification{Summary: "closed"}); err == nil {
+		t.Error("Expected an error once closed")
+	}
+}
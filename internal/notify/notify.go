@@ -0,0 +1,124 @@
+// Package notify raises desktop notifications for device events through the
+// freedesktop notification service on the session bus
+package notify
+
+import (
+	"btui/internal/config"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Kinds of event that can raise a notification
+const (
+	EventConnected    = "connected"
+	EventDisconnected = "disconnected"
+	EventLowBattery   = "low_battery"
+)
+
+// Event is something that happened to a device
+type Event struct {
+	Kind string
+	MAC  string
+	Name string
+	// Battery is the charge left in percent, for low battery events
+	Battery int
+}
+
+// Urgency levels of the notification spec
+type Urgency byte
+
+const (
+	UrgencyLow Urgency = iota
+	UrgencyNormal
+	UrgencyCritical
+)
+
+// Notification is one message for the desktop
+type Notification struct {
+	Summary string
+	Body    string
+	Urgency Urgency
+}
+
+// Notifier shows desktop notifications
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// notification words an event for the desktop
+func notification(e Event) Notification {
+	name := e.Name
+	if name == "" {
+		name = e.MAC
+	}
+	switch e.Kind {
+	case EventConnected:
+		return Notification{Summary: name + " connected", Body: e.MAC, Urgency: UrgencyLow}
+	case EventDisconnected:
+		return Notification{Summary: name + " disconnected", Body: e.MAC, Urgency: UrgencyNormal}
+	case EventLowBattery:
+		return Notification{Summary: name + " battery low", Body: fmt.Sprintf("%d%% left", e.Battery), Urgency: UrgencyCritical}
+	default:
+		return Notification{Summary: name + ": " + e.Kind, Body: e.MAC, Urgency: UrgencyNormal}
+	}
+}
+
+// Dispatcher decides which events become notifications: those of enabled
+// kinds, and no more than one of a kind about a device per interval
+type Dispatcher struct {
+	notifier Notifier
+	cfg      config.Notifications
+	now      func() time.Time
+
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// NewDispatcher returns a dispatcher sending notifications through notifier
+// as cfg allows
+func NewDispatcher(notifier Notifier, cfg config.Notifications) *Dispatcher {
+	return &Dispatcher{notifier: notifier, cfg: cfg, now: time.Now, last: make(map[string]time.Time)}
+}
+
+// enabled reports whether events of a kind raise notifications
+func (d *Dispatcher) enabled(kind string) bool {
+	if !d.cfg.Enabled {
+		return false
+	}
+	switch kind {
+	case EventConnected:
+		return d.cfg.Connect
+	case EventDisconnected:
+		return d.cfg.Disconnect
+	case EventLowBattery:
+		return d.cfg.LowBattery
+	default:
+		return false
+	}
+}
+
+// Dispatch notifies the desktop of an event unless its kind is turned off or
+// the same was notified too recently. It reports whether a notification was
+// sent; a failed one still counts towards the rate limit, so an unreachable
+// service is not asked again for every event.
+func (d *Dispatcher) Dispatch(e Event) (bool, error) {
+	if !d.enabled(e.Kind) {
+		return false, nil
+	}
+
+	d.mu.Lock()
+	key := e.Kind + " " + e.MAC
+	now := d.now()
+	if last, ok := d.last[key]; ok && now.Sub(last) < d.cfg.Interval {
+		d.mu.Unlock()
+		return false, nil
+	}
+	d.last[key] = now
+	d.mu.Unlock()
+
+	if err := d.notifier.Notify(notification(e)); err != nil {
+		return false, fmt.Errorf("desktop notification: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"btui/internal/config"
+	"errors"
+	"testing"
+	"time"
+)
+
+// recorder is a notifier that keeps what it is asked to show
+type recorder struct {
+	sent []Notification
+	err  error
+}
+
+func (r *recorder) Notify(n Notification) error {
+	r.sent = append(r.sent, n)
+	return r.err
+}
+
+// enabled returns notification settings with everything on
+func enabled() config.Notifications {
+	cfg := config.Default().Notifications
+	cfg.Enabled = true
+	return cfg
+}
+
+var keyboard = Event{Kind: EventDisconnected, MAC: "DC:2C:26:09:D0:0C", Name: "Keychron K4"}
+
+func TestDispatchRateLimit(t *testing.T) {
+	r := &recorder{}
+	d := NewDispatcher(r, enabled())
+	now := time.Unix(1000, 0)
+	d.now = func() time.Time { return now }
+
+	if sent, err := d.Dispatch(keyboard); !sent || err != nil {
+		t.Fatalf("Expected the first disconnect notified, got %v, %v", sent, err)
+	}
+	// Again within the interval is quiet, but another device or kind is not
+	now = now.Add(30 * time.Second)
+	if sent, _ := d.Dispatch(keyboard); sent {
+		t.Error("Expected a repeat within the interval dropped")
+	}
+	other := keyboard
+	other.Kind = EventConnected
+	if sent, _ := d.Dispatch(other); !sent {
+		t.Error("Expected another kind of event notified")
+	}
+
+	now = now.Add(time.Minute)
+	if sent, _ := d.Dispatch(keyboard); !sent {
+		t.Error("Expected the disconnect notified again after the interval")
+	}
+	if len(r.sent) != 3 || r.sent[0].Summary != "Keychron K4 disconnected" || r.sent[0].Urgency != UrgencyNormal {
+		t.Errorf("Unexpected notifications %+v", r.sent)
+	}
+}
+
+func TestDispatchFlags(t *testing.T) {
+	r := &recorder{}
+	cfg := enabled()
+	cfg.Connect = false
+	d := NewDispatcher(r, cfg)
+
+	if sent, _ := d.Dispatch(Event{Kind: EventConnected, MAC: keyboard.MAC}); sent {
+		t.Error("Expected connects left out when turned off")
+	}
+	if sent, _ := d.Dispatch(Event{Kind: EventLowBattery, MAC: keyboard.MAC, Name: "Keychron K4", Battery: 12}); !sent {
+		t.Error("Expected low battery notified")
+	}
+	if n := r.sent[0]; n.Summary != "Keychron K4 battery low" || n.Body != "12% left" || n.Urgency != UrgencyCritical {
+		t.Errorf("Unexpected notification %+v", n)
+	}
+
+	// Nothing is sent while notifications are off altogether
+	d = NewDispatcher(r, config.Default().Notifications)
+	if sent, _ := d.Dispatch(keyboard); sent {
+		t.Error("Expected no notifications by default")
+	}
+}
+
+func TestDispatchError(t *testing.T) {
+	r := &recorder{err: errors.New("no service")}
+	d := NewDispatcher(r, enabled())
+	if sent, err := d.Dispatch(keyboard); sent || err == nil {
+		t.Fatalf("Expected the failure reported, got %v, %v", sent, err)
+	}
+	// A failed notification still counts, so the service is not pestered
+	if _, err := d.Dispatch(keyboard); err != nil || len(r.sent) != 1 {
+		t.Errorf("Expected the repeat dropped, got %v after %d tries", err, len(r.sent))
+	}
+}
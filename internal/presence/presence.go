@@ -0,0 +1,175 @@
+// Package presence decides when devices arrive and leave from the signal
+// strength reported during discovery, for "phone present / away" signals
+package presence
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/query"
+	"btui/internal/state"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Sighting is a device heard during discovery, with its signal strength in
+// Device.RSSI and when it was last heard
+type Sighting struct {
+	Device query.Device
+	At     time.Time
+}
+
+// Change is a device arriving at or leaving a rule
+type Change struct {
+	Rule    string
+	Device  query.Device // as last heard
+	Present bool
+	At      time.Time
+}
+
+// Status is where a device stands with a rule. A rule no device has matched
+// yet has one status without a device.
+type Status struct {
+	Rule    string
+	Device  query.Device
+	Present bool
+	// Since is when the device last arrived or left, zero if it never arrived
+	Since time.Time
+}
+
+// rule is a presence rule with its device query parsed
+type rule struct {
+	config.PresenceRule
+	query query.Query
+}
+
+// track is what a rule knows about one device
+type track struct {
+	device    query.Device
+	present   bool
+	since     time.Time
+	lastHeard time.Time // last sighting, weak or strong
+	// lastStrong is the last sighting at or above the exit threshold
+	lastStrong time.Time
+}
+
+// Evaluator follows the devices matching each rule. A device becomes
+// present when heard at or above the rule's enter threshold, stays present
+// while heard at or above its exit threshold, and becomes absent once it has
+// not been for the rule's timeout. It only acts on the sightings it is given
+// and the time its clock reports, so the same timeline always gives the
+// same changes.
+type Evaluator struct {
+	rules []rule
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tracks []map[string]*track // by rule, then MAC address
+}
+
+// NewEvaluator returns an evaluator for resolved rules, reading the time
+// from now
+func NewEvaluator(rules []config.PresenceRule, now func() time.Time) *Evaluator {
+	e := &Evaluator{now: now}
+	for _, r := range rules {
+		// Config validation has already rejected queries that do not parse
+		q, _ := query.Parse(r.Device)
+		e.rules = append(e.rules, rule{PresenceRule: r, query: q})
+		e.tracks = append(e.tracks, make(map[string]*track))
+	}
+	return e
+}
+
+// Observe feeds the evaluator the devices heard in a discovery snapshot and
+// returns the changes since the previous one, in rule order and then by MAC
+// address. Sightings no newer than the last one of a device, without a
+// signal reading or older than a rule's timeout are passed over, so the same
+// snapshot can be fed again.
+func (e *Evaluator) Observe(sightings []Sighting) []Change {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	sorted := append([]Sighting(nil), sightings...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Device.MAC < sorted[j].Device.MAC })
+
+	var changes []Change
+	for i, r := range e.rules {
+		tracks := e.tracks[i]
+		for _, s := range sorted {
+			if s.Device.RSSI == 0 || now.Sub(s.At) >= r.Timeout || !r.query.Match(s.Device) {
+				continue
+			}
+			t, ok := tracks[s.Device.MAC]
+			if !ok {
+				t = &track{}
+				tracks[s.Device.MAC] = t
+			}
+			if !s.At.After(t.lastHeard) {
+				continue
+			}
+			t.device = s.Device
+			t.lastHeard = s.At
+			if s.Device.RSSI >= r.Exit {
+				t.lastStrong = s.At
+			}
+			if !t.present && s.Device.RSSI >= r.Enter {
+				t.present, t.since = true, s.At
+				changes = append(changes, Change{Rule: r.Name, Device: t.device, Present: true, At: s.At})
+			}
+		}
+
+		for _, mac := range sortedKeys(tracks) {
+			t := tracks[mac]
+			if t.present && now.Sub(t.lastStrong) >= r.Timeout {
+				t.present, t.since = false, now
+				changes = append(changes, Change{Rule: r.Name, Device: t.device, Present: false, At: now})
+			}
+		}
+	}
+	return changes
+}
+
+// Status returns where every device stands with each rule, in rule order
+// and then by MAC address
+func (e *Evaluator) Status() []Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var statuses []Status
+	for i, r := range e.rules {
+		tracks := e.tracks[i]
+		if len(tracks) == 0 {
+			statuses = append(statuses, Status{Rule: r.Name})
+			continue
+		}
+		for _, mac := range sortedKeys(tracks) {
+			t := tracks[mac]
+			statuses = append(statuses, Status{Rule: r.Name, Device: t.device, Present: t.present, Since: t.since})
+		}
+	}
+	return statuses
+}
+
+// sortedKeys returns the MAC addresses a rule tracks, in order
+func sortedKeys(tracks map[string]*track) []string {
+	macs := make([]string, 0, len(tracks))
+	for mac := range tracks {
+		macs = append(macs, mac)
+	}
+	sort.Strings(macs)
+	return macs
+}
+
+// Sightings turns a discovery snapshot into sightings, describing each
+// device with the nickname, note and tags it has in store
+func Sightings(devices []bluetooth.DiscoveredDevice, store *state.Store) []Sighting {
+	sightings := make([]Sighting, len(devices))
+	for i, d := range devices {
+		sightings[i] = Sighting{
+			Device: bluetooth.QueryDevice(d.BluetoothDevice, store.Meta(d.MacAddress), "", d.RSSI),
+			At:     d.Timestamp,
+		}
+	}
+	return sightings
+}
@@ -0,0 +1,202 @@
+package presence
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/query"
+	"btui/internal/state"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock that only moves when told to
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+const phoneMAC = "AA:BB:CC:DD:EE:01"
+
+var start = time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+
+var phoneRule = config.PresenceRule{Name: "phone", Device: "tag:phone", Enter: -70, Exit: -80, Timeout: 30 * time.Second}
+
+// phone returns a sighting of the phone at a signal strength
+func phone(rssi int, at time.Time) Sighting {
+	return Sighting{Device: query.Device{Name: "Pixel", Tags: []string{"phone"}, MAC: phoneMAC, RSSI: rssi}, At: at}
+}
+
+// describe summarises changes as "rule present|absent MAC" strings
+func describe(changes []Change) string {
+	var parts []string
+	for _, c := range changes {
+		state := "absent"
+		if c.Present {
+			state = "present"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", c.Rule, state, c.Device.MAC))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func TestHysteresisTimeline(t *testing.T) {
+	clock := &fakeClock{t: start}
+	e := NewEvaluator([]config.PresenceRule{phoneRule}, clock.Now)
+
+	// One reading every 10 seconds
+	steps := []struct {
+		rssi     int
+		expected string
+	}{
+		{-85, ""},              // in range but too weak to arrive
+		{-75, ""},              // between the thresholds: still away
+		{-68, "phone present"}, // at or above enter
+		{-78, ""},              // dips below enter, still above exit
+		{-72, ""},              // back up: no second arrival
+		{-84, ""},              // below exit, but within the timeout
+		{-86, ""},              // 20s since the last strong reading
+		{-90, "phone absent"},  // 30s since the last strong reading
+		{-72, ""},              // above exit but below enter: stays away
+		{-70, "phone present"}, // exactly at enter counts
+	}
+
+	for i, step := range steps {
+		changes := e.Observe([]Sighting{phone(step.rssi, clock.Now())})
+		expected := step.expected
+		if expected != "" {
+			expected += " " + phoneMAC
+		}
+		if got := describe(changes); got != expected {
+			t.Fatalf("step %d (%d dBm at %s): expected %q, got %q", i, step.rssi, clock.Now().Sub(start), expected, got)
+		}
+		clock.Advance(10 * time.Second)
+	}
+}
+
+func TestAbsenceTimeoutWithoutSightings(t *testing.T) {
+	clock := &fakeClock{t: start}
+	e := NewEvaluator([]config.PresenceRule{phoneRule}, clock.Now)
+
+	if got := describe(e.Observe([]Sighting{phone(-60, start)})); got != "phone present "+phoneMAC {
+		t.Fatalf("Expected the phone to arrive, got %q", got)
+	}
+
+	// The scanner keeps reporting the last reading after the phone has gone
+	stale := []Sighting{phone(-60, start)}
+	clock.Advance(29 * time.Second)
+	if changes := e.Observe(stale); len(changes) != 0 {
+		t.Errorf("Expected no change within the timeout, got %q", describe(changes))
+	}
+	clock.Advance(time.Second)
+	changes := e.Observe(stale)
+	if len(changes) != 1 || changes[0].Present || !changes[0].At.Equal(start.Add(30*time.Second)) {
+		t.Fatalf("Expected the phone to leave at the timeout, got %+v", changes)
+	}
+
+	// The stale reading does not bring it back
+	clock.Advance(time.Minute)
+	if changes := e.Observe(stale); len(changes) != 0 {
+		t.Errorf("Expected a stale reading to be passed over, got %q", describe(changes))
+	}
+}
+
+func TestSightingsWithoutSignalIgnored(t *testing.T) {
+	clock := &fakeClock{t: start}
+	e := NewEvaluator([]config.PresenceRule{phoneRule}, clock.Now)
+
+	e.Observe([]Sighting{phone(-60, start)})
+	for range 3 {
+		clock.Advance(10 * time.Second)
+		// Heard, but without a signal reading: does not keep the phone present
+		e.Observe([]Sighting{phone(0, clock.Now())})
+	}
+	if statuses := e.Status(); len(statuses) != 1 || statuses[0].Present {
+		t.Errorf("Expected the phone absent, got %+v", statuses)
+	}
+}
+
+func TestRulesAndDevicesOrdered(t *testing.T) {
+	clock := &fakeClock{t: start}
+	rules := []config.PresenceRule{
+		phoneRule,
+		{Name: "watch", Device: "name:watch", Enter: -60, Exit: -75, Timeout: time.Minute},
+		{Name: "car", Device: "name:carplay", Enter: -70, Exit: -80, Timeout: time.Minute},
+	}
+	e := NewEvaluator(rules, clock.Now)
+
+	tablet := Sighting{Device: query.Device{Name: "Tablet", Tags: []string{"phone"}, MAC: "AA:BB:CC:DD:EE:00", RSSI: -50}, At: start}
+	watch := Sighting{Device: query.Device{Name: "Watch", MAC: "11:22:33:44:55:66", RSSI: -65}, At: start}
+	changes := e.Observe([]Sighting{watch, phone(-55, start), tablet})
+	expected := "phone present AA:BB:CC:DD:EE:00, phone present " + phoneMAC
+	if got := describe(changes); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	statuses := e.Status()
+	if len(statuses) != 4 {
+		t.Fatalf("Expected two phones, the watch and the car, got %+v", statuses)
+	}
+	if s := statuses[2]; s.Rule != "watch" || s.Present || s.Device.MAC != watch.Device.MAC || !s.Since.IsZero() {
+		t.Errorf("Expected the watch heard but too far to arrive, got %+v", s)
+	}
+	if s := statuses[3]; s.Rule != "car" || s.Present || s.Device.MAC != "" {
+		t.Errorf("Expected the car never heard, got %+v", s)
+	}
+	if s := statuses[1]; !s.Present || !s.Since.Equal(start) || s.Device.RSSI != -55 {
+		t.Errorf("Expected the phone present since the start, got %+v", s)
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	// The same timeline through fresh evaluators gives the same changes
+	run := func() string {
+		clock := &fakeClock{t: start}
+		e := NewEvaluator([]config.PresenceRule{phoneRule}, clock.Now)
+		var all []string
+		for i, rssi := range []int{-90, -65, -79, -81, -81, -81, -81, -69, -75} {
+			at := clock.Now()
+			other := Sighting{Device: query.Device{Tags: []string{"phone"}, MAC: "AA:BB:CC:DD:EE:02", RSSI: -100 + 5*i}, At: at}
+			all = append(all, describe(e.Observe([]Sighting{other, phone(rssi, at)})))
+			clock.Advance(15 * time.Second)
+		}
+		return strings.Join(all, " | ")
+	}
+
+	first := run()
+	for range 5 {
+		if got := run(); got != first {
+			t.Fatalf("Expected the same changes every run, got %q and %q", first, got)
+		}
+	}
+	if !strings.Contains(first, "phone absent "+phoneMAC) || !strings.Contains(first, "phone present AA:BB:CC:DD:EE:02") {
+		t.Errorf("Unexpected timeline %q", first)
+	}
+}
+
+func TestSightingsCarryTags(t *testing.T) {
+	store := state.New("")
+	store.SetMeta(phoneMAC, state.Meta{Nickname: "My phone", Tags: []string{"phone"}})
+	devices := []bluetooth.DiscoveredDevice{{
+		BluetoothDevice: bluetooth.BluetoothDevice{MacAddress: phoneMAC, Name: "Pixel"},
+		RSSI:            -60,
+		Timestamp:       start,
+	}}
+
+	sightings := Sightings(devices, store)
+	if len(sightings) != 1 || sightings[0].Device.Nickname != "My phone" || sightings[0].Device.RSSI != -60 || !sightings[0].At.Equal(start) {
+		t.Fatalf("Unexpected sightings %+v", sightings)
+	}
+	clock := &fakeClock{t: start}
+	if got := describe(NewEvaluator([]config.PresenceRule{phoneRule}, clock.Now).Observe(sightings)); got != "phone present "+phoneMAC {
+		t.Errorf("Expected the tagged phone to arrive, got %q", got)
+	}
+}
@@ -0,0 +1,306 @@
+// Package query parses and evaluates device filter queries such as
+// `status:connected rssi>-60 name:bose mac:4C:87 tag:desk`
+package query
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Fields that can be used in a query
+const (
+	FieldName   = "name"
+	FieldMAC    = "mac"
+	FieldType   = "type"
+	FieldStatus = "status"
+	FieldRSSI   = "rssi"
+	FieldTag    = "tag"
+	FieldNote   = "note"
+)
+
+// Values accepted by status:
+const (
+	StatusConnected    = "connected"
+	StatusDisconnected = "disconnected"
+	StatusPaired       = "paired"
+	StatusUnpaired     = "unpaired"
+	StatusDiscovered   = "discovered"
+)
+
+// Device is the view of a device that queries are matched against
+type Device struct {
+	Name      string
+	Nickname  string // btui-side name, if one was given
+	Note      string
+	Tags      []string
+	MAC       string
+	Type      string // bluetoothctl icon name, such as "audio-headphones"
+	RSSI      int    // signal strength in dBm, 0 when unknown
+	Connected bool
+	Paired    bool
+}
+
+// Error reports a problem with a query and where it was found
+type Error struct {
+	Pos int // byte offset of the offending term
+	Msg string
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos+1, e.Msg)
+}
+
+// term is one predicate of a query. A term without a field matches the name
+// or MAC address.
+type term struct {
+	field string
+	op    string
+	value string
+	num   int
+}
+
+// Query is a parsed filter; a device matches when every term matches
+type Query struct {
+	terms []term
+}
+
+// Parse parses a query made of space-separated terms:
+//
+//	word            name, nickname, MAC address, note or a tag contains word
+//	name:text       name or nickname contains text
+//	mac:4C:87       MAC address contains 4C:87 (separators are optional)
+//	type:audio      device type contains audio
+//	status:value    connected, disconnected, paired, unpaired or discovered
+//	rssi>-60        signal strength compared with >, >=, <, <= or =
+//	tag:desk        tagged desk
+//	note:text       note contains text
+//
+// Text is matched case-insensitively and may be double-quoted to include spaces.
+func Parse(s string) (Query, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return Query{}, err
+	}
+
+	var q Query
+	for _, tok := range tokens {
+		t, err := parseTerm(tok)
+		if err != nil {
+			return Query{}, err
+		}
+		q.terms = append(q.terms, t)
+	}
+	return q, nil
+}
+
+// token is a raw term and its offset in the query
+type token struct {
+	text    string
+	pos     int
+	quoteAt int // offset in text where quoting began, or -1
+}
+
+// tokenize splits a query on whitespace outside double quotes
+func tokenize(s string) ([]token, error) {
+	var (
+		tokens  []token
+		current strings.Builder
+		start   = -1
+		quoteAt = -1
+		inQuote bool
+		openAt  int
+	)
+	flush := func() {
+		if start >= 0 {
+			tokens = append(tokens, token{text: current.String(), pos: start, quoteAt: quoteAt})
+		}
+		current.Reset()
+		start = -1
+		quoteAt = -1
+	}
+
+	for i, r := range s {
+		switch {
+		case r == '"':
+			if start < 0 {
+				start = i
+			}
+			if quoteAt < 0 {
+				quoteAt = current.Len()
+			}
+			inQuote = !inQuote
+			openAt = i
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		default:
+			if start < 0 {
+				start = i
+			}
+			current.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, &Error{Pos: openAt, Msg: "unterminated quote"}
+	}
+	flush()
+	return tokens, nil
+}
+
+// comparisons are the operators accepted by rssi, longest first
+var comparisons = []string{">=", "<=", ">", "<", "="}
+
+// parseTerm parses a single term
+func parseTerm(tok token) (term, error) {
+	text := tok.text
+
+	// Split at the first operator character to find the field name; operators
+	// inside quotes are part of a plain search word
+	i := strings.IndexAny(text, ":<>=")
+	if i <= 0 || (tok.quoteAt >= 0 && i >= tok.quoteAt) {
+		if text == "" {
+			return term{}, &Error{Pos: tok.pos, Msg: "empty search term"}
+		}
+		return term{value: text}, nil
+	}
+	field := strings.ToLower(text[:i])
+	rest := text[i:]
+
+	switch field {
+	case FieldName, FieldMAC, FieldType, FieldStatus, FieldTag, FieldNote:
+		if rest[0] != ':' {
+			return term{}, &Error{Pos: tok.pos, Msg: fmt.Sprintf("%s only supports %s:value", field, field)}
+		}
+		value := rest[1:]
+		if value == "" {
+			return term{}, &Error{Pos: tok.pos, Msg: fmt.Sprintf("%s: needs a value", field)}
+		}
+		if field == FieldStatus {
+			value = strings.ToLower(value)
+			switch value {
+			case StatusConnected, StatusDisconnected, StatusPaired, StatusUnpaired, StatusDiscovered:
+			default:
+				return term{}, &Error{Pos: tok.pos, Msg: fmt.Sprintf(
+					"unknown status %q, expected connected, disconnected, paired, unpaired or discovered", value)}
+			}
+		}
+		return term{field: field, op: ":", value: value}, nil
+
+	case FieldRSSI:
+		for _, op := range comparisons {
+			if !strings.HasPrefix(rest, op) {
+				continue
+			}
+			value := rest[len(op):]
+			num, err := strconv.Atoi(value)
+			if err != nil {
+				return term{}, &Error{Pos: tok.pos, Msg: fmt.Sprintf("rssi needs a whole number of dBm, got %q", value)}
+			}
+			return term{field: field, op: op, value: value, num: num}, nil
+		}
+		return term{}, &Error{Pos: tok.pos, Msg: "rssi needs a comparison such as rssi>-60"}
+
+	default:
+		return term{}, &Error{Pos: tok.pos, Msg: fmt.Sprintf(
+			"unknown field %q, expected name, mac, type, status, rssi, tag or note", text[:i])}
+	}
+}
+
+// Empty reports whether the query has no terms and so matches everything
+func (q Query) Empty() bool {
+	return len(q.terms) == 0
+}
+
+// Match reports whether a device satisfies every term of the query
+func (q Query) Match(d Device) bool {
+	for _, t := range q.terms {
+		if !t.match(d) {
+			return false
+		}
+	}
+	return true
+}
+
+// match reports whether a device satisfies the term
+func (t term) match(d Device) bool {
+	switch t.field {
+	case "":
+		return contains(d.Name, t.value) || contains(d.Nickname, t.value) || containsMAC(d.MAC, t.value) ||
+			contains(d.Note, t.value) || slices.ContainsFunc(d.Tags, func(tag string) bool { return contains(tag, t.value) })
+	case FieldName:
+		return contains(d.Name, t.value) || contains(d.Nickname, t.value)
+	case FieldTag:
+		return slices.ContainsFunc(d.Tags, func(tag string) bool { return strings.EqualFold(tag, t.value) })
+	case FieldNote:
+		return contains(d.Note, t.value)
+	case FieldMAC:
+		return containsMAC(d.MAC, t.value)
+	case FieldType:
+		return contains(d.Type, t.value)
+	case FieldStatus:
+		switch t.value {
+		case StatusConnected:
+			return d.Connected
+		case StatusDisconnected:
+			return !d.Connected
+		case StatusPaired:
+			return d.Paired
+		default: // unpaired, discovered
+			return !d.Paired
+		}
+	case FieldRSSI:
+		if d.RSSI == 0 {
+			// Unknown signal strength never satisfies a comparison
+			return false
+		}
+		switch t.op {
+		case ">":
+			return d.RSSI > t.num
+		case ">=":
+			return d.RSSI >= t.num
+		case "<":
+			return d.RSSI < t.num
+		case "<=":
+			return d.RSSI <= t.num
+		default:
+			return d.RSSI == t.num
+		}
+	}
+	return false
+}
+
+// contains reports whether s contains substr, ignoring case
+func contains(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// containsMAC reports whether a MAC address contains a fragment, ignoring case
+// and the ":" or "-" separators
+func containsMAC(mac, fragment string) bool {
+	strip := strings.NewReplacer(":", "", "-", "")
+	fragment = strip.Replace(fragment)
+	return fragment != "" && contains(strip.Replace(mac), fragment)
+}
+
+// String returns the query in canonical form
+func (q Query) String() string {
+	parts := make([]string, len(q.terms))
+	for i, t := range q.terms {
+		parts[i] = t.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// String returns the term in canonical form
+func (t term) String() string {
+	value := t.value
+	if strings.ContainsAny(value, " \t\n") || (t.field == "" && strings.ContainsAny(value, ":<>=")) {
+		value = `"` + value + `"`
+	}
+	if t.field == "" {
+		return value
+	}
+	return t.field + t.op + value
+}
@@ -0,0 +1,210 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var devices = map[string]Device{
+	"headphones": {Name: "Bose NC 700 Headphones", MAC: "4C:87:5D:28:86:DD", Type: "audio-headphones", RSSI: -55, Paired: true},
+	"keyboard":   {Name: "Keychron K4", MAC: "DC:2C:26:09:D0:0C", Type: "input-keyboard", Connected: true, Paired: true},
+	"tv":         {Name: "Living Room TV", MAC: "F0:99:B6:12:34:56", Type: "video-display", RSSI: -72},
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"headphones", "keyboard", "tv"}},
+		{"bose", []string{"headphones"}},
+		{"BOSE", []string{"headphones"}},
+		{"name:key", []string{"keyboard"}},
+		{`name:"living room"`, []string{"tv"}},
+		{`"room tv"`, []string{"tv"}},
+		{"mac:4C:87", []string{"headphones"}},
+		{"mac:4c87", []string{"headphones"}},
+		{"mac:d0-0c", []string{"keyboard"}},
+		{"dc2c", []string{"keyboard"}},
+		{"type:audio", []string{"headphones"}},
+		{"status:connected", []string{"keyboard"}},
+		{"status:disconnected", []string{"headphones", "tv"}},
+		{"status:paired", []string{"headphones", "keyboard"}},
+		{"status:discovered", []string{"tv"}},
+		{"status:Unpaired", []string{"tv"}},
+		{"rssi>-60", []string{"headphones"}},
+		{"rssi>=-72", []string{"headphones", "tv"}},
+		{"rssi<-60", []string{"tv"}},
+		{"rssi<=-55", []string{"headphones", "tv"}},
+		{"rssi=-72", []string{"tv"}},
+		{"status:paired rssi>-60 name:bose mac:4C:87", []string{"headphones"}},
+		{"status:connected rssi>-90", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var matched []string
+			for _, name := range []string{"headphones", "keyboard", "tv"} {
+				if q.Match(devices[name]) {
+					matched = append(matched, name)
+				}
+			}
+			if strings.Join(matched, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, matched)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+		pos      int
+	}{
+		{"colour:red", `unknown field "colour"`, 0},
+		{"bose status:asleep", `unknown status "asleep"`, 5},
+		{"name:", "name: needs a value", 0},
+		{"name>3", "name only supports name:value", 0},
+		{"rssi:-60", "rssi needs a comparison", 0},
+		{"rssi>strong", `rssi needs a whole number of dBm, got "strong"`, 0},
+		{`name:"living room`, "unterminated quote", 5},
+		{`""`, "empty search term", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %q", tt.expected, err.Error())
+			}
+			var qerr *Error
+			if !errors.As(err, &qerr) || qerr.Pos != tt.pos {
+				t.Errorf("Expected error at %d, got %v", tt.pos, err)
+			}
+		})
+	}
+}
+
+func TestMatchMetadata(t *testing.T) {
+	d := Device{
+		Name:     "LE-Bose QC35 II",
+		Nickname: "Alex's headphones",
+		Note:     "Spare ear pads in the drawer",
+		Tags:     []string{"desk", "meeting-room-3"},
+		MAC:      "4C:87:5D:28:86:DD",
+	}
+
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"alex", true},
+		{"name:alex", true},
+		{"name:qc35", true},
+		{"drawer", true},
+		{"meeting", true},
+		{"tag:desk", true},
+		{"tag:DESK", true},
+		{"tag:meeting", false},
+		{"note:pads", true},
+		{"note:desk", false},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tt.query, err)
+		}
+		if got := q.Match(d); got != tt.expected {
+			t.Errorf("%q matched = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestQuotedOperatorsAreText(t *testing.T) {
+	q, err := Parse(`"name:bose"`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if q.Match(devices["headphones"]) {
+		t.Error("Expected a quoted term to be searched for literally")
+	}
+	if !q.Match(Device{Name: "name:bose speaker"}) {
+		t.Error("Expected a quoted term to match the literal text")
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"  STATUS:Connected   Name:bose  ", "status:connected name:bose"},
+		{`name:"living room" rssi>=-70`, `name:"living room" rssi>=-70`},
+		{`"a:b"`, `"a:b"`},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", tt.query, err)
+		}
+		if q.String() != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, q.String())
+		}
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	q, _ := Parse("   ")
+	if !q.Empty() {
+		t.Error("Expected a blank query to be empty")
+	}
+	q, _ = Parse("bose")
+	if q.Empty() {
+		t.Error("Expected a query with terms not to be empty")
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"status:connected rssi>-60 name:bose mac:4C:87",
+		`name:"living room" type:audio`,
+		`"quoted:text" rssi<=-70 rssi=0`,
+		"rssi>", `name:"`, "::", "=-1",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		q, err := Parse(s)
+		if err != nil {
+			var qerr *Error
+			if !errors.As(err, &qerr) || qerr.Pos < 0 || qerr.Pos > len(s) {
+				t.Fatalf("Parse(%q) returned a bad error: %v", s, err)
+			}
+			return
+		}
+
+		// The canonical form parses back to the same query
+		canonical := q.String()
+		again, err := Parse(canonical)
+		if err != nil {
+			t.Fatalf("Parse(%q) of canonical form of %q failed: %v", canonical, s, err)
+		}
+		if again.String() != canonical {
+			t.Fatalf("Canonical form not stable: %q -> %q", canonical, again.String())
+		}
+		q.Match(Device{Name: s, Nickname: s, Note: s, Tags: []string{s}, MAC: s, Type: s, RSSI: -50})
+	})
+}
@@ -0,0 +1,246 @@
+// Package scene applies scenes: named groups of devices that are connected
+// or disconnected together, defined in the config file or saved from the TUI
+package scene
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/state"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Scene is a scene with its name and where it is defined
+type Scene struct {
+	config.Scene
+	Name string
+	// Saved is set for scenes saved from the TUI, which can be deleted there;
+	// config scenes are edited in the config file
+	Saved bool
+}
+
+// List returns every scene sorted by name. A config scene hides a saved one
+// of the same name.
+func List() []Scene {
+	var scenes []Scene
+	configured := config.Get().Scenes
+	for name, s := range configured {
+		scenes = append(scenes, Scene{Scene: s, Name: name})
+	}
+	for name, s := range state.Get().Scenes() {
+		if _, ok := configured[name]; !ok {
+			scenes = append(scenes, Scene{Scene: s, Name: name, Saved: true})
+		}
+	}
+	sort.Slice(scenes, func(i, j int) bool { return scenes[i].Name < scenes[j].Name })
+	return scenes
+}
+
+// Find returns the scene with the given name
+func Find(name string) (Scene, error) {
+	for _, s := range List() {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return Scene{}, fmt.Errorf("no scene named %q", name)
+}
+
+// Status is how far a step has got
+type Status int
+
+const (
+	Pending Status = iota
+	Running
+	Succeeded
+	Skipped
+	Failed
+)
+
+// String returns a string representation of the status
+func (s Status) String() string {
+	switch s {
+	case Pending:
+		return "pending"
+	case Running:
+		return "running"
+	case Succeeded:
+		return "done"
+	case Skipped:
+		return "skipped"
+	case Failed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// Progress reports a change in one step of a running scene
+type Progress struct {
+	Index  int
+	Step   config.SceneStep
+	Device bluetooth.BluetoothDevice
+	Status Status
+	// Output is the failure reason, or why the step was skipped
+	Output string
+}
+
+// Name returns the device name for display, or the step's device as written
+// when it could not be found
+func (p Progress) Name() string {
+	if p.Device.MacAddress == "" {
+		return p.Step.Device
+	}
+	return state.Get().DisplayName(p.Device.MacAddress, p.Device.Name)
+}
+
+// Executor carries out one step on a device, reporting whether it worked and
+// the command output
+type Executor func(action string, device bluetooth.BluetoothDevice) (bool, string)
+
+// Execute runs a step with bluetoothctl right away, retrying a failed
+// connect as configured. Scene actions are named like the operations of the
+// same kind.
+func Execute(action string, device bluetooth.BluetoothDevice) (bool, string) {
+	ok, output, _ := bluetooth.RunWithRetry(context.Background(), config.Get().Retry, bluetooth.RunOperation, action, device, nil)
+	return ok, output
+}
+
+// Apply starts the steps of a scene in order against the known devices, with
+// at most the scene's concurrency running at once. Steps whose device is
+// already in the wanted state are skipped. Every change is sent on the
+// returned channel, which is closed once all steps have finished.
+func Apply(s config.Scene, devices []bluetooth.BluetoothDevice, execute Executor) <-chan Progress {
+	// Each step reports at most twice, so sends never block
+	updates := make(chan Progress, 2*len(s.Steps))
+	limit := make(chan struct{}, max(1, s.Concurrency))
+
+	go func() {
+		var wg sync.WaitGroup
+		for i, step := range s.Steps {
+			p := Progress{Index: i, Step: step}
+			device, err := bluetooth.MatchDevice(devices, step.Device)
+			if err != nil {
+				p.Status, p.Output = Failed, err.Error()
+				updates <- p
+				continue
+			}
+			p.Device = device
+			if device.Connected == (step.Action == config.SceneConnect) {
+				p.Status, p.Output = Skipped, "already "+pastTense(step.Action)
+				updates <- p
+				continue
+			}
+
+			limit <- struct{}{}
+			p.Status = Running
+			updates <- p
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-limit }()
+				ok, output := execute(step.Action, device)
+				p.Status, p.Output = Succeeded, ""
+				if !ok {
+					p.Status, p.Output = Failed, output
+				}
+				updates <- p
+			}()
+		}
+		wg.Wait()
+		close(updates)
+	}()
+	return updates
+}
+
+// pastTense returns "connected" or "disconnected"
+func pastTense(action string) string {
+	return action + "ed"
+}
+
+// ProgressMsg is sent when a step of a scene run changes
+type ProgressMsg struct {
+	Run      *Run
+	Progress Progress
+}
+
+// DoneMsg is sent when every step of a scene run has finished
+type DoneMsg struct {
+	Run *Run
+}
+
+// Run follows a scene being applied in a Bubble Tea program
+type Run struct {
+	Scene   Scene
+	Steps   []Progress
+	updates <-chan Progress
+}
+
+// Start applies a scene and returns the run to follow it with Next
+func Start(s Scene, devices []bluetooth.BluetoothDevice, execute Executor) *Run {
+	r := &Run{Scene: s, Steps: make([]Progress, len(s.Steps))}
+	for i, step := range s.Steps {
+		r.Steps[i] = Progress{Index: i, Step: step}
+	}
+	r.updates = Apply(s.Scene, devices, execute)
+	return r
+}
+
+// Next returns a command waiting for the next change in the run
+func (r *Run) Next() tea.Cmd {
+	return func() tea.Msg {
+		p, ok := <-r.updates
+		if !ok {
+			return DoneMsg{Run: r}
+		}
+		return ProgressMsg{Run: r, Progress: p}
+	}
+}
+
+// Record stores a change so the run reflects the latest state of each step
+func (r *Run) Record(p Progress) {
+	if p.Index >= 0 && p.Index < len(r.Steps) {
+		r.Steps[p.Index] = p
+	}
+}
+
+// Finished counts the steps that are no longer pending or running
+func (r *Run) Finished() int {
+	n := 0
+	for _, p := range r.Steps {
+		if p.Status > Running {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary describes the outcome of a set of steps, naming those that failed
+func Summary(name string, steps []Progress) string {
+	counts := make(map[Status]int)
+	var failures []string
+	for _, p := range steps {
+		counts[p.Status]++
+		if p.Status == Failed {
+			failures = append(failures, p.Name()+": "+p.Output)
+		}
+	}
+
+	parts := []string{fmt.Sprintf("%d done", counts[Succeeded])}
+	if counts[Skipped] > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", counts[Skipped]))
+	}
+	if counts[Failed] > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", counts[Failed]))
+	}
+	summary := fmt.Sprintf("Scene %s: %s", name, strings.Join(parts, ", "))
+	if len(failures) > 0 {
+		summary += " (" + strings.Join(failures, "; ") + ")"
+	}
+	return summary
+}
@@ -0,0 +1,150 @@
+package scene
+
+import (
+	"btui/internal/bluetooth"
+	"btui/internal/config"
+	"btui/internal/state"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+var devices = []bluetooth.BluetoothDevice{
+	{MacAddress: "4C:87:5D:28:86:DD", Name: "Bose NC 700 Headphones", Paired: true},
+	{MacAddress: "DC:2C:26:09:D0:0C", Name: "Keychron K4", Paired: true, Connected: true},
+	{MacAddress: "F0:99:B6:12:34:56", Name: "MX Master 3", Paired: true},
+}
+
+// collect drains a run's updates, keeping the last report of each step
+func collect(updates <-chan Progress, steps int) []Progress {
+	results := make([]Progress, steps)
+	for p := range updates {
+		results[p.Index] = p
+	}
+	return results
+}
+
+func TestApplyRunsStepsInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	execute := func(action string, device bluetooth.BluetoothDevice) (bool, string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, action+" "+device.Name)
+		return device.Name != "MX Master 3", "Failed to connect: org.bluez.Error.Failed"
+	}
+
+	desk := config.Scene{Steps: []config.SceneStep{
+		{Device: "MX Master 3", Action: config.SceneConnect},
+		{Device: "keychron k4", Action: config.SceneConnect},
+		{Device: "4C:87:5D:28:86:DD", Action: config.SceneConnect},
+		{Device: "Toaster", Action: config.SceneDisconnect},
+	}}
+	results := collect(Apply(desk, devices, execute), len(desk.Steps))
+
+	if got := strings.Join(order, ", "); got != "connect MX Master 3, connect Bose NC 700 Headphones" {
+		t.Errorf("Expected steps to run in order, skipping the connected keyboard, got %q", got)
+	}
+	expected := []Status{Failed, Skipped, Succeeded, Failed}
+	for i, status := range expected {
+		if results[i].Status != status {
+			t.Errorf("Step %d: expected %s, got %s (%s)", i+1, status, results[i].Status, results[i].Output)
+		}
+	}
+	if results[1].Output != "already connected" || !strings.Contains(results[3].Output, "no device named") {
+		t.Errorf("Expected reasons for skipped and unknown devices, got %+v", results)
+	}
+}
+
+func TestApplyBoundsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	started, release := make(chan struct{}, 3), make(chan struct{})
+	execute := func(string, bluetooth.BluetoothDevice) (bool, string) {
+		started <- struct{}{}
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		return true, ""
+	}
+
+	all := config.Scene{Concurrency: 2, Steps: []config.SceneStep{
+		{Device: "Bose NC 700 Headphones", Action: config.SceneConnect},
+		{Device: "Keychron K4", Action: config.SceneDisconnect},
+		{Device: "MX Master 3", Action: config.SceneConnect},
+	}}
+	updates := Apply(all, devices, execute)
+
+	// Two steps start, the third waits for a free slot
+	<-started
+	<-started
+	close(release)
+	results := collect(updates, len(all.Steps))
+
+	if peak.Load() != 2 {
+		t.Errorf("Expected at most 2 steps at once, peak was %d", peak.Load())
+	}
+	for i, p := range results {
+		if p.Status != Succeeded {
+			t.Errorf("Step %d: expected done, got %s", i+1, p.Status)
+		}
+	}
+}
+
+func TestRunFollowsProgress(t *testing.T) {
+	s := Scene{Name: "desk", Scene: config.Scene{Steps: []config.SceneStep{
+		{Device: "Bose NC 700 Headphones", Action: config.SceneConnect},
+	}}}
+	r := Start(s, devices, func(string, bluetooth.BluetoothDevice) (bool, string) { return true, "" })
+
+	for {
+		msg := r.Next()()
+		if _, ok := msg.(DoneMsg); ok {
+			break
+		}
+		r.Record(msg.(ProgressMsg).Progress)
+	}
+	if r.Finished() != 1 || r.Steps[0].Status != Succeeded {
+		t.Errorf("Expected the step to be recorded as done, got %+v", r.Steps)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	steps := []Progress{
+		{Device: devices[0], Status: Succeeded},
+		{Device: devices[1], Status: Skipped},
+		{Device: devices[2], Status: Failed, Output: "Failed to connect: br-connection-page-timeout"},
+	}
+	expected := "Scene desk: 1 done, 1 skipped, 1 failed (MX Master 3: Failed to connect: br-connection-page-timeout)"
+	if got := Summary("desk", steps); got != expected {
+		t.Errorf("Summary() = %q, expected %q", got, expected)
+	}
+}
+
+func TestListPrefersConfigScenes(t *testing.T) {
+	cfg := config.Default()
+	step := []config.SceneStep{{Device: "Keychron K4", Action: config.SceneConnect}}
+	cfg.Scenes = map[string]config.Scene{"desk": {Steps: step}}
+	config.Set(cfg)
+	t.Cleanup(func() { config.Set(config.Default()) })
+
+	store := state.New("")
+	store.SaveScene("desk", config.Scene{Steps: step})
+	store.SaveScene("couch", config.Scene{Steps: step})
+	state.Set(store)
+	t.Cleanup(func() { state.Set(state.New("")) })
+
+	scenes := List()
+	if len(scenes) != 2 || scenes[0].Name != "couch" || !scenes[0].Saved || scenes[1].Saved {
+		t.Errorf("Expected the saved couch scene and the config desk scene, got %+v", scenes)
+	}
+	if _, err := Find("gym"); err == nil {
+		t.Error("Expected an unknown scene to be an error")
+	}
+}
@@ -0,0 +1,119 @@
+package state
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Kinds of ignore rule
+const (
+	IgnoreMAC          = "mac"          // one device by address
+	IgnoreName         = "name"         // names matching a glob pattern such as "LG*"
+	IgnoreManufacturer = "manufacturer" // a Bluetooth SIG company identifier such as 0x004C
+)
+
+// IgnoreRule hides matching devices from discovery
+type IgnoreRule struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Target is what ignore rules are matched against. Manufacturer is the
+// company identifier in 0x%04X form, or empty while it is unknown.
+type Target struct {
+	MAC          string
+	Name         string
+	Manufacturer string
+}
+
+// NewIgnoreRule validates a rule and puts its value in canonical form
+func NewIgnoreRule(kind, value string) (IgnoreRule, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return IgnoreRule{}, fmt.Errorf("%s rule needs a value", kind)
+	}
+	switch kind {
+	case IgnoreMAC:
+		value = normalizeMAC(value)
+	case IgnoreName:
+		if _, err := path.Match(strings.ToLower(value), ""); err != nil {
+			return IgnoreRule{}, fmt.Errorf("invalid name pattern %q: %w", value, err)
+		}
+	case IgnoreManufacturer:
+		var id uint16
+		if _, err := fmt.Sscanf(strings.ToLower(value), "0x%x", &id); err != nil {
+			return IgnoreRule{}, fmt.Errorf("manufacturer must be a company identifier such as 0x004C, got %q", value)
+		}
+		value = fmt.Sprintf("0x%04X", id)
+	default:
+		return IgnoreRule{}, fmt.Errorf("unknown rule kind %q, expected mac, name or manufacturer", kind)
+	}
+	return IgnoreRule{Kind: kind, Value: value}, nil
+}
+
+// Match reports whether the rule hides a device. Name patterns use shell glob
+// syntax and ignore case.
+func (r IgnoreRule) Match(t Target) bool {
+	switch r.Kind {
+	case IgnoreMAC:
+		return normalizeMAC(t.MAC) == r.Value
+	case IgnoreName:
+		ok, _ := path.Match(strings.ToLower(r.Value), strings.ToLower(t.Name))
+		return ok && t.Name != ""
+	case IgnoreManufacturer:
+		return t.Manufacturer != "" && strings.EqualFold(t.Manufacturer, r.Value)
+	}
+	return false
+}
+
+// String describes the rule, such as `name "LG*"`
+func (r IgnoreRule) String() string {
+	if r.Kind == IgnoreName {
+		return fmt.Sprintf("%s %q", r.Kind, r.Value)
+	}
+	return r.Kind + " " + r.Value
+}
+
+// IgnoreRules returns the ignore rules in the order they were added
+func (s *Store) IgnoreRules() []IgnoreRule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.data.Ignore)
+}
+
+// AddIgnore adds a rule and reports whether it was new
+func (s *Store) AddIgnore(rule IgnoreRule) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if slices.Contains(s.data.Ignore, rule) {
+		return false
+	}
+	s.data.Ignore = append(s.data.Ignore, rule)
+	return true
+}
+
+// RemoveIgnore deletes a rule and reports whether it existed
+func (s *Store) RemoveIgnore(rule IgnoreRule) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.Index(s.data.Ignore, rule)
+	if i < 0 {
+		return false
+	}
+	s.data.Ignore = slices.Delete(s.data.Ignore, i, i+1)
+	return true
+}
+
+// Ignored returns the first rule that hides a device
+func (s *Store) Ignored(t Target) (IgnoreRule, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rule := range s.data.Ignore {
+		if rule.Match(t) {
+			return rule, true
+		}
+	}
+	return IgnoreRule{}, false
+}
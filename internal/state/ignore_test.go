@@ -0,0 +1,112 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewIgnoreRule(t *testing.T) {
+	tests := []struct {
+		kind, value string
+		expected    IgnoreRule
+		wantErr     bool
+	}{
+		{IgnoreMAC, "aa:bb:cc:dd:ee:ff", IgnoreRule{IgnoreMAC, "AA:BB:CC:DD:EE:FF"}, false},
+		{IgnoreName, " LG* ", IgnoreRule{IgnoreName, "LG*"}, false},
+		{IgnoreManufacturer, "0x4c", IgnoreRule{IgnoreManufacturer, "0x004C"}, false},
+		{IgnoreName, "[", IgnoreRule{}, true},
+		{IgnoreManufacturer, "Apple", IgnoreRule{}, true},
+		{IgnoreMAC, "", IgnoreRule{}, true},
+		{"colour", "red", IgnoreRule{}, true},
+	}
+
+	for _, tt := range tests {
+		rule, err := NewIgnoreRule(tt.kind, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewIgnoreRule(%q, %q) error = %v, wantErr %v", tt.kind, tt.value, err, tt.wantErr)
+			continue
+		}
+		if rule != tt.expected {
+			t.Errorf("NewIgnoreRule(%q, %q) = %+v, expected %+v", tt.kind, tt.value, rule, tt.expected)
+		}
+	}
+}
+
+func TestIgnoreRuleMatch(t *testing.T) {
+	tv := Target{MAC: "F0:99:B6:12:34:56", Name: "[LG] webOS TV", Manufacturer: "0x00C4"}
+
+	tests := []struct {
+		rule     IgnoreRule
+		expected bool
+	}{
+		{IgnoreRule{IgnoreMAC, "F0:99:B6:12:34:56"}, true},
+		{IgnoreRule{IgnoreMAC, "AA:BB:CC:DD:EE:FF"}, false},
+		{IgnoreRule{IgnoreName, `\[lg\]*`}, true},
+		{IgnoreRule{IgnoreName, "*tv"}, true},
+		{IgnoreRule{IgnoreName, "tv"}, false},
+		{IgnoreRule{IgnoreManufacturer, "0x00C4"}, true},
+		{IgnoreRule{IgnoreManufacturer, "0x004C"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rule.Match(tv); got != tt.expected {
+			t.Errorf("%s matched = %v, expected %v", tt.rule, got, tt.expected)
+		}
+	}
+
+	// Devices with an unknown manufacturer never match a manufacturer rule
+	if (IgnoreRule{IgnoreManufacturer, "0x00C4"}).Match(Target{MAC: tv.MAC}) {
+		t.Error("Expected no match without a known manufacturer")
+	}
+}
+
+func TestAddRemoveIgnore(t *testing.T) {
+	s := New("")
+	rule := IgnoreRule{IgnoreName, "LG*"}
+
+	if !s.AddIgnore(rule) || s.AddIgnore(rule) {
+		t.Error("Expected only the first add to be new")
+	}
+	if got, ok := s.Ignored(Target{Name: "LG TV"}); !ok || got != rule {
+		t.Errorf("Expected the rule to hide the TV, got %+v", got)
+	}
+	if !s.RemoveIgnore(rule) || s.RemoveIgnore(rule) {
+		t.Error("Expected only the first remove to succeed")
+	}
+	if _, ok := s.Ignored(Target{Name: "LG TV"}); ok {
+		t.Error("Expected the TV to be shown again")
+	}
+}
+
+func TestIgnoreRulesSaved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s := New(path)
+	s.AddIgnore(IgnoreRule{IgnoreMAC, "AA:BB:CC:DD:EE:FF"})
+	s.AddIgnore(IgnoreRule{IgnoreManufacturer, "0x004C"})
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if rules := loaded.IgnoreRules(); len(rules) != 2 || rules[1].Value != "0x004C" {
+		t.Errorf("Expected both rules in order, got %+v", rules)
+	}
+}
+
+func TestLoadInvalidIgnoreRule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	data := `{"version": 1, "ignore": [{"kind": "name", "value": "LG*"}, {"kind": "colour", "value": "red"}]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "ignore rule 2") {
+		t.Errorf("Expected an error naming the second rule, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// macAddress matches a normalized MAC address
+var macAddress = regexp.MustCompile(`^([0-9A-F]{2}:){5}[0-9A-F]{2}$`)
+
+// Meta is what btui remembers about a device beyond what Bluetooth reports
+type Meta struct {
+	Nickname string   `json:"nickname,omitempty"`
+	Note     string   `json:"note,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// Empty reports whether nothing is recorded
+func (m Meta) Empty() bool {
+	return m.Nickname == "" && m.Note == "" && len(m.Tags) == 0
+}
+
+// normalize trims every field and leaves tags lower-case, sorted and unique
+func (m Meta) normalize() Meta {
+	m.Nickname = strings.TrimSpace(m.Nickname)
+	m.Note = strings.TrimSpace(m.Note)
+	var tags []string
+	for _, tag := range m.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	m.Tags = tags
+	return m
+}
+
+// ParseTags splits a comma or space separated list of tags
+func ParseTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// Meta returns what is recorded about a device
+func (s *Store) Meta(mac string) Meta {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	meta := s.data.Devices[normalizeMAC(mac)]
+	meta.Tags = slices.Clone(meta.Tags)
+	return meta
+}
+
+// SetMeta records a device's nickname, note and tags, forgetting the device
+// when all of them are empty
+func (s *Store) SetMeta(mac string, meta Meta) {
+	mac = normalizeMAC(mac)
+	meta = meta.normalize()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if meta.Empty() {
+		delete(s.data.Devices, mac)
+		return
+	}
+	if s.data.Devices == nil {
+		s.data.Devices = make(map[string]Meta)
+	}
+	s.data.Devices[mac] = meta
+}
+
+// DisplayName returns the nickname of a device, or name when it has none
+func (s *Store) DisplayName(mac, name string) string {
+	if nickname := s.Meta(mac).Nickname; nickname != "" {
+		return nickname
+	}
+	return name
+}
+
+// Catalogue is the file format for sharing device metadata
+type Catalogue struct {
+	Version int             `json:"version"`
+	Devices map[string]Meta `json:"devices"`
+}
+
+// Export writes the metadata of every device as a JSON catalogue
+func (s *Store) Export(w io.Writer) error {
+	s.mu.Lock()
+	catalogue := Catalogue{Version: version, Devices: maps.Clone(s.data.Devices)}
+	s.mu.Unlock()
+	if catalogue.Devices == nil {
+		catalogue.Devices = map[string]Meta{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(catalogue)
+}
+
+// Import reads a JSON catalogue and records its devices, replacing what is
+// known about them. With replace set, devices missing from the catalogue are
+// forgotten. Every address is checked before anything changes, so a
+// catalogue with a bad one leaves the store as it was. Entries with nothing
+// recorded are passed over. It returns the number of devices imported.
+func (s *Store) Import(r io.Reader, replace bool) (int, error) {
+	var catalogue Catalogue
+	if err := json.NewDecoder(r).Decode(&catalogue); err != nil {
+		return 0, fmt.Errorf("invalid catalogue: %w", err)
+	}
+	if catalogue.Version > version {
+		return 0, fmt.Errorf("catalogue version %d is newer than this btui supports (%d)", catalogue.Version, version)
+	}
+
+	devices := make(map[string]Meta, len(catalogue.Devices))
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(catalogue.Devices)) {
+		mac := normalizeMAC(key)
+		if !macAddress.MatchString(mac) {
+			errs = append(errs, fmt.Errorf("device %q: not a MAC address", key))
+			continue
+		}
+		if _, ok := devices[mac]; ok {
+			errs = append(errs, fmt.Errorf("device %s: listed more than once", mac))
+			continue
+		}
+		devices[mac] = catalogue.Devices[key].normalize()
+	}
+	if len(errs) > 0 {
+		return 0, fmt.Errorf("invalid catalogue: %w", errors.Join(errs...))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if replace || s.data.Devices == nil {
+		s.data.Devices = make(map[string]Meta, len(devices))
+	}
+	imported := 0
+	for mac, meta := range devices {
+		if meta.Empty() {
+			continue
+		}
+		s.data.Devices[mac] = meta
+		imported++
+	}
+	return imported, nil
+}
@@ -0,0 +1,130 @@
+package state
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetMeta(t *testing.T) {
+	s := New("")
+	s.SetMeta("4c:87:5d:28:86:dd", Meta{
+		Nickname: "  Alex's headphones ",
+		Tags:     []string{"Desk", "meeting-room-3", "desk", " "},
+	})
+
+	meta := s.Meta("4C:87:5D:28:86:DD")
+	if meta.Nickname != "Alex's headphones" {
+		t.Errorf("Expected a trimmed nickname, got %q", meta.Nickname)
+	}
+	if strings.Join(meta.Tags, ",") != "desk,meeting-room-3" {
+		t.Errorf("Expected sorted unique lower-case tags, got %v", meta.Tags)
+	}
+	if got := s.DisplayName("4C:87:5D:28:86:DD", "LE-Bose QC35 II"); got != "Alex's headphones" {
+		t.Errorf("Expected the nickname as display name, got %q", got)
+	}
+	if got := s.DisplayName("AA:BB:CC:DD:EE:FF", "Speaker"); got != "Speaker" {
+		t.Errorf("Expected the Bluetooth name without a nickname, got %q", got)
+	}
+
+	// Clearing every field forgets the device
+	s.SetMeta("4C:87:5D:28:86:DD", Meta{Tags: []string{" "}})
+	if s.Meta("4C:87:5D:28:86:DD").Nickname != "" || len(s.data.Devices) != 0 {
+		t.Errorf("Expected the device to be forgotten, got %+v", s.data.Devices)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	if got := strings.Join(ParseTags("desk, meeting-room-3  office"), "|"); got != "desk|meeting-room-3|office" {
+		t.Errorf("Unexpected tags %q", got)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	s := New("")
+	s.SetMeta("4C:87:5D:28:86:DD", Meta{Nickname: "Headphones", Tags: []string{"desk"}})
+
+	var buf bytes.Buffer
+	if err := s.Export(&buf); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	other := New("")
+	other.SetMeta("AA:BB:CC:DD:EE:FF", Meta{Note: "kept when merging"})
+	n, err := other.Import(bytes.NewReader(buf.Bytes()), false)
+	if err != nil || n != 1 {
+		t.Fatalf("Import = %d, %v", n, err)
+	}
+	if other.Meta("4C:87:5D:28:86:DD").Nickname != "Headphones" || other.Meta("AA:BB:CC:DD:EE:FF").Note == "" {
+		t.Error("Expected the catalogue merged into existing metadata")
+	}
+
+	if _, err := other.Import(bytes.NewReader(buf.Bytes()), true); err != nil {
+		t.Fatal(err)
+	}
+	if other.Meta("AA:BB:CC:DD:EE:FF").Note != "" {
+		t.Error("Expected replace to forget devices missing from the catalogue")
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	s := New("")
+	if _, err := s.Import(strings.NewReader("{"), false); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+	if _, err := s.Import(strings.NewReader(`{"version": 99, "devices": {}}`), false); err == nil {
+		t.Error("Expected an error for a newer catalogue version")
+	}
+}
+
+func TestImportValidatesAddresses(t *testing.T) {
+	s := New("")
+	s.SetMeta("AA:BB:CC:DD:EE:FF", Meta{Note: "kept"})
+
+	// A bad address fails the whole import, even when replacing
+	catalogue := `{"version": 1, "devices": {"4C:87:5D:28:86:DD": {"nickname": "Headphones"}, "headphones": {"note": "x"}}}`
+	if _, err := s.Import(strings.NewReader(catalogue), true); err == nil || !strings.Contains(err.Error(), `device "headphones": not a MAC address`) {
+		t.Errorf("Expected the bad address reported, got %v", err)
+	}
+	if s.Meta("AA:BB:CC:DD:EE:FF").Note != "kept" || s.Meta("4C:87:5D:28:86:DD").Nickname != "" {
+		t.Error("Expected the store left as it was")
+	}
+
+	// The same device twice, in different cases, is ambiguous
+	catalogue = `{"version": 1, "devices": {"4C:87:5D:28:86:DD": {"nickname": "A"}, "4c:87:5d:28:86:dd": {"nickname": "B"}}}`
+	if _, err := s.Import(strings.NewReader(catalogue), false); err == nil {
+		t.Error("Expected an error for a device listed twice")
+	}
+
+	// Lower-case addresses are normalized, and empty entries are not counted
+	catalogue = `{"version": 1, "devices": {"4c:87:5d:28:86:dd": {"nickname": " Headphones ", "tags": ["Desk"]}, "11:22:33:44:55:66": {"note": " "}}}`
+	n, err := s.Import(strings.NewReader(catalogue), false)
+	if err != nil || n != 1 {
+		t.Fatalf("Import = %d, %v", n, err)
+	}
+	if meta := s.Meta("4C:87:5D:28:86:DD"); meta.Nickname != "Headphones" || meta.Tags[0] != "desk" {
+		t.Errorf("Expected a normalized entry, got %+v", meta)
+	}
+	if _, ok := s.data.Devices["11:22:33:44:55:66"]; ok {
+		t.Error("Expected the empty entry passed over")
+	}
+}
+
+func TestLoadNormalizesDevices(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	data := `{"version": 1, "devices": {"4c:87:5d:28:86:dd": {"nickname": "Headphones", "tags": ["Desk"]}}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	meta := s.Meta("4C:87:5D:28:86:DD")
+	if meta.Nickname != "Headphones" || meta.Tags[0] != "desk" {
+		t.Errorf("Expected a normalized entry, got %+v", meta)
+	}
+}
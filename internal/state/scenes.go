@@ -0,0 +1,42 @@
+package state
+
+import (
+	"btui/internal/config"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Scenes returns the scenes saved from the TUI, keyed by name
+func (s *Store) Scenes() map[string]config.Scene {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.data.Scenes)
+}
+
+// SaveScene stores a scene under name, replacing any scene of that name
+func (s *Store) SaveScene(name string, scene config.Scene) error {
+	name = strings.TrimSpace(name)
+	if err := config.ValidateScene(name, scene); err != nil {
+		return err
+	}
+	scene.Steps = slices.Clone(scene.Steps)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Scenes == nil {
+		s.data.Scenes = make(map[string]config.Scene)
+	}
+	s.data.Scenes[name] = scene
+	return nil
+}
+
+// DeleteScene removes a saved scene and reports whether it existed
+func (s *Store) DeleteScene(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data.Scenes[name]; !ok {
+		return false
+	}
+	delete(s.data.Scenes, name)
+	return true
+}
@@ -0,0 +1,54 @@
+package state
+
+import (
+	"btui/internal/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveScene(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s := New(path)
+	desk := config.Scene{Steps: []config.SceneStep{
+		{Device: "DC:2C:26:09:D0:0C", Action: config.SceneConnect},
+		{Device: "4C:87:5D:28:86:DD", Action: config.SceneConnect},
+	}}
+
+	if err := s.SaveScene(" desk ", desk); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := s.SaveScene("empty", config.Scene{}); err == nil {
+		t.Error("Expected a scene without steps to be refused")
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	scenes := loaded.Scenes()
+	if len(scenes) != 1 || len(scenes["desk"].Steps) != 2 || scenes["desk"].Steps[1].Device != "4C:87:5D:28:86:DD" {
+		t.Errorf("Expected the desk scene to round-trip, got %+v", scenes)
+	}
+
+	if !loaded.DeleteScene("desk") || loaded.DeleteScene("desk") {
+		t.Error("Expected the scene to be deleted once")
+	}
+}
+
+func TestLoadInvalidScene(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	data := `{"version": 1, "scenes": {"desk": {"steps": [{"device": "K4", "action": "pair"}]}}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "scene desk step 1") {
+		t.Errorf("Expected the bad step to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+// Package state persists what btui remembers about devices between runs,
+// such as favorites, ignored devices, nicknames and scenes, in a JSON file under XDG_STATE_HOME
+package state
+
+import (
+	"btui/internal/config"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// version is written to the state file so later formats can migrate it
+const version = 1
+
+// Data is the content of the state file
+type Data struct {
+	Version   int                     `json:"version"`
+	Favorites []string                `json:"favorites,omitempty"`
+	Ignore    []IgnoreRule            `json:"ignore,omitempty"`
+	Devices   map[string]Meta         `json:"devices,omitempty"`
+	Scenes    map[string]config.Scene `json:"scenes,omitempty"`
+}
+
+// Store holds the state in memory and writes it back to its file. It is safe
+// for concurrent use, so saves can run in the background.
+type Store struct {
+	mu   sync.Mutex
+	path string
+	data Data
+}
+
+// New returns an empty store that saves to path; an empty path keeps the
+// state in memory only
+func New(path string) *Store {
+	return &Store{path: path, data: Data{Version: version}}
+}
+
+// DefaultPath returns the state file location under XDG_STATE_HOME
+func DefaultPath() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot determine state directory: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "btui", "state.json"), nil
+}
+
+// Load reads the state file at path, or the default location when path is
+// empty. A missing file yields an empty store that will create it on save.
+func Load(path string) (*Store, error) {
+	if path == "" {
+		p, err := DefaultPath()
+		if err != nil {
+			return New(""), nil
+		}
+		path = p
+	}
+
+	s := New(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("read state %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &s.data); err != nil {
+		return nil, fmt.Errorf("state %s: %w", path, err)
+	}
+	// Rules may have been edited by hand, so check them like new ones
+	for i, rule := range s.data.Ignore {
+		canonical, err := NewIgnoreRule(rule.Kind, rule.Value)
+		if err != nil {
+			return nil, fmt.Errorf("state %s: ignore rule %d: %w", path, i+1, err)
+		}
+		s.data.Ignore[i] = canonical
+	}
+	devices := s.data.Devices
+	s.data.Devices = nil
+	for mac, meta := range devices {
+		s.SetMeta(mac, meta)
+	}
+	for name, scene := range s.data.Scenes {
+		if err := config.ValidateScene(name, scene); err != nil {
+			return nil, fmt.Errorf("state %s: scene %w", path, err)
+		}
+	}
+	s.data.Version = version
+	return s, nil
+}
+
+// Path returns the file the store saves to
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Save writes the state to its file, replacing it atomically
+func (s *Store) Save() error {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if s.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".state-*.json")
+	if err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("save state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+	return nil
+}
+
+// normalizeMAC puts a MAC address in the upper-case form bluetoothctl prints
+func normalizeMAC(mac string) string {
+	return strings.ToUpper(strings.TrimSpace(mac))
+}
+
+// IsFavorite reports whether a device is starred
+func (s *Store) IsFavorite(mac string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Contains(s.data.Favorites, normalizeMAC(mac))
+}
+
+// Favorites returns the MAC addresses of starred devices
+func (s *Store) Favorites() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.data.Favorites)
+}
+
+// SetFavorite stars or unstars a device
+func (s *Store) SetFavorite(mac string, on bool) {
+	mac = normalizeMAC(mac)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.Index(s.data.Favorites, mac)
+	switch {
+	case on && i < 0:
+		s.data.Favorites = append(s.data.Favorites, mac)
+		slices.Sort(s.data.Favorites)
+	case !on && i >= 0:
+		s.data.Favorites = slices.Delete(s.data.Favorites, i, i+1)
+	}
+}
+
+// ToggleFavorite flips whether a device is starred and returns the new setting
+func (s *Store) ToggleFavorite(mac string) bool {
+	on := !s.IsFavorite(mac)
+	s.SetFavorite(mac, on)
+	return on
+}
+
+var (
+	current  atomic.Pointer[Store]
+	inMemory = New("")
+)
+
+// Get returns the active store. Until Set is called this is an in-memory
+// store that is never written to disk.
+func Get() *Store {
+	if s := current.Load(); s != nil {
+		return s
+	}
+	return inMemory
+}
+
+// Set replaces the active store
+func Set(s *Store) {
+	current.Store(s)
+}
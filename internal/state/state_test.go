@@ -0,0 +1,126 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/xdg-state")
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if path != "/tmp/xdg-state/btui/state.json" {
+		t.Errorf("Expected path under XDG_STATE_HOME, got %q", path)
+	}
+
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("HOME", "/home/tester")
+	path, _ = DefaultPath()
+	if path != "/home/tester/.local/state/btui/state.json" {
+		t.Errorf("Expected fallback under ~/.local/state, got %q", path)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "btui", "state.json")
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s.Path() != path || len(s.Favorites()) != 0 {
+		t.Errorf("Expected an empty store for %s", path)
+	}
+}
+
+func TestLoadFromXDGStateHome(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+
+	s, err := Load("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s.Path() != filepath.Join(dir, "btui", "state.json") {
+		t.Errorf("Expected default path, got %q", s.Path())
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "btui", "state.json")
+	s := New(path)
+	s.SetFavorite("dc:2c:26:09:d0:0c", true)
+	s.SetFavorite("4C:87:5D:28:86:DD", true)
+
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	favorites := strings.Join(loaded.Favorites(), ",")
+	if favorites != "4C:87:5D:28:86:DD,DC:2C:26:09:D0:0C" {
+		t.Errorf("Expected sorted upper-case favorites, got %s", favorites)
+	}
+
+	// No temporary files are left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected only the state file, got %d entries", len(entries))
+	}
+}
+
+func TestLoadCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("Expected an error naming %s, got %v", path, err)
+	}
+}
+
+func TestToggleFavorite(t *testing.T) {
+	s := New("")
+
+	if !s.ToggleFavorite("aa:bb:cc:dd:ee:ff") {
+		t.Error("Expected first toggle to star the device")
+	}
+	if !s.IsFavorite("AA:BB:CC:DD:EE:FF") {
+		t.Error("Expected favorites to ignore MAC case")
+	}
+	if s.ToggleFavorite("AA:BB:CC:DD:EE:FF") {
+		t.Error("Expected second toggle to unstar the device")
+	}
+	if len(s.Favorites()) != 0 {
+		t.Errorf("Expected no favorites, got %v", s.Favorites())
+	}
+}
+
+func TestInMemorySave(t *testing.T) {
+	s := New("")
+	s.SetFavorite("AA:BB:CC:DD:EE:FF", true)
+	if err := s.Save(); err != nil {
+		t.Errorf("Expected saving an in-memory store to succeed, got %v", err)
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	t.Cleanup(func() { current.Store(nil) })
+
+	if Get() != inMemory {
+		t.Error("Expected the in-memory store before Set")
+	}
+	s := New("")
+	Set(s)
+	if Get() != s {
+		t.Error("Expected Get to return the store passed to Set")
+	}
+}
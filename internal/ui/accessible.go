@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/paginator"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// accessible reports whether plain mode is active: no alt screen, no colour,
+// ASCII markers and status changes printed as lines
+var accessible bool
+
+// SetAccessible switches plain accessible mode on or off. Turning it on also
+// applies the monochrome palette.
+func SetAccessible(on bool) {
+	accessible = on
+	if on {
+		ApplyTheme(Presets[ThemeMonochrome])
+	}
+}
+
+// Accessible reports whether plain accessible mode is active
+func Accessible() bool {
+	return accessible
+}
+
+// AccessibleFromEnv reports whether the environment asks for plain output,
+// either through NO_COLOR (https://no-color.org) or a dumb terminal
+func AccessibleFromEnv() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return true
+	}
+	return os.Getenv("TERM") == "dumb"
+}
+
+// ProgramOptions returns the Bubble Tea options for the current mode. The
+// alt screen is skipped in accessible mode so output stays in the scrollback
+// where screen readers can reach it.
+func ProgramOptions() []tea.ProgramOption {
+	if accessible {
+		return nil
+	}
+	return []tea.ProgramOption{tea.WithAltScreen()}
+}
+
+// Separator returns the string placed between parts of a description
+func Separator() string {
+	if accessible {
+		return ", "
+	}
+	return " • "
+}
+
+// JoinDescription joins description parts with the mode's separator
+func JoinDescription(parts ...string) string {
+	return strings.Join(parts, Separator())
+}
+
+// ConnectedMarker returns the prefix marking a connected device
+func ConnectedMarker() string {
+	if accessible {
+		return "[connected] "
+	}
+	return "🔗 "
+}
+
+// FavoriteMarker returns the prefix marking a favorite device
+func FavoriteMarker() string {
+	if accessible {
+		return "[favorite] "
+	}
+	return "★ "
+}
+
+// MarkedMarker returns the prefix marking a device picked for a scene
+func MarkedMarker() string {
+	if accessible {
+		return "[marked] "
+	}
+	return "● "
+}
+
+// SuccessMarker returns the prefix for a successful result
+func SuccessMarker() string {
+	if accessible {
+		return "OK:"
+	}
+	return "✓"
+}
+
+// FailureMarker returns the prefix for a failed result
+func FailureMarker() string {
+	if accessible {
+		return "FAILED:"
+	}
+	return "✗"
+}
+
+// applyAccessibleList swaps a list's decorative glyphs for plain text
+func applyAccessibleList(l *list.Model) {
+	if !accessible {
+		return
+	}
+	l.Paginator.Type = paginator.Arabic
+	l.Help.ShortSeparator = " | "
+	l.Help.FullSeparator = "   "
+	l.Help.Ellipsis = "..."
+}
+
+// applyAccessibleDelegate marks the selected row with ">" instead of a bar
+func applyAccessibleDelegate(d *list.DefaultDelegate) {
+	if !accessible {
+		return
+	}
+	marker := lipgloss.Border{Left: ">"}
+	d.Styles.SelectedTitle = d.Styles.SelectedTitle.BorderStyle(marker)
+	d.Styles.SelectedDesc = d.Styles.SelectedDesc.BorderStyle(lipgloss.Border{Left: " "})
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"testing"
+)
+
+func TestAccessibleFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		noColor  string
+		term     string
+		expected bool
+	}{
+		{"regular terminal", "", "xterm-256color", false},
+		{"NO_COLOR set", "1", "xterm-256color", true},
+		{"dumb terminal", "", "dumb", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			t.Setenv("TERM", tt.term)
+			if got := AccessibleFromEnv(); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAccessibleMarkers(t *testing.T) {
+	t.Cleanup(func() {
+		SetAccessible(false)
+		ApplyTheme(Presets[ThemeDark])
+	})
+
+	SetAccessible(false)
+	if ConnectedMarker() != "🔗 " || Separator() != " • " {
+		t.Error("Expected decorative markers outside accessible mode")
+	}
+	if len(ProgramOptions()) != 1 {
+		t.Error("Expected the alt screen option outside accessible mode")
+	}
+
+	SetAccessible(true)
+	if !Accessible() {
+		t.Fatal("Expected accessible mode to be on")
+	}
+	if ConnectedMarker() != "[connected] " {
+		t.Errorf("Expected ASCII connected marker, got %q", ConnectedMarker())
+	}
+	if SuccessMarker() != "OK:" || FailureMarker() != "FAILED:" {
+		t.Errorf("Expected textual result markers, got %q and %q", SuccessMarker(), FailureMarker())
+	}
+	if FavoriteMarker() != "[favorite] " {
+		t.Errorf("Expected textual favorite marker, got %q", FavoriteMarker())
+	}
+	if MarkedMarker() != "[marked] " {
+		t.Errorf("Expected textual marked marker, got %q", MarkedMarker())
+	}
+	if got := JoinDescription("Connected", "AA:BB:CC:DD:EE:FF"); got != "Connected, AA:BB:CC:DD:EE:FF" {
+		t.Errorf("Expected comma-separated description, got %q", got)
+	}
+	if len(ProgramOptions()) != 0 {
+		t.Error("Expected no alt screen in accessible mode")
+	}
+	if ActivePalette() != Presets[ThemeMonochrome] {
+		t.Error("Expected accessible mode to use the monochrome palette")
+	}
+}
+
+func TestAccessibleList(t *testing.T) {
+	t.Cleanup(func() {
+		SetAccessible(false)
+		ApplyTheme(Presets[ThemeDark])
+	})
+	SetAccessible(true)
+
+	l := NewList(nil, "Devices", 80, 20)
+	if l.Help.ShortSeparator != " | " {
+		t.Errorf("Expected ASCII help separator, got %q", l.Help.ShortSeparator)
+	}
+}
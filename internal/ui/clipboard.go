@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"encoding/base64"
+	"io"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// clipboardOutput receives OSC 52 sequences, which the terminal turns into
+// clipboard contents. Going through the terminal works over SSH too.
+var clipboardOutput io.Writer = os.Stdout
+
+// ClipboardMsg is sent once text has been handed to the terminal's clipboard
+type ClipboardMsg struct {
+	Err error
+}
+
+// osc52 returns the escape sequence that sets the clipboard to text. Inside
+// tmux it is wrapped so tmux passes it on to the outer terminal.
+func osc52(text string) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	return seq
+}
+
+// CopyCmd returns a command that puts text on the clipboard with OSC 52
+func CopyCmd(text string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := io.WriteString(clipboardOutput, osc52(text))
+		return ClipboardMsg{Err: err}
+	}
+}
@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCopyCmd(t *testing.T) {
+	t.Setenv("TMUX", "")
+	var out bytes.Buffer
+	clipboardOutput = &out
+	t.Cleanup(func() { clipboardOutput = os.Stdout })
+
+	if msg := CopyCmd("details")().(ClipboardMsg); msg.Err != nil {
+		t.Fatal(msg.Err)
+	}
+	expected := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("details")) + "\a"
+	if out.String() != expected {
+		t.Errorf("Expected an OSC 52 sequence, got %q", out.String())
+	}
+
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	if seq := osc52("details"); !strings.HasPrefix(seq, "\x1bPtmux;\x1b\x1b]52;") {
+		t.Errorf("Expected the sequence passed through tmux, got %q", seq)
+	}
+}
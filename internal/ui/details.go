@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// ErrorDetails describes a failed operation in full, for the error details
+// modal: what was tried, how it ended and what to do about it
+type ErrorDetails struct {
+	Operation  string
+	Device     string
+	Duration   time.Duration
+	ExitStatus string
+	// Cause is the classified error and Fix the advice for it, if any
+	Cause  string
+	Fix    string
+	Output string
+}
+
+// ErrorDetailsKeys are the bindings of the error details modal
+var ErrorDetailsKeys = struct {
+	Copy  key.Binding
+	Close key.Binding
+}{
+	Copy:  key.NewBinding(key.WithKeys("c", "y"), key.WithHelp("c", "copy details")),
+	Close: key.NewBinding(key.WithKeys("esc", "enter", "q"), key.WithHelp("esc", "close")),
+}
+
+// fields returns the labelled lines of the details, leaving out those
+// that are unknown
+func (d ErrorDetails) fields() [][2]string {
+	fields := [][2]string{{"Operation", d.Operation}, {"Device", d.Device}}
+	if d.Duration > 0 {
+		fields = append(fields, [2]string{"Duration", d.Duration.Round(100 * time.Millisecond).String()})
+	}
+	if d.ExitStatus != "" {
+		fields = append(fields, [2]string{"Exit status", d.ExitStatus})
+	}
+	if d.Cause != "" {
+		fields = append(fields, [2]string{"Cause", d.Cause})
+	}
+	if d.Fix != "" {
+		fields = append(fields, [2]string{"Fix", d.Fix})
+	}
+	return fields
+}
+
+// output returns the raw output, or a note that there was none
+func (d ErrorDetails) output() string {
+	if output := strings.TrimSpace(d.Output); output != "" {
+		return output
+	}
+	return "(no output)"
+}
+
+// Text returns the details as plain text, for copying
+func (d ErrorDetails) Text() string {
+	var b strings.Builder
+	for _, field := range d.fields() {
+		fmt.Fprintf(&b, "%s: %s\n", field[0], field[1])
+	}
+	b.WriteString("Output:\n" + d.output() + "\n")
+	return b.String()
+}
+
+// View renders the details as a framed modal of the given width, ending
+// with the keys to copy and close it
+func (d ErrorDetails) View(width int) string {
+	var b strings.Builder
+	b.WriteString(ErrorStyle().Render(FailureMarker()+" "+d.Operation+" failed") + "\n\n")
+	for _, field := range d.fields() {
+		label := fmt.Sprintf("%-13s", field[0]+":")
+		b.WriteString(DetailLabelStyle.Render(label) + field[1] + "\n")
+	}
+	b.WriteString("\n" + DetailLabelStyle.Render("Output:") + "\n" + d.output() + "\n\n")
+	copyHelp, closeHelp := ErrorDetailsKeys.Copy.Help(), ErrorDetailsKeys.Close.Help()
+	b.WriteString(PaginationStyle.Render(JoinDescription(copyHelp.Key+" "+copyHelp.Desc, closeHelp.Key+" "+closeHelp.Desc)))
+
+	style := ModalStyle
+	// The width set on a style covers its padding but not its border
+	if inner := width - style.GetHorizontalBorderSize(); inner > 0 {
+		style = style.Width(inner)
+	}
+	return style.Render(b.String())
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var pageTimeoutDetails = ErrorDetails{
+	Operation:  "connect",
+	Device:     "Bose NC 700 Headphones (4C:87:5D:28:86:DD)",
+	Duration:   5120 * time.Millisecond,
+	ExitStatus: "1",
+	Cause:      "page timeout",
+	Fix:        "the device did not answer",
+	Output:     "Attempting to connect to 4C:87:5D:28:86:DD\nFailed to connect: org.bluez.Error.Failed br-connection-page-timeout",
+}
+
+func TestErrorDetailsText(t *testing.T) {
+	expected := "Operation: connect\n" +
+		"Device: Bose NC 700 Headphones (4C:87:5D:28:86:DD)\n" +
+		"Duration: 5.1s\n" +
+		"Exit status: 1\n" +
+		"Cause: page timeout\n" +
+		"Fix: the device did not answer\n" +
+		"Output:\n" + pageTimeoutDetails.Output + "\n"
+	if got := pageTimeoutDetails.Text(); got != expected {
+		t.Errorf("Unexpected text:\n%s", got)
+	}
+
+	// Unknown fields are left out
+	text := ErrorDetails{Operation: "trust", Device: "Keychron K4"}.Text()
+	if strings.Contains(text, "Duration") || strings.Contains(text, "Cause") || !strings.Contains(text, "(no output)") {
+		t.Errorf("Unexpected text:\n%s", text)
+	}
+}
+
+func TestErrorDetailsView(t *testing.T) {
+	view := pageTimeoutDetails.View(70)
+	for _, expected := range []string{"connect failed", "Exit status", "org.bluez.Error.Failed", "copy details"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected the modal to show %q", expected)
+		}
+	}
+	if width := lipgloss.Width(view); width != 70 {
+		t.Errorf("Expected the modal 70 columns wide, got %d", width)
+	}
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Detail pane sizing: a third of the window, within these bounds
+const (
+	detailMinWidth = 30
+	detailMaxWidth = 50
+	paneGap        = 2
+)
+
+// Pane is the area one component renders into
+type Pane struct {
+	Width  int
+	Height int
+}
+
+// Layout divides the window into a main pane and, on wide terminals, a
+// detail pane to its right
+type Layout struct {
+	Main   Pane
+	Detail Pane
+	Split  bool
+}
+
+// NewLayout computes the panes for a window. Rows reserved by the caller
+// (such as a status line) are taken from the main pane. The detail pane is
+// shown once the window is at least splitWidth columns wide; 0 disables it.
+func NewLayout(width, height, splitWidth, reserved int) Layout {
+	innerWidth := max(width-AppStyle.GetHorizontalFrameSize(), 0)
+	innerHeight := max(height-AppStyle.GetVerticalFrameSize(), 0)
+
+	layout := Layout{
+		Main: Pane{Width: innerWidth, Height: max(innerHeight-reserved, 0)},
+	}
+	if splitWidth == 0 || width < splitWidth {
+		return layout
+	}
+
+	detailWidth := min(max(innerWidth/3, detailMinWidth), detailMaxWidth)
+	layout.Split = true
+	layout.Detail = Pane{Width: detailWidth, Height: innerHeight}
+	layout.Main.Width = innerWidth - detailWidth - paneGap
+	return layout
+}
+
+// Join places the main and detail pane views side by side when split
+func (l Layout) Join(main, detail string) string {
+	if !l.Split {
+		return main
+	}
+	main = lipgloss.NewStyle().Width(l.Main.Width).Render(main)
+	gap := strings.Repeat(" ", paneGap)
+	return lipgloss.JoinHorizontal(lipgloss.Top, main, gap, detail)
+}
+
+// RenderDetail frames detail pane content to the pane size
+func (l Layout) RenderDetail(content string) string {
+	// Width covers padding but not the border
+	border := DetailPaneStyle.GetHorizontalBorderSize()
+	return DetailPaneStyle.
+		Width(max(l.Detail.Width-border, 0)).
+		MaxHeight(l.Detail.Height).
+		Render(content)
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewLayoutSingleColumn(t *testing.T) {
+	layout := NewLayout(80, 24, 100, 1)
+
+	if layout.Split {
+		t.Error("Expected a single column below the split width")
+	}
+	// AppStyle pads 2 columns on each side and 1 row top and bottom
+	if layout.Main.Width != 76 || layout.Main.Height != 21 {
+		t.Errorf("Expected 76x21 main pane, got %dx%d", layout.Main.Width, layout.Main.Height)
+	}
+}
+
+func TestNewLayoutSplit(t *testing.T) {
+	layout := NewLayout(120, 30, 100, 1)
+
+	if !layout.Split {
+		t.Fatal("Expected two panes at or above the split width")
+	}
+	if layout.Detail.Width != 38 || layout.Detail.Height != 28 {
+		t.Errorf("Expected 38x28 detail pane, got %dx%d", layout.Detail.Width, layout.Detail.Height)
+	}
+	if got := layout.Main.Width + paneGap + layout.Detail.Width; got != 116 {
+		t.Errorf("Expected panes to fill the 116 inner columns, got %d", got)
+	}
+	if layout.Main.Height != 27 {
+		t.Errorf("Expected main pane height 27, got %d", layout.Main.Height)
+	}
+}
+
+func TestNewLayoutDetailWidthBounds(t *testing.T) {
+	if w := NewLayout(80, 30, 80, 0).Detail.Width; w != detailMinWidth {
+		t.Errorf("Expected minimum detail width %d, got %d", detailMinWidth, w)
+	}
+	if w := NewLayout(300, 30, 100, 0).Detail.Width; w != detailMaxWidth {
+		t.Errorf("Expected maximum detail width %d, got %d", detailMaxWidth, w)
+	}
+}
+
+func TestNewLayoutSplitDisabled(t *testing.T) {
+	if NewLayout(300, 30, 0, 1).Split {
+		t.Error("Expected split width 0 to disable the detail pane")
+	}
+}
+
+func TestNewLayoutTinyWindow(t *testing.T) {
+	layout := NewLayout(2, 1, 100, 1)
+	if layout.Main.Width != 0 || layout.Main.Height != 0 {
+		t.Errorf("Expected an empty main pane, got %dx%d", layout.Main.Width, layout.Main.Height)
+	}
+}
+
+func TestLayoutJoin(t *testing.T) {
+	single := NewLayout(80, 24, 100, 1)
+	if got := single.Join("main", "detail"); got != "main" {
+		t.Errorf("Expected only the main view, got %q", got)
+	}
+
+	split := NewLayout(120, 24, 100, 1)
+	joined := split.Join("main", split.RenderDetail("detail"))
+	if !strings.Contains(joined, "main") || !strings.Contains(joined, "detail") {
+		t.Errorf("Expected both panes, got %q", joined)
+	}
+}
@@ -4,7 +4,6 @@ package ui
 import (
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
-	"github.com/charmbracelet/lipgloss"
 )
 
 // DeviceItem represents a Bluetooth device that can be displayed in a list
@@ -17,7 +16,7 @@ type DeviceItem struct {
 // Title implements list.Item
 func (i DeviceItem) Title() string { return i.title }
 
-// Description implements list.Item  
+// Description implements list.Item
 func (i DeviceItem) Description() string { return i.description }
 
 // FilterValue implements list.Item
@@ -47,25 +46,52 @@ func (i GenericItem) FilterValue() string {
 	return i.Title + " " + i.Description
 }
 
-// newDeviceDelegate creates an extended default delegate with status-specific colors
+// newDeviceDelegate creates an extended default delegate with selection colours from the active palette
 func newDeviceDelegate() list.DefaultDelegate {
 	d := list.NewDefaultDelegate()
-	
-	// Customize selection colors to use terminal colors that fit our scheme
+	p := activePalette
+
 	d.Styles.SelectedTitle = d.Styles.SelectedTitle.
-		Foreground(lipgloss.Color("15")).
-    BorderLeftForeground(lipgloss.Color("2")).
+		Foreground(color(p.SelectedFg)).
+		BorderLeftForeground(color(p.SelectedBorder)).
 		Bold(true).
-    Italic(true)
-	
+		Italic(true)
+
 	d.Styles.SelectedDesc = d.Styles.SelectedDesc.
-    BorderLeftForeground(lipgloss.Color("2")).
-		Foreground(lipgloss.Color("7")) // Terminal white
+		BorderLeftForeground(color(p.SelectedBorder)).
+		Foreground(color(p.SelectedDesc))
+
+	// The delegate's built-in greys give way to the palette; a palette
+	// without these colours leaves the terminal's own
+	d.Styles.NormalTitle = d.Styles.NormalTitle.Foreground(color(p.Text))
+	d.Styles.NormalDesc = d.Styles.NormalDesc.Foreground(color(p.Muted))
+	d.Styles.DimmedTitle = d.Styles.DimmedTitle.Foreground(color(p.Muted))
+	d.Styles.DimmedDesc = d.Styles.DimmedDesc.Foreground(color(p.Muted))
+	if p.Text == "" {
+		d.Styles.FilterMatch = d.Styles.FilterMatch.Underline(true)
+	}
+	applyAccessibleDelegate(&d)
 
-	
 	return d
 }
 
+// applyListTheme colours the list's help, pagination dots, filter prompt and
+// empty message from the active palette
+func applyListTheme(l *list.Model) {
+	p := activePalette
+	l.Help.Styles = HelpStyles()
+	l.Styles.ActivePaginationDot = l.Styles.ActivePaginationDot.Foreground(color(p.Text))
+	l.Styles.InactivePaginationDot = l.Styles.InactivePaginationDot.Foreground(color(p.Muted))
+	l.Styles.DividerDot = l.Styles.DividerDot.Foreground(color(p.Muted))
+	l.Styles.NoItems = l.Styles.NoItems.Foreground(color(p.Muted))
+	l.Styles.StatusBar = l.Styles.StatusBar.Foreground(color(p.Muted))
+	l.Styles.StatusEmpty = l.Styles.StatusEmpty.Foreground(color(p.Muted))
+	l.Styles.StatusBarActiveFilter = l.Styles.StatusBarActiveFilter.Foreground(color(p.Text))
+	l.Styles.StatusBarFilterCount = l.Styles.StatusBarFilterCount.Foreground(color(p.Muted))
+	l.Styles.FilterPrompt = l.Styles.FilterPrompt.Foreground(color(p.Success))
+	l.Styles.FilterCursor = l.Styles.FilterCursor.Foreground(color(p.Text))
+}
+
 // NewList creates a new list with colored status indicators
 func NewList(items []list.Item, title string, width, height int) list.Model {
 	delegate := newDeviceDelegate()
@@ -76,6 +102,8 @@ func NewList(items []list.Item, title string, width, height int) list.Model {
 	l.Styles.Title = TitleStyle
 	l.Styles.PaginationStyle = PaginationStyle
 	l.Styles.HelpStyle = HelpStyle
+	applyListTheme(&l)
+	applyAccessibleList(&l)
 	return l
 }
 
@@ -89,6 +117,8 @@ func NewListWithKeys(items []list.Item, title string, width, height int, keyMap
 	l.Styles.Title = TitleStyle
 	l.Styles.PaginationStyle = PaginationStyle
 	l.Styles.HelpStyle = HelpStyle
+	applyListTheme(&l)
+	applyAccessibleList(&l)
 	l.AdditionalShortHelpKeys = func() []key.Binding {
 		if km, ok := keyMap.(interface{ ShortHelp() []key.Binding }); ok {
 			return km.ShortHelp()
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DoubleClickInterval is the longest gap between two clicks on the same row
+// that still counts as a double click
+const DoubleClickInterval = 400 * time.Millisecond
+
+// MouseCmd returns the command that turns on mouse reporting for a view, or
+// nil when mouse support is disabled. Accessible mode never enables the mouse
+// so text in the scrollback stays selectable.
+func MouseCmd(enabled bool) tea.Cmd {
+	if !enabled || accessible {
+		return nil
+	}
+	return tea.EnableMouseCellMotion
+}
+
+// ListItemAt returns the index of the item drawn at row y of a list's view,
+// for lists built with NewList or NewListWithKeys
+func ListItemAt(l list.Model, y int) (int, bool) {
+	if l.ShowTitle() || (l.ShowFilter() && l.FilteringEnabled()) {
+		y -= lipgloss.Height(l.Styles.TitleBar.Render(l.Styles.Title.Render(l.Title)))
+	}
+	if l.ShowStatusBar() {
+		y -= lipgloss.Height(l.Styles.StatusBar.Render(" "))
+	}
+	if y < 0 {
+		return 0, false
+	}
+
+	// Each item is drawn by the two-line default delegate followed by a gap
+	d := list.NewDefaultDelegate()
+	rowHeight := d.Height() + d.Spacing()
+	if y%rowHeight >= d.Height() || y/rowHeight >= l.Paginator.PerPage {
+		return 0, false
+	}
+
+	index := l.Paginator.Page*l.Paginator.PerPage + y/rowHeight
+	if index >= len(l.VisibleItems()) {
+		return 0, false
+	}
+	return index, true
+}
+
+// ClickTracker turns consecutive clicks on the same row into double clicks
+type ClickTracker struct {
+	row int
+	at  time.Time
+}
+
+// Click records a click on a row and reports whether it completes a double click
+func (c *ClickTracker) Click(row int, now time.Time) bool {
+	double := !c.at.IsZero() && c.row == row && now.Sub(c.at) <= DoubleClickInterval
+	if double {
+		// A third click starts a new pair rather than double clicking again
+		c.at = time.Time{}
+	} else {
+		c.row = row
+		c.at = now
+	}
+	return double
+}
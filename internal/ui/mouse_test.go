@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+func TestMouseCmd(t *testing.T) {
+	t.Cleanup(func() {
+		SetAccessible(false)
+		ApplyTheme(Presets[ThemeDark])
+	})
+
+	if MouseCmd(true) == nil {
+		t.Error("Expected a command when the mouse is enabled")
+	}
+	if MouseCmd(false) != nil {
+		t.Error("Expected no command when the mouse is disabled")
+	}
+
+	SetAccessible(true)
+	if MouseCmd(true) != nil {
+		t.Error("Expected accessible mode to keep the mouse off")
+	}
+}
+
+func TestListItemAt(t *testing.T) {
+	items := []list.Item{
+		GenericItem{Title: "One", Description: "first"},
+		GenericItem{Title: "Two", Description: "second"},
+		GenericItem{Title: "Three", Description: "third"},
+	}
+	l := NewList(items, "Devices", 40, 20)
+
+	// Title bar (2 rows), then items of 2 rows separated by a blank row
+	tests := []struct {
+		y        int
+		expected int
+		ok       bool
+	}{
+		{0, 0, false},
+		{1, 0, false},
+		{2, 0, true},
+		{3, 0, true},
+		{4, 0, false},
+		{5, 1, true},
+		{9, 2, true},
+		{11, 0, false},
+	}
+
+	for _, tt := range tests {
+		index, ok := ListItemAt(l, tt.y)
+		if ok != tt.ok || (ok && index != tt.expected) {
+			t.Errorf("row %d: expected (%d, %v), got (%d, %v)", tt.y, tt.expected, tt.ok, index, ok)
+		}
+	}
+}
+
+func TestClickTracker(t *testing.T) {
+	var clicks ClickTracker
+	now := time.Now()
+
+	if clicks.Click(1, now) {
+		t.Error("Expected a single click")
+	}
+	if !clicks.Click(1, now.Add(100*time.Millisecond)) {
+		t.Error("Expected a double click on the same row")
+	}
+	if clicks.Click(1, now.Add(200*time.Millisecond)) {
+		t.Error("Expected a third click to start a new pair")
+	}
+	if clicks.Click(2, now.Add(300*time.Millisecond)) {
+		t.Error("Expected a click on another row not to double click")
+	}
+	if clicks.Click(2, now.Add(300*time.Millisecond+DoubleClickInterval+time.Millisecond)) {
+		t.Error("Expected slow clicks not to double click")
+	}
+}
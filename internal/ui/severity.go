@@ -0,0 +1,41 @@
+package ui
+
+import "github.com/charmbracelet/lipgloss"
+
+// Severity ranks events such as activity log entries, from routine to failed
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeveritySuccess
+	SeverityWarning
+	SeverityError
+)
+
+// String returns the label shown beside an event
+func (s Severity) String() string {
+	switch s {
+	case SeveritySuccess:
+		return "ok"
+	case SeverityWarning:
+		return "warn"
+	case SeverityError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// Style returns the style events of the severity are rendered in
+func (s Severity) Style() lipgloss.Style {
+	switch s {
+	case SeveritySuccess:
+		return lipgloss.NewStyle().Foreground(color(activePalette.Success))
+	case SeverityWarning:
+		return lipgloss.NewStyle().Foreground(color(activePalette.Connecting))
+	case SeverityError:
+		return lipgloss.NewStyle().Foreground(color(activePalette.Error))
+	default:
+		return lipgloss.NewStyle().Foreground(color(activePalette.Muted))
+	}
+}
@@ -0,0 +1,18 @@
+package ui
+
+import "testing"
+
+func TestSeverityLabels(t *testing.T) {
+	seen := make(map[string]bool)
+	for s := SeverityInfo; s <= SeverityError; s++ {
+		label := s.String()
+		// Labels are padded to five columns in the activity log
+		if len(label) > 5 || seen[label] {
+			t.Errorf("Unexpected label %q for severity %d", label, int(s))
+		}
+		seen[label] = true
+	}
+	if Severity(99).String() != "info" {
+		t.Error("Expected unknown severities shown as info")
+	}
+}
@@ -1,81 +1,167 @@
 // Package ui contains user interface components and styling
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/lipgloss"
+)
 
-// Styles using terminal default colors and fancy list styling
+// Styles derived from the active palette; ApplyTheme rebuilds them
 var (
+	TitleStyle    lipgloss.Style
+	SubtitleStyle lipgloss.Style
+	HelpStyle     lipgloss.Style
+
+	// List styles
+	ItemStyle                  lipgloss.Style
+	SelectedItemStyle          lipgloss.Style
+	ConnectedItemStyle         lipgloss.Style
+	ConnectedSelectedItemStyle lipgloss.Style
+	PaginationStyle            lipgloss.Style
+
+	// Status-specific styles
+	ConnectedStatusStyle     lipgloss.Style
+	PairedStatusStyle        lipgloss.Style
+	DiscoveredStatusStyle    lipgloss.Style
+	ConnectingStatusStyle    lipgloss.Style
+	DisconnectingStatusStyle lipgloss.Style
+	MacAddressStyle          lipgloss.Style
+	RSSIStyle                lipgloss.Style
+
+	// Detail pane styles
+	DetailPaneStyle  lipgloss.Style
+	DetailLabelStyle lipgloss.Style
+
+	// ModalStyle frames the error details modal
+	ModalStyle lipgloss.Style
+
+	// Application-wide padding style for comfortable spacing
+	AppStyle = lipgloss.NewStyle().
+			Padding(1, 2) // 1 row padding top/bottom, 2 column padding left/right
+)
+
+// activePalette is the palette the current styles were built from
+var activePalette Palette
+
+func init() {
+	ApplyTheme(Presets[ThemeDark])
+}
+
+// HelpStyles returns key help styles coloured from the active palette, in
+// place of the help component's built-in greys
+func HelpStyles() help.Styles {
+	p := activePalette
+	keys := lipgloss.NewStyle().Foreground(color(p.Text))
+	muted := lipgloss.NewStyle().Foreground(color(p.Muted))
+	return help.Styles{
+		Ellipsis:       muted,
+		ShortKey:       keys,
+		ShortDesc:      muted,
+		ShortSeparator: muted,
+		FullKey:        keys,
+		FullDesc:       muted,
+		FullSeparator:  muted,
+	}
+}
+
+// ActivePalette returns the palette the current styles were built from
+func ActivePalette() Palette {
+	return activePalette
+}
+
+// ApplyTheme rebuilds every style from the given palette
+func ApplyTheme(p Palette) {
+	activePalette = p
+
 	TitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("15")). // Terminal bright white
-			Background(lipgloss.Color("2")).  // Terminal bright black (muted)
-			Padding(0, 1).
-			Bold(true)
+		Foreground(color(p.TitleFg)).
+		Background(color(p.TitleBg)).
+		Padding(0, 1).
+		Bold(true)
+	if p.TitleBg == "" {
+		// Without a background colour the title stands out in reverse video
+		TitleStyle = TitleStyle.Reverse(true)
+	}
 
 	SubtitleStyle = lipgloss.NewStyle().
-			Faint(true).
-			MarginBottom(2)
+		Faint(true).
+		MarginBottom(2)
 
 	HelpStyle = lipgloss.NewStyle().
-			Faint(true).
-			MarginTop(1)
+		Faint(true).
+		MarginTop(1)
 
-	// List styles
 	ItemStyle = lipgloss.NewStyle().
-			PaddingLeft(4)
+		PaddingLeft(4)
 
 	SelectedItemStyle = lipgloss.NewStyle().
-				PaddingLeft(2).
-				Bold(true)
+		PaddingLeft(2).
+		Bold(true)
 
 	ConnectedItemStyle = lipgloss.NewStyle().
-				PaddingLeft(4).
-				Foreground(lipgloss.Color("2")) // Terminal green for connected devices
+		PaddingLeft(4).
+		Foreground(color(p.Connected))
 
 	ConnectedSelectedItemStyle = lipgloss.NewStyle().
-					PaddingLeft(2).
-					Bold(true).
-					Foreground(lipgloss.Color("2")) // Terminal green for connected devices
+		PaddingLeft(2).
+		Bold(true).
+		Foreground(color(p.Connected))
 
 	PaginationStyle = lipgloss.NewStyle().
-			Faint(true)
+		Faint(true)
 
-	// Status-specific styles using terminal colors that respect user's theme
 	ConnectedStatusStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("2")) // Terminal green
+		Foreground(color(p.Connected))
 
 	PairedStatusStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("3")) // Terminal yellow
+		Foreground(color(p.Paired))
 
 	DiscoveredStatusStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("6")) // Terminal cyan
+		Foreground(color(p.Discovered))
 
 	ConnectingStatusStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("11")) // Terminal bright yellow/orange
+		Foreground(color(p.Connecting))
 
 	DisconnectingStatusStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("13")) // Terminal bright magenta
+		Foreground(color(p.Disconnecting))
 
 	MacAddressStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("8")) // Terminal bright black (muted)
+		Foreground(color(p.Muted))
 
 	RSSIStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("7")) // Terminal white
-
-	// Application-wide padding style for comfortable spacing
-	AppStyle = lipgloss.NewStyle().
-			Padding(1, 2) // 1 row padding top/bottom, 2 column padding left/right
-)
+		Foreground(color(p.Text))
+
+	DetailPaneStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(color(p.Muted)).
+		PaddingLeft(1)
+	if accessible {
+		DetailPaneStyle = DetailPaneStyle.BorderStyle(lipgloss.Border{Left: "|"})
+	}
+
+	DetailLabelStyle = lipgloss.NewStyle().
+		Foreground(color(p.Muted))
+
+	ModalStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(color(p.Error)).
+		Padding(0, 1)
+	if accessible {
+		ModalStyle = ModalStyle.BorderStyle(lipgloss.ASCIIBorder())
+	}
+}
 
 // SuccessStyle returns the success style
 func SuccessStyle() lipgloss.Style {
 	return lipgloss.NewStyle().
-		Foreground(lipgloss.Color("10")).
+		Foreground(color(activePalette.Success)).
 		Bold(true)
 }
 
 // ErrorStyle returns the error style
 func ErrorStyle() lipgloss.Style {
 	return lipgloss.NewStyle().
-		Foreground(lipgloss.Color("9")).
+		Foreground(color(activePalette.Error)).
 		Bold(true)
 }
@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// NewTable creates a focused table styled from the active palette
+func NewTable(columns []table.Column, rows []table.Row, width, height int) table.Model {
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithWidth(width),
+		table.WithHeight(height),
+	)
+	t.SetStyles(tableStyles())
+	return t
+}
+
+// tableStyles derives header, cell and selection styles from the active palette
+func tableStyles() table.Styles {
+	p := activePalette
+	s := table.DefaultStyles()
+
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderBottom(true).
+		BorderForeground(color(p.Muted)).
+		Bold(true)
+
+	s.Selected = lipgloss.NewStyle().
+		Foreground(color(p.TitleFg)).
+		Background(color(p.TitleBg)).
+		Bold(true)
+	if p.TitleBg == "" {
+		s.Selected = s.Selected.Reverse(true)
+	}
+
+	if accessible {
+		s.Header = s.Header.BorderStyle(lipgloss.Border{Bottom: "-"})
+	}
+	return s
+}
+
+// SignalBars renders an RSSI value (in dBm) as a four-step bar gauge. Zero
+// means the signal strength is unknown and renders as an empty string.
+func SignalBars(rssi int) string {
+	if rssi == 0 {
+		return ""
+	}
+
+	level := 1
+	switch {
+	case rssi >= -55:
+		level = 4
+	case rssi >= -67:
+		level = 3
+	case rssi >= -80:
+		level = 2
+	}
+
+	if accessible {
+		return strings.Repeat("#", level) + strings.Repeat("-", 4-level)
+	}
+	bars := []rune("▂▄▆█")
+	return string(bars[:level]) + strings.Repeat(" ", 4-level)
+}
+
+// BatteryLevel renders a known charge in percent behind a battery icon,
+// which empties when the charge is low
+func BatteryLevel(percent int, low bool) string {
+	level := strconv.Itoa(percent) + "%"
+	if accessible {
+		if low {
+			level += " low"
+		}
+		return level
+	}
+	if low {
+		return "🪫 " + level
+	}
+	return "🔋 " + level
+}
+
+// Sparkline renders RSSI samples (in dBm) as a row of block characters scaled
+// between the weakest and strongest sample. In accessible mode the values are
+// listed instead.
+func Sparkline(samples []int) string {
+	if len(samples) == 0 {
+		return ""
+	}
+
+	if accessible {
+		values := make([]string, len(samples))
+		for i, s := range samples {
+			values[i] = strconv.Itoa(s)
+		}
+		return strings.Join(values, " ")
+	}
+
+	lo, hi := samples[0], samples[0]
+	for _, s := range samples {
+		lo = min(lo, s)
+		hi = max(hi, s)
+	}
+
+	blocks := []rune("▁▂▃▄▅▆▇█")
+	var b strings.Builder
+	for _, s := range samples {
+		level := len(blocks) / 2
+		if hi > lo {
+			level = (s - lo) * (len(blocks) - 1) / (hi - lo)
+		}
+		b.WriteRune(blocks[level])
+	}
+	return b.String()
+}
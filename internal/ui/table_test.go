@@ -0,0 +1,98 @@
+package ui
+
+import "testing"
+
+func TestSignalBars(t *testing.T) {
+	tests := []struct {
+		name       string
+		rssi       int
+		expected   string
+		accessible string
+	}{
+		{"unknown", 0, "", ""},
+		{"excellent", -50, "▂▄▆█", "####"},
+		{"good", -67, "▂▄▆ ", "###-"},
+		{"fair", -75, "▂▄  ", "##--"},
+		{"weak", -90, "▂   ", "#---"},
+	}
+
+	t.Cleanup(func() {
+		SetAccessible(false)
+		ApplyTheme(Presets[ThemeDark])
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetAccessible(false)
+			if got := SignalBars(tt.rssi); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+			SetAccessible(true)
+			if got := SignalBars(tt.rssi); got != tt.accessible {
+				t.Errorf("Expected accessible %q, got %q", tt.accessible, got)
+			}
+		})
+	}
+}
+
+func TestBatteryLevel(t *testing.T) {
+	t.Cleanup(func() {
+		SetAccessible(false)
+		ApplyTheme(Presets[ThemeDark])
+	})
+
+	tests := []struct {
+		percent    int
+		low        bool
+		expected   string
+		accessible string
+	}{
+		{0, true, "🪫 0%", "0% low"},
+		{90, false, "🔋 90%", "90%"},
+		{100, false, "🔋 100%", "100%"},
+		{15, true, "🪫 15%", "15% low"},
+	}
+	for _, tt := range tests {
+		SetAccessible(false)
+		if got := BatteryLevel(tt.percent, tt.low); got != tt.expected {
+			t.Errorf("BatteryLevel(%d, %v) = %q, expected %q", tt.percent, tt.low, got, tt.expected)
+		}
+		SetAccessible(true)
+		if got := BatteryLevel(tt.percent, tt.low); got != tt.accessible {
+			t.Errorf("accessible BatteryLevel(%d, %v) = %q, expected %q", tt.percent, tt.low, got, tt.accessible)
+		}
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	t.Cleanup(func() {
+		SetAccessible(false)
+		ApplyTheme(Presets[ThemeDark])
+	})
+
+	if got := Sparkline(nil); got != "" {
+		t.Errorf("Expected empty sparkline, got %q", got)
+	}
+	if got := Sparkline([]int{-90, -75, -60}); got != "▁▄█" {
+		t.Errorf("Expected ▁▄█, got %q", got)
+	}
+	if got := Sparkline([]int{-70, -70}); got != "▅▅" {
+		t.Errorf("Expected a flat line, got %q", got)
+	}
+
+	SetAccessible(true)
+	if got := Sparkline([]int{-90, -60}); got != "-90 -60" {
+		t.Errorf("Expected plain values, got %q", got)
+	}
+}
+
+func TestNewTable(t *testing.T) {
+	tbl := NewTable(nil, nil, 40, 10)
+
+	if !tbl.Focused() {
+		t.Error("Expected table to be focused")
+	}
+	if tbl.Width() != 40 {
+		t.Errorf("Expected width 40, got %d", tbl.Width())
+	}
+}
@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Built-in theme names
+const (
+	ThemeAuto         = "auto"
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeHighContrast = "high-contrast"
+	ThemeMonochrome   = "monochrome"
+)
+
+// Palette holds the colours every style is derived from. Colours are ANSI
+// numbers ("0"-"255") or hex values ("#rrggbb"); an empty colour leaves the
+// terminal default in place.
+type Palette struct {
+	Base           string `toml:"base"`
+	TitleFg        string `toml:"title_fg"`
+	TitleBg        string `toml:"title_bg"`
+	SelectedFg     string `toml:"selected_fg"`
+	SelectedDesc   string `toml:"selected_desc"`
+	SelectedBorder string `toml:"selected_border"`
+	Connected      string `toml:"connected"`
+	Paired         string `toml:"paired"`
+	Discovered     string `toml:"discovered"`
+	Connecting     string `toml:"connecting"`
+	Disconnecting  string `toml:"disconnecting"`
+	Muted          string `toml:"muted"`
+	Text           string `toml:"text"`
+	Success        string `toml:"success"`
+	Error          string `toml:"error"`
+}
+
+// Presets are the palettes shipped with btui
+var Presets = map[string]Palette{
+	// Terminal colours that follow the user's own dark scheme
+	ThemeDark: {
+		TitleFg:        "15",
+		TitleBg:        "2",
+		SelectedFg:     "15",
+		SelectedDesc:   "7",
+		SelectedBorder: "2",
+		Connected:      "2",
+		Paired:         "3",
+		Discovered:     "6",
+		Connecting:     "11",
+		Disconnecting:  "13",
+		Muted:          "8",
+		Text:           "7",
+		Success:        "10",
+		Error:          "9",
+	},
+	// Darker 256-colour shades that stay readable on white backgrounds
+	ThemeLight: {
+		TitleFg:        "231",
+		TitleBg:        "22",
+		SelectedFg:     "16",
+		SelectedDesc:   "238",
+		SelectedBorder: "28",
+		Connected:      "28",
+		Paired:         "130",
+		Discovered:     "25",
+		Connecting:     "166",
+		Disconnecting:  "90",
+		Muted:          "242",
+		Text:           "236",
+		Success:        "28",
+		Error:          "124",
+	},
+	// Bright, widely separated colours for low-vision use
+	ThemeHighContrast: {
+		TitleFg:        "16",
+		TitleBg:        "226",
+		SelectedFg:     "231",
+		SelectedDesc:   "231",
+		SelectedBorder: "226",
+		Connected:      "46",
+		Paired:         "226",
+		Discovered:     "51",
+		Connecting:     "213",
+		Disconnecting:  "208",
+		Muted:          "231",
+		Text:           "231",
+		Success:        "46",
+		Error:          "196",
+	},
+	// No colours at all; emphasis comes from bold and reverse video
+	ThemeMonochrome: {},
+}
+
+// PresetNames returns the built-in theme names in a stable order
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var hexColor = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validColor reports whether c is empty, an ANSI colour number or a hex colour
+func validColor(c string) bool {
+	if c == "" || hexColor.MatchString(c) {
+		return true
+	}
+	n, err := strconv.Atoi(c)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// Validate checks that every colour in the palette can be rendered
+func (p Palette) Validate() error {
+	if p.Base != "" {
+		if _, ok := Presets[p.Base]; !ok {
+			return fmt.Errorf("base must be one of %s, got %q", strings.Join(PresetNames(), ", "), p.Base)
+		}
+	}
+	for _, field := range p.fields() {
+		if !validColor(*field.value) {
+			return fmt.Errorf("%s must be an ANSI colour number (0-255) or #rrggbb, got %q", field.name, *field.value)
+		}
+	}
+	return nil
+}
+
+// fields lists the palette's colour settings by config name
+func (p *Palette) fields() []struct {
+	name  string
+	value *string
+} {
+	return []struct {
+		name  string
+		value *string
+	}{
+		{"title_fg", &p.TitleFg},
+		{"title_bg", &p.TitleBg},
+		{"selected_fg", &p.SelectedFg},
+		{"selected_desc", &p.SelectedDesc},
+		{"selected_border", &p.SelectedBorder},
+		{"connected", &p.Connected},
+		{"paired", &p.Paired},
+		{"discovered", &p.Discovered},
+		{"connecting", &p.Connecting},
+		{"disconnecting", &p.Disconnecting},
+		{"muted", &p.Muted},
+		{"text", &p.Text},
+		{"success", &p.Success},
+		{"error", &p.Error},
+	}
+}
+
+// ResolveTheme returns the palette for a theme name. "auto" picks the light or
+// dark preset from the terminal background. Custom palettes start from their
+// base preset (dark by default) and override the colours they set.
+func ResolveTheme(name string, custom map[string]Palette) (Palette, error) {
+	if name == "" || name == ThemeAuto {
+		if lipgloss.HasDarkBackground() {
+			return Presets[ThemeDark], nil
+		}
+		return Presets[ThemeLight], nil
+	}
+
+	if p, ok := custom[name]; ok {
+		base := p.Base
+		if base == "" {
+			base = ThemeDark
+		}
+		resolved, ok := Presets[base]
+		if !ok {
+			return Palette{}, fmt.Errorf("theme %q: unknown base %q", name, base)
+		}
+		overrides := p.fields()
+		for i, field := range resolved.fields() {
+			if v := *overrides[i].value; v != "" {
+				*field.value = v
+			}
+		}
+		return resolved, nil
+	}
+
+	if p, ok := Presets[name]; ok {
+		return p, nil
+	}
+	return Palette{}, fmt.Errorf("unknown theme %q", name)
+}
+
+// color converts a palette entry to a lipgloss colour, with "" meaning none
+func color(c string) lipgloss.TerminalColor {
+	if c == "" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(c)
+}
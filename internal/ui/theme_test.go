@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPresetsAreValid(t *testing.T) {
+	for _, name := range PresetNames() {
+		if err := Presets[name].Validate(); err != nil {
+			t.Errorf("Preset %s is invalid: %v", name, err)
+		}
+	}
+}
+
+func TestPresetNames(t *testing.T) {
+	expected := "dark,high-contrast,light,monochrome"
+	if got := strings.Join(PresetNames(), ","); got != expected {
+		t.Errorf("Expected presets %s, got %s", expected, got)
+	}
+}
+
+func TestPaletteValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		palette Palette
+		valid   bool
+	}{
+		{"ansi number", Palette{Connected: "42"}, true},
+		{"hex colour", Palette{Connected: "#00ff7f"}, true},
+		{"short hex colour", Palette{Connected: "#0f7"}, true},
+		{"empty colour", Palette{}, true},
+		{"known base", Palette{Base: ThemeLight}, true},
+		{"out of range", Palette{Connected: "256"}, false},
+		{"colour name", Palette{Error: "red"}, false},
+		{"bad hex", Palette{Muted: "#12345"}, false},
+		{"unknown base", Palette{Base: "solarized"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.palette.Validate()
+			if (err == nil) != tt.valid {
+				t.Errorf("Expected valid=%v, got error %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestResolveThemePreset(t *testing.T) {
+	p, err := ResolveTheme(ThemeHighContrast, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p != Presets[ThemeHighContrast] {
+		t.Error("Expected the high-contrast preset")
+	}
+}
+
+func TestResolveThemeCustom(t *testing.T) {
+	custom := map[string]Palette{
+		"mine": {Base: ThemeLight, Connected: "#00aa00"},
+	}
+
+	p, err := ResolveTheme("mine", custom)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.Connected != "#00aa00" {
+		t.Errorf("Expected overridden connected colour, got %q", p.Connected)
+	}
+	if p.Paired != Presets[ThemeLight].Paired {
+		t.Errorf("Expected paired colour from the light base, got %q", p.Paired)
+	}
+}
+
+func TestResolveThemeUnknown(t *testing.T) {
+	if _, err := ResolveTheme("nope", nil); err == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+}
+
+func TestResolveThemeAuto(t *testing.T) {
+	p, err := ResolveTheme(ThemeAuto, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p != Presets[ThemeDark] && p != Presets[ThemeLight] {
+		t.Error("Expected auto to resolve to the dark or light preset")
+	}
+}
+
+func TestApplyTheme(t *testing.T) {
+	t.Cleanup(func() { ApplyTheme(Presets[ThemeDark]) })
+
+	ApplyTheme(Presets[ThemeMonochrome])
+	if ActivePalette() != Presets[ThemeMonochrome] {
+		t.Error("Expected the monochrome palette to be active")
+	}
+	if !TitleStyle.GetReverse() {
+		t.Error("Expected a reverse-video title when the palette has no title background")
+	}
+
+	ApplyTheme(Presets[ThemeLight])
+	if TitleStyle.GetReverse() {
+		t.Error("Expected no reverse video when the palette has a title background")
+	}
+	if got := ConnectedStatusStyle.GetForeground(); got != color(Presets[ThemeLight].Connected) {
+		t.Errorf("Expected connected status to use the light palette, got %v", got)
+	}
+}
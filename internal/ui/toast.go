@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Toast is one transient notification
+type Toast struct {
+	ID       int
+	Severity Severity
+	Message  string
+}
+
+// ToastExpiredMsg is sent when a toast has been shown for its lifetime
+type ToastExpiredMsg struct {
+	ID int
+}
+
+// Toasts is a stack of notifications shown together, newest last, each
+// dismissed once its lifetime is up. Past the limit, the oldest make way.
+type Toasts struct {
+	Items    []Toast
+	Limit    int
+	Lifetime time.Duration
+	nextID   int
+}
+
+// NewToasts returns an empty stack showing at most limit toasts, each for
+// the given lifetime
+func NewToasts(limit int, lifetime time.Duration) Toasts {
+	return Toasts{Limit: max(1, limit), Lifetime: lifetime}
+}
+
+// Push adds a toast and returns the command that expires it
+func (t *Toasts) Push(severity Severity, message string) tea.Cmd {
+	t.nextID++
+	id := t.nextID
+	t.Items = append(t.Items, Toast{ID: id, Severity: severity, Message: message})
+	if len(t.Items) > t.Limit {
+		t.Items = t.Items[len(t.Items)-t.Limit:]
+	}
+	return tea.Tick(t.Lifetime, func(time.Time) tea.Msg {
+		return ToastExpiredMsg{ID: id}
+	})
+}
+
+// Expire removes a toast, reporting whether it was still shown
+func (t *Toasts) Expire(id int) bool {
+	for i, toast := range t.Items {
+		if toast.ID == id {
+			t.Items = append(t.Items[:i:i], t.Items[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Since returns the toasts pushed after the one with the given ID, such as
+// those to announce in accessible mode
+func (t Toasts) Since(id int) []Toast {
+	var toasts []Toast
+	for _, toast := range t.Items {
+		if toast.ID > id {
+			toasts = append(toasts, toast)
+		}
+	}
+	return toasts
+}
+
+// LastID returns the ID of the newest toast ever pushed
+func (t Toasts) LastID() int {
+	return t.nextID
+}
+
+// Height returns how many rows the stack takes up
+func (t Toasts) Height() int {
+	return len(t.Items)
+}
+
+// marker returns the prefix for a toast of the severity
+func (s Severity) marker() string {
+	switch s {
+	case SeveritySuccess:
+		return SuccessMarker()
+	case SeverityError:
+		return FailureMarker()
+	case SeverityWarning:
+		if accessible {
+			return "WARNING:"
+		}
+		return "!"
+	default:
+		if accessible {
+			return "INFO:"
+		}
+		return "•"
+	}
+}
+
+// View renders the toasts one per line in their severity colours, cut to
+// the given width
+func (t Toasts) View(width int) string {
+	lines := make([]string, len(t.Items))
+	for i, toast := range t.Items {
+		line := toast.Severity.marker() + " " + toast.Message
+		lines[i] = toast.Severity.Style().MaxWidth(width).Render(line)
+	}
+	return strings.Join(lines, "\n")
+}
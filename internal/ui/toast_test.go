@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToastsStack(t *testing.T) {
+	toasts := NewToasts(2, time.Second)
+	if cmd := toasts.Push(SeverityInfo, "first"); cmd == nil {
+		t.Fatal("Expected a command expiring the toast")
+	}
+	toasts.Push(SeverityError, "second")
+	toasts.Push(SeveritySuccess, "third")
+
+	// Past the limit the oldest makes way
+	if toasts.Height() != 2 || toasts.Items[0].Message != "second" || toasts.Items[1].Message != "third" {
+		t.Fatalf("Unexpected toasts %v", toasts.Items)
+	}
+	if since := toasts.Since(2); len(since) != 1 || since[0].Message != "third" {
+		t.Errorf("Expected only the third toast since the second, got %v", since)
+	}
+
+	if !toasts.Expire(toasts.Items[0].ID) || toasts.Expire(1) {
+		t.Error("Expected only a shown toast to expire")
+	}
+	if toasts.Height() != 1 || toasts.LastID() != 3 {
+		t.Errorf("Unexpected toasts %v", toasts.Items)
+	}
+}
+
+func TestToastsView(t *testing.T) {
+	toasts := NewToasts(3, time.Second)
+	toasts.Push(SeveritySuccess, "Connected to Keychron K4")
+	toasts.Push(SeverityError, "Discovery error: adapter gone and not coming back")
+
+	lines := strings.Split(toasts.View(30), "\n")
+	if len(lines) != 2 || lines[0] != "✓ Connected to Keychron K4" {
+		t.Fatalf("Unexpected view %q", lines)
+	}
+	if !strings.HasPrefix(lines[1], "✗ Discovery error") || len(lines[1]) > 32 {
+		t.Errorf("Expected the error cut to the width, got %q", lines[1])
+	}
+
+	t.Cleanup(func() {
+		SetAccessible(false)
+		ApplyTheme(Presets[ThemeDark])
+	})
+	SetAccessible(true)
+	if view := toasts.View(80); !strings.HasPrefix(view, "OK: Connected") {
+		t.Errorf("Expected plain markers, got %q", view)
+	}
+}